@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"example/api/internal/api/handlers"
 	"example/api/internal/api/middleware"
+	"example/api/internal/audit"
+	"example/api/internal/blob"
+	"example/api/internal/events"
 	"example/api/internal/services"
+	"example/api/internal/telemetry"
+	"expvar"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	_ "example/api/docs" // This will be generated
 
@@ -21,12 +34,139 @@ import (
 func main() {
 	fmt.Println("Api restfull")
 
-	userService := services.NewUserService()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Configure tracing from OTEL_* environment variables
+	shutdownTracing, err := telemetry.Setup(ctx, telemetry.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	// Recent events are kept in memory for GET /events subscribers resuming after a disconnect
+	eventLog := events.NewLog(intFromEnv("EVENTS_LOG_SIZE", 1000))
+	eventHandler := handlers.NewEventHandler(eventLog, durationFromEnv("EVENTS_HEARTBEAT", 15*time.Second))
+
+	// Services announce their changes on the bus
+	bus := services.NewBus()
+
+	userService := services.NewUserService(bus)
 	userHandler := handlers.NewUserhandler(userService)
 
-	postService := services.NewPostService()
+	postService := services.NewPostService(bus)
+	postService.ResolveMentionsWith(userService.ResolveHandles)
 	postHandler := handlers.NewPostHandler(postService)
 
+	// Events live consumers may see are relayed to the event log
+	services.ForwardEvents(bus, postService, eventLog)
+
+	tagHandler := handlers.NewTagHandler(postService)
+
+	commentService := services.NewCommentService(postService)
+	commentHandler := handlers.NewCommentHandler(commentService, postService)
+
+	// Avatars are stored on the local filesystem under BLOB_DIR
+	blobDir := os.Getenv("BLOB_DIR")
+	if blobDir == "" {
+		blobDir = "data/blobs"
+	}
+	blobs, err := blob.NewFS(blobDir)
+	if err != nil {
+		log.Fatalf("Failed to open blob store: %v", err)
+	}
+	avatarService := services.NewAvatarService(userService, blobs, bytesFromEnv("AVATAR_MAX_BYTES", services.DefaultMaxAvatarBytes))
+	profileHandler := handlers.NewProfileHandler(userService, avatarService)
+
+	followService := services.NewFollowService(userService)
+	feedService := services.NewFeedService(postService, followService)
+	followHandler := handlers.NewFollowHandler(followService, feedService)
+
+	notificationService := services.NewNotificationService(postService, userService, commentService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+
+	bookmarkService := services.NewBookmarkService(postService, userService)
+	bookmarkHandler := handlers.NewBookmarkHandler(bookmarkService)
+
+	searchService := services.NewSearchService(postService, userService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+
+	// Webhook requests only reach public addresses and time out after WEBHOOK_TIMEOUT; failed deliveries are dead-lettered after WEBHOOK_MAX_ATTEMPTS
+	webhookClient := services.NewWebhookClient(durationFromEnv("WEBHOOK_TIMEOUT", 10*time.Second))
+	webhookService := services.NewWebhookService(postService, userService, webhookClient, intFromEnv("WEBHOOK_MAX_ATTEMPTS", services.DefaultWebhookMaxAttempts))
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	// Every mutation made through the API is recorded in the audit log
+	auditLog := audit.NewLog()
+	services.RecordAuditChanges(bus)
+	auditHandler := handlers.NewAuditHandler(auditLog)
+
+	// Bulk imports may be up to IMPORT_MAX_BYTES
+	transferService := services.NewTransferService(userService, postService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	maxImportBytes := bytesFromEnv("IMPORT_MAX_BYTES", handlers.DefaultMaxImportBytes)
+
+	// Request body limits and JSON decoding mode
+	maxBodyBytes := bytesFromEnv("MAX_BODY_BYTES", handlers.DefaultMaxBodyBytes)
+	handlers.ConfigureDecoding(handlers.DecodeConfig{
+		MaxBytes: maxBodyBytes,
+		Strict:   os.Getenv("STRICT_JSON") == "true",
+	})
+
+	// Callers are identified by X-User-ID from the trusted proxies and by the API keys
+	// of API_KEYS and ADMIN_API_KEYS
+	trustedProxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	adminKeys := middleware.ParseAPIKeys(os.Getenv("ADMIN_API_KEYS"))
+	apiKeys := append(middleware.ParseAPIKeys(os.Getenv("API_KEYS")), adminKeys...)
+
+	// Rate limits for write endpoints and expensive reads, keyed by API key, user or client IP
+	limiter := middleware.NewRateLimiter(trustedProxies)
+
+	// Idempotency-Key support so clients can safely retry creations
+	idempotency := middleware.NewIdempotency(durationFromEnv("IDEMPOTENCY_TTL", 24*time.Hour))
+
+	register := limiter.Limit("users.register", rateLimitFromEnv("RATE_LIMIT_REGISTER", "5/1m"),
+		idempotency.Handle("users.register", http.HandlerFunc(userHandler.Register)))
+	createPostLimit := rateLimitFromEnv("RATE_LIMIT_CREATE_POST", "30/1m")
+	createPost := limiter.Limit("posts.create", createPostLimit,
+		idempotency.Handle("posts.create", http.HandlerFunc(postHandler.Create)))
+
+	// The other write routes and the expensive reads are limited in groups, whose routes share their buckets
+	userWrites := limitRoute(limiter, "users.write", rateLimitFromEnv("RATE_LIMIT_USER_WRITE", "30/1m"))
+	avatarUploads := limitRoute(limiter, "users.avatar", rateLimitFromEnv("RATE_LIMIT_AVATAR", "10/1m"))
+	postWriteLimit := rateLimitFromEnv("RATE_LIMIT_POST_WRITE", "60/1m")
+	postWrites := limitRoute(limiter, "posts.write", postWriteLimit)
+	reactions := limitRoute(limiter, "posts.react", rateLimitFromEnv("RATE_LIMIT_REACT", "120/1m"))
+	diffs := limitRoute(limiter, "posts.diff", rateLimitFromEnv("RATE_LIMIT_DIFF", "30/1m"))
+	createCommentLimit := rateLimitFromEnv("RATE_LIMIT_CREATE_COMMENT", "30/1m")
+	commentCreations := limitRoute(limiter, "comments.create", createCommentLimit)
+	deleteCommentLimit := rateLimitFromEnv("RATE_LIMIT_DELETE_COMMENT", "60/1m")
+	commentDeletions := limitRoute(limiter, "comments.delete", deleteCommentLimit)
+	follows := limitRoute(limiter, "users.follow", rateLimitFromEnv("RATE_LIMIT_FOLLOW", "60/1m"))
+	feeds := limitRoute(limiter, "feed", rateLimitFromEnv("RATE_LIMIT_FEED", "120/1m"))
+	bookmarkWrites := limitRoute(limiter, "bookmarks.write", rateLimitFromEnv("RATE_LIMIT_BOOKMARK", "120/1m"))
+	notificationWrites := limitRoute(limiter, "notifications.write", rateLimitFromEnv("RATE_LIMIT_NOTIFICATION", "60/1m"))
+	searches := limitRoute(limiter, "search", rateLimitFromEnv("RATE_LIMIT_SEARCH", "60/1m"))
+	webhookWrites := limitRoute(limiter, "webhooks.write", rateLimitFromEnv("RATE_LIMIT_WEBHOOK", "30/1m"))
+	tagAdmin := limitRoute(limiter, "admin.tags", rateLimitFromEnv("RATE_LIMIT_ADMIN_TAGS", "30/1m"))
+	imports := limitRoute(limiter, "admin.import", rateLimitFromEnv("RATE_LIMIT_IMPORT", "5/1m"))
+	exports := limitRoute(limiter, "admin.export", rateLimitFromEnv("RATE_LIMIT_EXPORT", "5/1m"))
+	deleteUser := userWrites(userHandler.Delete)
+
+	// WebSocket clients are pinged every WS_PING_INTERVAL and dropped after two intervals of silence.
+	// Their commands are audited and rate limited like the matching requests, and browser pages
+	// may only connect from the API's origin or those of WS_ALLOWED_ORIGINS
+	wsHandler := handlers.NewWSHandler(eventLog, postService, commentService, middleware.NewCommandAuditor(auditLog, trustedProxies), durationFromEnv("WS_PING_INTERVAL", 30*time.Second))
+	wsHandler.AllowOrigins(listFromEnv("WS_ALLOWED_ORIGINS"))
+	wsHandler.LimitCommands(limiter, map[string]handlers.WSCommandLimit{
+		"post.create":    {Route: "posts.create", Limit: createPostLimit},
+		"post.delete":    {Route: "posts.write", Limit: postWriteLimit},
+		"comment.create": {Route: "comments.create", Limit: createCommentLimit},
+		"comment.delete": {Route: "comments.delete", Limit: deleteCommentLimit},
+	})
+
 	// Create a new mux router
 	mux := http.NewServeMux()
 
@@ -35,28 +175,44 @@ func main() {
 		httpSwagger.URL("http://localhost:8059/swagger/doc.json"),
 	))
 
+	// Publish scheduled posts in the background
+	go postService.RunScheduler(ctx, durationFromEnv("SCHEDULER_INTERVAL", 30*time.Second))
+
+	// Relay outbox events whose publication was interrupted
+	outboxInterval := durationFromEnv("OUTBOX_RELAY_INTERVAL", 5*time.Second)
+	go userService.Outbox().RunRelay(ctx, outboxInterval)
+	go postService.Outbox().RunRelay(ctx, outboxInterval)
+
+	// Retry failed webhook deliveries in the background
+	go webhookService.RunRetries(ctx, durationFromEnv("WEBHOOK_RETRY_INTERVAL", 10*time.Second))
+
+	// Runtime metrics
+	mux.Handle("/debug/vars", expvar.Handler())
+
 	// User endpoints
 	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			userHandler.List(w, r)
 		case http.MethodPost:
-			userHandler.Register(w, r)
+			register.ServeHTTP(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 
+	mux.HandleFunc("GET /users/{id}/posts", postHandler.FindByUserID)
+	mux.HandleFunc("GET /users/{id}/mentions", postHandler.Mentions)
+	mux.Handle("PATCH /users/{id}/profile", userWrites(profileHandler.UpdateProfile))
+	mux.Handle("PUT /users/{id}/avatar", avatarUploads(profileHandler.UploadAvatar))
+	mux.HandleFunc("GET /users/{id}/avatar/{size}", profileHandler.Avatar)
+
 	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path[len("/users/"):] == "posts" {
-			postHandler.FindByUserID(w, r)
-			return
-		}
 		switch r.Method {
 		case http.MethodGet:
 			userHandler.FindByID(w, r)
 		case http.MethodDelete:
-			userHandler.Delete(w, r)
+			deleteUser.ServeHTTP(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -68,26 +224,221 @@ func main() {
 		case http.MethodGet:
 			postHandler.List(w, r)
 		case http.MethodPost:
-			postHandler.Create(w, r)
+			createPost.ServeHTTP(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 
+	// /posts/by-slug/{slug} cannot be a pattern route: it would conflict with GET /posts/{id}/comments
 	mux.HandleFunc("/posts/", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
+			if strings.HasPrefix(r.URL.Path, "/posts/by-slug/") {
+				postHandler.FindBySlug(w, r)
+				return
+			}
 			postHandler.FindByID(w, r)
-		case http.MethodDelete:
-			postHandler.Delete(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 
-	// Apply CORS middleware to all routes
-	handler := middleware.CORS(mux)
+	mux.Handle("PUT /posts/{id}", postWrites(postHandler.Update))
+	mux.Handle("DELETE /posts/{id}", postWrites(postHandler.Delete))
+	mux.Handle("POST /posts/{id}/publish", postWrites(postHandler.Publish))
+	mux.Handle("POST /posts/{id}/archive", postWrites(postHandler.Archive))
+
+	// Revision endpoints
+	mux.HandleFunc("GET /posts/{id}/revisions", postHandler.Revisions)
+	mux.Handle("GET /posts/{id}/revisions/diff", diffs(postHandler.Diff))
+	mux.HandleFunc("GET /posts/{id}/revisions/{n}", postHandler.Revision)
+	mux.Handle("POST /posts/{id}/revisions/{n}/restore", postWrites(postHandler.Restore))
+
+	// Reaction endpoints
+	mux.HandleFunc("GET /posts/{id}/reactions", postHandler.Reactions)
+	mux.Handle("PUT /posts/{id}/reactions/{type}", reactions(postHandler.React))
+	mux.Handle("DELETE /posts/{id}/reactions/{type}", reactions(postHandler.Unreact))
+
+	// Tag endpoints
+	mux.HandleFunc("GET /tags", tagHandler.List)
+	mux.HandleFunc("GET /tags/{tag}/posts", tagHandler.Posts)
+	mux.Handle("POST /admin/tags/merge", middleware.RequireAdmin(adminKeys, tagAdmin(tagHandler.Merge)))
+	mux.Handle("POST /admin/tags/{tag}/rename", middleware.RequireAdmin(adminKeys, tagAdmin(tagHandler.Rename)))
+
+	// Import and export endpoints
+	mux.Handle("POST /admin/import", middleware.RequireAdmin(adminKeys, imports(transferHandler.Import)))
+	mux.Handle("GET /admin/export", middleware.RequireAdmin(adminKeys, exports(transferHandler.Export)))
+
+	// Audit log endpoints
+	mux.Handle("GET /admin/audit", middleware.RequireAdmin(adminKeys, http.HandlerFunc(auditHandler.Query)))
+	mux.Handle("GET /admin/audit/verify", middleware.RequireAdmin(adminKeys, http.HandlerFunc(auditHandler.Verify)))
+
+	// Comment endpoints
+	mux.HandleFunc("GET /posts/{id}/comments", commentHandler.List)
+	mux.Handle("POST /posts/{id}/comments", commentCreations(commentHandler.Create))
+	mux.Handle("DELETE /comments/{id}", commentDeletions(commentHandler.Delete))
+
+	// Follow and feed endpoints
+	mux.Handle("POST /users/{id}/follow", follows(followHandler.Follow))
+	mux.Handle("DELETE /users/{id}/follow", follows(followHandler.Unfollow))
+	mux.HandleFunc("GET /users/{id}/followers", followHandler.Followers)
+	mux.HandleFunc("GET /users/{id}/following", followHandler.Following)
+	mux.Handle("GET /feed", feeds(followHandler.Feed))
+
+	// Bookmark and reading list endpoints
+	mux.HandleFunc("GET /users/me/bookmarks", bookmarkHandler.Bookmarks)
+	mux.Handle("PUT /users/me/bookmarks/{postId}", bookmarkWrites(bookmarkHandler.Bookmark))
+	mux.Handle("DELETE /users/me/bookmarks/{postId}", bookmarkWrites(bookmarkHandler.Unbookmark))
+	mux.HandleFunc("GET /users/me/lists", bookmarkHandler.Lists)
+	mux.Handle("POST /users/me/lists", bookmarkWrites(bookmarkHandler.CreateList))
+	mux.HandleFunc("GET /users/me/lists/{listId}", bookmarkHandler.FindList)
+	mux.Handle("PATCH /users/me/lists/{listId}", bookmarkWrites(bookmarkHandler.RenameList))
+	mux.Handle("DELETE /users/me/lists/{listId}", bookmarkWrites(bookmarkHandler.DeleteList))
+	mux.HandleFunc("GET /users/me/lists/{listId}/posts", bookmarkHandler.ListPosts)
+	mux.Handle("PUT /users/me/lists/{listId}/posts/{postId}", bookmarkWrites(bookmarkHandler.AddToList))
+	mux.Handle("DELETE /users/me/lists/{listId}/posts/{postId}", bookmarkWrites(bookmarkHandler.RemoveFromList))
+
+	// Notification endpoints
+	mux.HandleFunc("GET /notifications", notificationHandler.List)
+	mux.Handle("POST /notifications/read", notificationWrites(notificationHandler.MarkRead))
+	mux.HandleFunc("GET /notifications/preferences", notificationHandler.Preferences)
+	mux.Handle("PUT /notifications/preferences", notificationWrites(notificationHandler.SetPreferences))
+
+	// Event stream and WebSocket endpoints
+	mux.HandleFunc("GET /events", eventHandler.Stream)
+	mux.HandleFunc("GET /ws", wsHandler.Serve)
+
+	// Search endpoint
+	mux.Handle("GET /search", searches(searchHandler.Search))
+
+	// Webhook endpoints
+	mux.HandleFunc("GET /webhooks", webhookHandler.List)
+	mux.Handle("POST /webhooks", webhookWrites(webhookHandler.Create))
+	mux.HandleFunc("GET /webhooks/dead-letters", webhookHandler.DeadLetters)
+	mux.HandleFunc("GET /webhooks/{id}", webhookHandler.Find)
+	mux.Handle("PATCH /webhooks/{id}", webhookWrites(webhookHandler.Update))
+	mux.Handle("DELETE /webhooks/{id}", webhookWrites(webhookHandler.Delete))
+	mux.HandleFunc("GET /webhooks/{id}/deliveries", webhookHandler.Deliveries)
+	mux.Handle("POST /webhooks/{id}/deliveries/{deliveryId}/redeliver", webhookWrites(webhookHandler.Redeliver))
+
+	// Apply middleware to all routes, innermost first
+	var handler http.Handler = mux
+	handler = middleware.Route(handler)
+	handler = middleware.Audit(auditLog, trustedProxies, handler)
+	handler = middleware.CORS(handler)
+	handler = middleware.Authenticate(trustedProxies, apiKeys, handler)
+	handler = middleware.MaxBodySizeFunc(func(r *http.Request) int64 {
+		if r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/users/") && strings.HasSuffix(r.URL.Path, "/avatar") {
+			// Leave room for the multipart framing around the image.
+			return avatarService.MaxBytes() + 64<<10
+		}
+		if r.URL.Path == "/admin/import" {
+			return maxImportBytes
+		}
+		return maxBodyBytes
+	}, handler)
+	handler = middleware.Recover(handler)
+	handler = middleware.RequestID(handler)
+	handler = middleware.Tracing(handler)
+
+	server := &http.Server{Addr: ":8059", Handler: handler}
+	// Shutdown waits for open requests, so end the event streams when it starts;
+	// this also closes the WebSocket connections, which Shutdown does not track.
+	server.RegisterOnShutdown(eventLog.Close)
+	go func() {
+		log.Println("Server starting on :8059")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown: %v", err)
+	}
+	if err := wsHandler.Wait(shutdownCtx); err != nil {
+		log.Printf("WebSocket shutdown: %v", err)
+	}
+	// Requests have finished, so no more events are coming: publish what is left in the
+	// outboxes and run the queued asynchronous subscribers
+	userService.Outbox().Relay(shutdownCtx)
+	postService.Outbox().Relay(shutdownCtx)
+	bus.Close()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Tracing shutdown: %v", err)
+	}
+}
+
+// limitRoute returns a function applying limit to handlers under the route name.
+func limitRoute(limiter *middleware.RateLimiter, route string, limit middleware.RateLimit) func(http.HandlerFunc) http.Handler {
+	return func(handler http.HandlerFunc) http.Handler {
+		return limiter.Limit(route, limit, handler)
+	}
+}
+
+// rateLimitFromEnv reads a limit such as "5/1m" from the environment variable name, or uses def.
+func rateLimitFromEnv(name string, def string) middleware.RateLimit {
+	value := os.Getenv(name)
+	if value == "" {
+		value = def
+	}
+	limit, err := middleware.ParseRateLimit(value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", name, err)
+	}
+	return limit
+}
+
+// listFromEnv reads a comma-separated list from the environment variable name, which may be empty.
+func listFromEnv(name string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// bytesFromEnv reads a positive size in bytes from the environment variable name, or uses def.
+func bytesFromEnv(name string, def int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		log.Fatalf("Invalid %s: %q", name, value)
+	}
+	return n
+}
+
+// intFromEnv reads a positive integer from the environment variable name, or uses def.
+func intFromEnv(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Fatalf("Invalid %s: %q", name, value)
+	}
+	return n
+}
 
-	log.Println("Server starting on :8059")
-	log.Fatal(http.ListenAndServe(":8059", handler))
+// durationFromEnv reads a duration such as "24h" from the environment variable name, or uses def.
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid %s: %q", name, value)
+	}
+	return d
 }
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"example/api/internal/audit"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AuditHandler handles HTTP requests for the audit log of API mutations.
+// It contains a reference to the log.
+type AuditHandler struct {
+	log *audit.Log
+}
+
+// NewAuditHandler creates a new instance of AuditHandler with the provided log.
+// It returns a pointer to the newly created AuditHandler.
+func NewAuditHandler(log *audit.Log) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// Query handles GET /admin/audit endpoint.
+// @Summary Query audit log
+// @Description Retrieve the audit log entries, oldest first. Each entry records who changed what, from where,
+// @Description with snapshots of the resource before and after, and is chained to the previous one by its hash.
+// @Description Page through results by passing the seq of the last entry received as after. Requires an admin API key.
+// @Tags admin
+// @Produce json
+// @Param actor query string false "Actor, such as user:42 or key:{fingerprint}; a bare number means a user ID"
+// @Param resource query string false "Resource, such as post:5, or resource type, such as post"
+// @Param since query string false "Only entries at or after this RFC 3339 time"
+// @Param after query int false "Only entries with a greater seq"
+// @Param limit query int false "Maximum number of entries" default(100) maximum(1000)
+// @Success 200 {array} audit.Entry
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /admin/audit [get]
+func (h *AuditHandler) Query(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := audit.Filter{Actor: q.Get("actor"), Resource: q.Get("resource")}
+	if _, err := strconv.Atoi(filter.Actor); err == nil {
+		filter.Actor = "user:" + filter.Actor
+	}
+	if v := q.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		filter.Since = since
+	}
+	if v := q.Get("after"); v != "" {
+		after, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			http.Error(w, "after must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		filter.After = after
+	}
+	limit, ok := queryInt(w, q.Get("limit"), "limit", audit.DefaultLimit)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > audit.MaxLimit {
+		http.Error(w, "limit must be between 1 and "+strconv.Itoa(audit.MaxLimit), http.StatusBadRequest)
+		return
+	}
+	filter.Limit = limit
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.log.Query(filter))
+}
+
+// Verify handles GET /admin/audit/verify endpoint.
+// @Summary Verify audit log
+// @Description Check the hash chain of the whole audit log. Requires an admin API key.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]any "Object with valid and the number of entries checked"
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 409 {string} string "The chain is broken"
+// @Router /admin/audit/verify [get]
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	n, err := h.log.Verify()
+	if errors.Is(err, audit.ErrTampered) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"valid": true, "entries": n})
+}
@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"example/api/internal/services"
+	"net/http"
+	"strconv"
+)
+
+// BookmarkHandler handles HTTP requests related to the caller's bookmarks and reading lists.
+// It contains a reference to the bookmark service.
+type BookmarkHandler struct {
+	service *services.BookmarkService
+}
+
+// NewBookmarkHandler creates a new instance of BookmarkHandler with the provided service.
+// It returns a pointer to the newly created BookmarkHandler.
+func NewBookmarkHandler(service *services.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{service: service}
+}
+
+// Bookmark handles PUT /users/me/bookmarks/{postId} endpoint.
+// @Summary Bookmark post
+// @Description Save a post to the caller's bookmarks. Bookmarking a post again keeps the original time.
+// @Tags bookmarks
+// @Produce json
+// @Param postId path int true "Post ID"
+// @Success 200 {object} models.Bookmark
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/me/bookmarks/{postId} [put]
+func (h *BookmarkHandler) Bookmark(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	postID, ok := pathID(w, r, "postId", "Invalid post ID")
+	if !ok {
+		return
+	}
+	bookmark, err := h.service.Bookmark(r.Context(), viewer, postID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bookmark)
+}
+
+// Unbookmark handles DELETE /users/me/bookmarks/{postId} endpoint.
+// @Summary Remove bookmark
+// @Description Remove a post from the caller's bookmarks
+// @Tags bookmarks
+// @Param postId path int true "Post ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/me/bookmarks/{postId} [delete]
+func (h *BookmarkHandler) Unbookmark(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	postID, ok := pathID(w, r, "postId", "Invalid post ID")
+	if !ok {
+		return
+	}
+	if !h.service.Unbookmark(r.Context(), viewer, postID) {
+		http.Error(w, "Bookmark not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Bookmarks handles GET /users/me/bookmarks endpoint.
+// @Summary Get bookmarks
+// @Description Retrieve the caller's bookmarks, most recently saved first.
+// @Description Pass next_cursor from a page as cursor to fetch the following page.
+// @Tags bookmarks
+// @Produce json
+// @Param cursor query string false "Cursor from a previous page"
+// @Param limit query int false "Maximum number of bookmarks (default 20, max 100)"
+// @Success 200 {object} models.BookmarkPage
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Router /users/me/bookmarks [get]
+func (h *BookmarkHandler) Bookmarks(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(w, r.URL.Query().Get("limit"), "limit", services.DefaultBookmarkLimit)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > services.MaxBookmarkLimit {
+		http.Error(w, "limit must be between 1 and "+strconv.Itoa(services.MaxBookmarkLimit), http.StatusBadRequest)
+		return
+	}
+	page, err := h.service.Bookmarks(r.Context(), viewer, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// CreateList handles POST /users/me/lists endpoint.
+// @Summary Create reading list
+// @Description Create an empty reading list for the caller. Names are unique per user, ignoring case.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param list body object true "Object with the list name"
+// @Success 201 {object} models.ReadingList
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 409 {string} string
+// @Router /users/me/lists [post]
+func (h *BookmarkHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		Name string `json:"name"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	list, err := h.service.CreateList(r.Context(), viewer, input.Name)
+	if err != nil {
+		writeReadingListError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+// Lists handles GET /users/me/lists endpoint.
+// @Summary Get reading lists
+// @Description Retrieve the caller's reading lists, oldest first
+// @Tags bookmarks
+// @Produce json
+// @Success 200 {array} models.ReadingList
+// @Failure 401 {string} string
+// @Router /users/me/lists [get]
+func (h *BookmarkHandler) Lists(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.Lists(r.Context(), viewer))
+}
+
+// FindList handles GET /users/me/lists/{listId} endpoint.
+// @Summary Get reading list
+// @Description Retrieve one of the caller's reading lists
+// @Tags bookmarks
+// @Produce json
+// @Param listId path int true "Reading list ID"
+// @Success 200 {object} models.ReadingList
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/me/lists/{listId} [get]
+func (h *BookmarkHandler) FindList(w http.ResponseWriter, r *http.Request) {
+	viewer, listID, ok := h.listRequest(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.service.FindList(r.Context(), viewer, listID)
+	if err != nil {
+		writeReadingListError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// RenameList handles PATCH /users/me/lists/{listId} endpoint.
+// @Summary Rename reading list
+// @Description Change the name of one of the caller's reading lists
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param listId path int true "Reading list ID"
+// @Param list body object true "Object with the new name"
+// @Success 200 {object} models.ReadingList
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Router /users/me/lists/{listId} [patch]
+func (h *BookmarkHandler) RenameList(w http.ResponseWriter, r *http.Request) {
+	viewer, listID, ok := h.listRequest(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		Name string `json:"name"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	list, err := h.service.RenameList(r.Context(), viewer, listID, input.Name)
+	if err != nil {
+		writeReadingListError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// DeleteList handles DELETE /users/me/lists/{listId} endpoint.
+// @Summary Delete reading list
+// @Description Delete one of the caller's reading lists. The posts themselves are not affected.
+// @Tags bookmarks
+// @Param listId path int true "Reading list ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/me/lists/{listId} [delete]
+func (h *BookmarkHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	viewer, listID, ok := h.listRequest(w, r)
+	if !ok {
+		return
+	}
+	if !h.service.DeleteList(r.Context(), viewer, listID) {
+		http.Error(w, services.ErrReadingListNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPosts handles GET /users/me/lists/{listId}/posts endpoint.
+// @Summary Get reading list posts
+// @Description Retrieve the posts in one of the caller's reading lists, in list order
+// @Tags bookmarks
+// @Produce json
+// @Param listId path int true "Reading list ID"
+// @Success 200 {array} models.Post
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/me/lists/{listId}/posts [get]
+func (h *BookmarkHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
+	viewer, listID, ok := h.listRequest(w, r)
+	if !ok {
+		return
+	}
+	posts, err := h.service.ListPosts(r.Context(), viewer, listID)
+	if err != nil {
+		writeReadingListError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(posts)
+}
+
+// AddToList handles PUT /users/me/lists/{listId}/posts/{postId} endpoint.
+// @Summary Add post to reading list
+// @Description Put a post in one of the caller's reading lists at the given position, counted from 0,
+// @Description or at the end. A post already in the list is moved.
+// @Tags bookmarks
+// @Produce json
+// @Param listId path int true "Reading list ID"
+// @Param postId path int true "Post ID"
+// @Param position query int false "Position in the list (default: end)"
+// @Success 200 {object} models.ReadingList
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/me/lists/{listId}/posts/{postId} [put]
+func (h *BookmarkHandler) AddToList(w http.ResponseWriter, r *http.Request) {
+	viewer, listID, ok := h.listRequest(w, r)
+	if !ok {
+		return
+	}
+	postID, ok := pathID(w, r, "postId", "Invalid post ID")
+	if !ok {
+		return
+	}
+	position, ok := queryInt(w, r.URL.Query().Get("position"), "position", -1)
+	if !ok {
+		return
+	}
+	list, err := h.service.AddToList(r.Context(), viewer, listID, postID, position)
+	if err != nil {
+		writeReadingListError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// RemoveFromList handles DELETE /users/me/lists/{listId}/posts/{postId} endpoint.
+// @Summary Remove post from reading list
+// @Description Take a post out of one of the caller's reading lists
+// @Tags bookmarks
+// @Produce json
+// @Param listId path int true "Reading list ID"
+// @Param postId path int true "Post ID"
+// @Success 200 {object} models.ReadingList
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/me/lists/{listId}/posts/{postId} [delete]
+func (h *BookmarkHandler) RemoveFromList(w http.ResponseWriter, r *http.Request) {
+	viewer, listID, ok := h.listRequest(w, r)
+	if !ok {
+		return
+	}
+	postID, ok := pathID(w, r, "postId", "Invalid post ID")
+	if !ok {
+		return
+	}
+	list, err := h.service.RemoveFromList(r.Context(), viewer, listID, postID)
+	if err != nil {
+		writeReadingListError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// listRequest returns the caller and the reading list ID in the path.
+// On failure it writes an error response and returns false.
+func (h *BookmarkHandler) listRequest(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	listID, ok := pathID(w, r, "listId", "Invalid reading list ID")
+	return viewer, listID, ok
+}
+
+// pathID parses the integer path value name. On failure it writes a 400 response
+// with the given message and returns false.
+func pathID(w http.ResponseWriter, r *http.Request, name string, invalid string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		http.Error(w, invalid, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeReadingListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrReadingListNotFound), errors.Is(err, services.ErrPostNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrReadingListExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
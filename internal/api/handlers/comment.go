@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"example/api/internal/services"
+	"net/http"
+	"strconv"
+)
+
+// CommentHandler handles HTTP requests related to comment operations.
+// It contains a reference to the comment service that implements the business logic,
+// and to the post service to check that the caller may see the post commented on.
+type CommentHandler struct {
+	service *services.CommentService
+	posts   *services.PostService
+}
+
+// NewCommentHandler creates a new instance of CommentHandler with the provided services.
+// It returns a pointer to the newly created CommentHandler.
+func NewCommentHandler(service *services.CommentService, posts *services.PostService) *CommentHandler {
+	return &CommentHandler{service: service, posts: posts}
+}
+
+// Create handles POST /posts/{id}/comments endpoint.
+// @Summary Comment on a post
+// @Description Add a comment by the caller to a post, optionally replying to another comment
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param comment body object true "Comment object with body and optional parent_id"
+// @Success 201 {object} map[string]int
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id}/comments [post]
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	postID, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		Body     string `json:"body"`
+		ParentID *int   `json:"parent_id"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	id, err := h.service.Create(r.Context(), postID, userID, input.Body, input.ParentID)
+	if errors.Is(err, services.ErrPostNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"id": id})
+}
+
+// List handles GET /posts/{id}/comments endpoint.
+// @Summary Get comments of a post
+// @Description Retrieve all comments of a post in creation order
+// @Tags comments
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {array} models.Comment
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id}/comments [get]
+func (h *CommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	postID, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	comments, err := h.service.FindByPostID(r.Context(), postID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+// Delete handles DELETE /comments/{id} endpoint.
+// @Summary Delete comment
+// @Description Delete a comment and all replies to it. Only the comment's author may delete it.
+// @Tags comments
+// @Param id path int true "Comment ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /comments/{id} [delete]
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireViewer(w, r); !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+	comment, err := h.service.FindByID(r.Context(), id)
+	if err != nil || !h.visible(r, comment.PostID) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if !requireAuthor(w, r, comment.UserID) {
+		return
+	}
+	if !h.service.Delete(r.Context(), id) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// visiblePost returns the ID of the post in the path, answering 404 if the caller cannot see it,
+// like PostHandler does for the post itself.
+func (h *CommentHandler) visiblePost(w http.ResponseWriter, r *http.Request) (int, bool) {
+	postID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return 0, false
+	}
+	if !h.visible(r, postID) {
+		http.Error(w, services.ErrPostNotFound.Error(), http.StatusNotFound)
+		return 0, false
+	}
+	return postID, true
+}
+
+// visible reports whether the post exists and the caller may see it.
+func (h *CommentHandler) visible(r *http.Request, postID int) bool {
+	post, err := h.posts.FindByID(r.Context(), postID)
+	return err == nil && post.VisibleTo(viewerID(r))
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"example/api/internal/api/middleware"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCommentHandler(t *testing.T) {
+	ctx := context.Background()
+	bus := services.NewBus()
+	users := services.NewUserService(bus)
+	posts := services.NewPostService(bus)
+	comments := services.NewCommentService(posts)
+	h := NewCommentHandler(comments, posts)
+
+	author, _ := users.Register(ctx, "Author", "author@example.com")
+	reader, _ := users.Register(ctx, "Reader", "reader@example.com")
+	public, _ := posts.Create(ctx, "Public", "Content", author)
+	draft, _ := posts.Create(ctx, "Draft", "Content", author, services.WithStatus(models.PostDraft))
+
+	// serve sends a request as viewer, or anonymously if zero, and returns the response.
+	serve := func(viewer int, method string, pattern string, target string, body string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if viewer != 0 {
+			req = req.WithContext(middleware.WithUserID(req.Context(), viewer))
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc(pattern, handler)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	create := func(viewer int, postID int, body string) *httptest.ResponseRecorder {
+		return serve(viewer, http.MethodPost, "POST /posts/{id}/comments", "/posts/"+strconv.Itoa(postID)+"/comments", body, h.Create)
+	}
+	remove := func(viewer int, id int) *httptest.ResponseRecorder {
+		return serve(viewer, http.MethodDelete, "DELETE /comments/{id}", "/comments/"+strconv.Itoa(id), "", h.Delete)
+	}
+
+	t.Run("Create comments as the caller", func(t *testing.T) {
+		if rr := create(0, public, `{"body": "Hi"}`); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for an anonymous caller, got %d", rr.Code)
+		}
+		if rr := create(reader, public, `{"user_id": `+strconv.Itoa(author)+`, "body": "Hi"}`); rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body)
+		}
+		list, _ := comments.FindByPostID(ctx, public)
+		if len(list) != 1 || list[0].UserID != reader {
+			t.Errorf("Expected 1 comment by the caller whatever the body says, got %+v", list)
+		}
+	})
+
+	t.Run("Hidden posts are not found", func(t *testing.T) {
+		if rr := create(reader, draft, `{"body": "Hi"}`); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 commenting another user's draft, got %d", rr.Code)
+		}
+		comments.Create(ctx, draft, author, "Note to self", nil)
+		rr := serve(reader, http.MethodGet, "GET /posts/{id}/comments", "/posts/"+strconv.Itoa(draft)+"/comments", "", h.List)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 listing comments of another user's draft, got %d", rr.Code)
+		}
+		rr = serve(author, http.MethodGet, "GET /posts/{id}/comments", "/posts/"+strconv.Itoa(draft)+"/comments", "", h.List)
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for the author, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Only the author deletes a comment", func(t *testing.T) {
+		id, _ := comments.Create(ctx, public, reader, "Mine", nil)
+		if rr := remove(0, id); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for an anonymous caller, got %d", rr.Code)
+		}
+		if rr := remove(author, id); rr.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for another user, got %d", rr.Code)
+		}
+		if rr := remove(reader, id); rr.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204 for the author, got %d", rr.Code)
+		}
+		if rr := remove(reader, id); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 once deleted, got %d", rr.Code)
+		}
+	})
+}
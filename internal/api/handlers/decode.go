@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DecodeConfig controls how JSON request bodies are decoded.
+type DecodeConfig struct {
+	// MaxBytes is the largest accepted request body
+	MaxBytes int64
+	// Strict rejects bodies containing fields the endpoint does not know
+	Strict bool
+}
+
+// DefaultMaxBodyBytes is the body size limit used unless configured otherwise.
+const DefaultMaxBodyBytes = 1 << 20
+
+var decodeConfig = DecodeConfig{MaxBytes: DefaultMaxBodyBytes}
+
+// ConfigureDecoding sets the body size limit and strict mode used by all handlers.
+func ConfigureDecoding(cfg DecodeConfig) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBodyBytes
+	}
+	decodeConfig = cfg
+}
+
+// decodeError is a request body problem together with the status to report it with.
+type decodeError struct {
+	status int
+	msg    string
+}
+
+func (e *decodeError) Error() string {
+	return e.msg
+}
+
+// decodeJSON decodes a single JSON value from the request body into dst.
+// On failure it writes the error response and returns false: 415 for a non-JSON
+// content type, 413 for an oversized body and 400 for anything malformed.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	if err := readJSON(w, r, dst, decodeConfig); err != nil {
+		var de *decodeError
+		if errors.As(err, &de) {
+			http.Error(w, de.msg, de.status)
+		} else {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		}
+		return false
+	}
+	return true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, dst any, cfg DecodeConfig) error {
+	if !isJSONContentType(r.Header.Get("Content-Type")) {
+		return &decodeError{http.StatusUnsupportedMediaType, "Content-Type must be application/json"}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBytes)
+	dec := json.NewDecoder(r.Body)
+	if cfg.Strict {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(dst); err != nil {
+		return describeDecodeError(err)
+	}
+	// Anything after the first value, other than whitespace, is rejected.
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return describeDecodeError(err)
+		}
+		return &decodeError{http.StatusBadRequest, "Request body must contain a single JSON object"}
+	}
+	return nil
+}
+
+func describeDecodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxErr):
+		return &decodeError{http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Request body must not be larger than %d bytes", maxErr.Limit)}
+	case errors.As(err, &syntaxErr):
+		return &decodeError{http.StatusBadRequest,
+			fmt.Sprintf("Request body contains malformed JSON at position %d", syntaxErr.Offset)}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &decodeError{http.StatusBadRequest, "Request body contains malformed JSON"}
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return &decodeError{http.StatusBadRequest,
+				fmt.Sprintf("Request body must be a JSON %s", jsonKind(typeErr.Type.Kind().String()))}
+		}
+		return &decodeError{http.StatusBadRequest,
+			fmt.Sprintf("Field %q must be a %s, got %s", typeErr.Field, jsonKind(typeErr.Type.Kind().String()), typeErr.Value)}
+	case errors.Is(err, io.EOF):
+		return &decodeError{http.StatusBadRequest, "Request body must not be empty"}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return &decodeError{http.StatusBadRequest, "Request body contains unknown field " + field}
+	default:
+		return &decodeError{http.StatusBadRequest, "Invalid request body"}
+	}
+}
+
+// jsonKind names a Go kind the way API clients think of JSON types.
+func jsonKind(kind string) string {
+	switch kind {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "slice", "array":
+		return "array"
+	case "struct", "map":
+		return "object"
+	default:
+		return kind
+	}
+}
+
+func isJSONContentType(value string) bool {
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type input struct {
+		Title  string `json:"title"`
+		UserID int    `json:"user_id"`
+	}
+
+	decode := func(cfg DecodeConfig, contentType string, body string) (input, *httptest.ResponseRecorder, bool) {
+		prev := decodeConfig
+		ConfigureDecoding(cfg)
+		defer func() { decodeConfig = prev }()
+
+		req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		var in input
+		ok := decodeJSON(rr, req, &in)
+		return in, rr, ok
+	}
+
+	t.Run("Valid body", func(t *testing.T) {
+		in, _, ok := decode(DecodeConfig{}, "application/json; charset=utf-8", `{"title":"Hi","user_id":3}`)
+		if !ok || in.Title != "Hi" || in.UserID != 3 {
+			t.Errorf("Expected decoded input, got %+v (ok=%v)", in, ok)
+		}
+	})
+
+	cases := []struct {
+		name        string
+		cfg         DecodeConfig
+		contentType string
+		body        string
+		status      int
+		message     string
+	}{
+		{"Missing content type", DecodeConfig{}, "", `{}`, http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+		{"Wrong content type", DecodeConfig{}, "text/plain", `{}`, http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+		{"Body too large", DecodeConfig{MaxBytes: 16}, "application/json", `{"title":"this is far too long"}`, http.StatusRequestEntityTooLarge, "must not be larger than 16 bytes"},
+		{"Wrong field type", DecodeConfig{}, "application/json", `{"user_id":"3"}`, http.StatusBadRequest, `Field "user_id" must be a number, got string`},
+		{"Malformed JSON", DecodeConfig{}, "application/json", `{"title":}`, http.StatusBadRequest, "malformed JSON at position 10"},
+		{"Truncated JSON", DecodeConfig{}, "application/json", `{"title":"a"`, http.StatusBadRequest, "malformed JSON"},
+		{"Empty body", DecodeConfig{}, "application/json", ``, http.StatusBadRequest, "must not be empty"},
+		{"Trailing data", DecodeConfig{}, "application/json", `{"title":"a"} {"title":"b"}`, http.StatusBadRequest, "single JSON object"},
+		{"Trailing garbage", DecodeConfig{}, "application/json", `{"title":"a"}garbage`, http.StatusBadRequest, "single JSON object"},
+		{"Not an object", DecodeConfig{}, "application/json", `[1,2]`, http.StatusBadRequest, "must be a JSON object"},
+		{"Unknown field in strict mode", DecodeConfig{Strict: true}, "application/json", `{"title":"a","extra":1}`, http.StatusBadRequest, `unknown field "extra"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, rr, ok := decode(tc.cfg, tc.contentType, tc.body)
+			if ok {
+				t.Fatal("Expected decoding to fail")
+			}
+			if rr.Code != tc.status {
+				t.Errorf("Expected status %d, got %d", tc.status, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), tc.message) {
+				t.Errorf("Expected message containing %q, got %q", tc.message, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("Unknown field allowed by default", func(t *testing.T) {
+		in, _, ok := decode(DecodeConfig{}, "application/json", `{"title":"a","extra":1}`)
+		if !ok || in.Title != "a" {
+			t.Errorf("Expected unknown field to be ignored, got %+v (ok=%v)", in, ok)
+		}
+	})
+}
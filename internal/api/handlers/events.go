@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"example/api/internal/events"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventHandler streams events to clients as Server-Sent Events.
+// It contains a reference to the event log and the heartbeat interval.
+type EventHandler struct {
+	log       *events.Log
+	heartbeat time.Duration
+}
+
+// NewEventHandler creates a new instance of EventHandler that reads from log and writes
+// a heartbeat comment whenever the stream has been idle for the given interval.
+// It returns a pointer to the newly created EventHandler.
+func NewEventHandler(log *events.Log, heartbeat time.Duration) *EventHandler {
+	return &EventHandler{log: log, heartbeat: heartbeat}
+}
+
+// Stream handles GET /events endpoint.
+// @Summary Stream events
+// @Description Stream user.registered, user.deleted, post.created, post.updated, post.deleted, comment.created
+// @Description and comment.deleted events as Server-Sent Events.
+// @Description Each message carries the event ID, so reconnecting clients resume after the Last-Event-ID header
+// @Description from the recent events kept in memory. Comments are sent as heartbeats while the stream is idle.
+// @Description Clients that fall too far behind are disconnected and should reconnect.
+// @Description Events about posts that are not published, and their comments, are only sent to the post's author.
+// @Description user.registered carries the user's id, name and handle, plus the email only for that user.
+// @Tags events
+// @Produce text/event-stream
+// @Param types query string false "Comma-separated event types to receive"
+// @Param user_id query int false "Only events concerning this user"
+// @Param Last-Event-ID header int false "ID of the last event received"
+// @Success 200 {object} events.Event
+// @Failure 400 {string} string
+// @Router /events [get]
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	// Events about posts the caller cannot see, such as drafts, are left out.
+	filter := events.Filter{ViewerID: viewerID(r)}
+	if v := query.Get("types"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			t := events.Type(strings.TrimSpace(t))
+			if !slices.Contains(events.Types, t) {
+				http.Error(w, fmt.Sprintf("Unknown event type %q", t), http.StatusBadRequest)
+				return
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	userID, ok := queryInt(w, query.Get("user_id"), "user_id", 0)
+	if !ok {
+		return
+	}
+	filter.UserID = userID
+	var after uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			http.Error(w, "Last-Event-ID must be an event ID", http.StatusBadRequest)
+			return
+		}
+		after = id
+	}
+
+	rc := http.NewResponseController(w)
+	sub := h.log.Subscribe(filter, after, events.DefaultBufferSize)
+	defer sub.Cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop reverse proxies such as nginx from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e.For(filter.ViewerID))
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data); err != nil {
+				return
+			}
+			heartbeat.Reset(h.heartbeat)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"example/api/internal/api/middleware"
+	"example/api/internal/events"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStreamVisibility(t *testing.T) {
+	ctx := context.Background()
+	log := events.NewLog(100)
+	bus := services.NewBus()
+	users := services.NewUserService(bus)
+	posts := services.NewPostService(bus)
+	comments := services.NewCommentService(posts)
+	services.ForwardEvents(bus, posts, log)
+	h := NewEventHandler(log, time.Minute)
+
+	author, _ := users.Register(ctx, "Author", "author@example.com")
+	draft, _ := posts.Create(ctx, "Secret draft", "Unpublished content", author, services.WithStatus(models.PostDraft))
+	comments.Create(ctx, draft, author, "Note to self", nil)
+	public, _ := posts.Create(ctx, "Public", "Content", author)
+	posts.Publish(ctx, draft, nil)
+
+	// stream returns the events after the registration seen by viewer, or by an anonymous client if zero.
+	stream := func(viewer int) string {
+		reqCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if viewer != 0 {
+			reqCtx = middleware.WithUserID(reqCtx, viewer)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(reqCtx)
+		req.Header.Set("Last-Event-ID", "1")
+		rr := httptest.NewRecorder()
+		h.Stream(rr, req)
+		return rr.Body.String()
+	}
+
+	anonymous := stream(0)
+	for _, msg := range strings.Split(anonymous, "\n\n") {
+		if strings.Contains(msg, "event: post.created") && strings.Contains(msg, "Secret draft") {
+			t.Errorf("Expected the draft's creation to be hidden, got:\n%s", msg)
+		}
+	}
+	if strings.Count(anonymous, "event: post.created") != 1 || strings.Contains(anonymous, "event: comment.created") {
+		t.Errorf("Expected only the public post's creation, got:\n%s", anonymous)
+	}
+	if !strings.Contains(anonymous, `"id":`+strconv.Itoa(public)) || !strings.Contains(anonymous, "event: post.updated") {
+		t.Errorf("Expected the public post and the draft's publication, got:\n%s", anonymous)
+	}
+
+	own := stream(author)
+	if strings.Count(own, "event: post.created") != 2 || !strings.Contains(own, "event: comment.created") {
+		t.Errorf("Expected the author to see their draft and its comment, got:\n%s", own)
+	}
+}
+
+func TestEventStreamUserEmails(t *testing.T) {
+	ctx := context.Background()
+	log := events.NewLog(10)
+	bus := services.NewBus()
+	users := services.NewUserService(bus)
+	services.ForwardEvents(bus, services.NewPostService(bus), log)
+	h := NewEventHandler(log, time.Minute)
+
+	// The first event is only there to resume after.
+	users.Register(ctx, "First", "first@example.com")
+	alice, _ := users.Register(ctx, "Alice", "alice@example.com")
+	bob, _ := users.Register(ctx, "Bob", "bob@example.com")
+
+	// stream returns the registrations after the first seen by viewer, or by an anonymous client if zero.
+	stream := func(viewer int) string {
+		reqCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if viewer != 0 {
+			reqCtx = middleware.WithUserID(reqCtx, viewer)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/events?types=user.registered", nil).WithContext(reqCtx)
+		req.Header.Set("Last-Event-ID", "1")
+		rr := httptest.NewRecorder()
+		h.Stream(rr, req)
+		return rr.Body.String()
+	}
+
+	anonymous := stream(0)
+	if strings.Count(anonymous, "event: user.registered") != 2 || !strings.Contains(anonymous, `"handle":"alice"`) {
+		t.Errorf("Expected both registrations with their handles, got:\n%s", anonymous)
+	}
+	if strings.Contains(anonymous, "@example.com") {
+		t.Errorf("Expected no emails for an anonymous client, got:\n%s", anonymous)
+	}
+	own := stream(alice)
+	if !strings.Contains(own, "alice@example.com") || strings.Contains(own, "bob@example.com") {
+		t.Errorf("Expected user %d to see only their own email, got:\n%s", alice, own)
+	}
+	if other := stream(bob); strings.Contains(other, "alice@example.com") {
+		t.Errorf("Expected user %d not to see another user's email, got:\n%s", bob, other)
+	}
+}
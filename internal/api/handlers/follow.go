@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"net/http"
+	"strconv"
+)
+
+// FollowHandler handles HTTP requests related to following users and the home feed.
+// It contains references to the follow and feed services.
+type FollowHandler struct {
+	follows *services.FollowService
+	feed    *services.FeedService
+}
+
+// NewFollowHandler creates a new instance of FollowHandler with the provided services.
+// It returns a pointer to the newly created FollowHandler.
+func NewFollowHandler(follows *services.FollowService, feed *services.FeedService) *FollowHandler {
+	return &FollowHandler{follows: follows, feed: feed}
+}
+
+// Follow handles POST /users/{id}/follow endpoint.
+// @Summary Follow user
+// @Description Make the caller follow a user. Following a user already followed succeeds without changes.
+// @Tags follows
+// @Param id path int true "User ID to follow"
+// @Success 204 "No Content"
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/{id}/follow [post]
+func (h *FollowHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	err = h.follows.Follow(r.Context(), viewer, id)
+	if errors.Is(err, services.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unfollow handles DELETE /users/{id}/follow endpoint.
+// @Summary Unfollow user
+// @Description Make the caller stop following a user
+// @Tags follows
+// @Param id path int true "User ID to unfollow"
+// @Success 204 "No Content"
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /users/{id}/follow [delete]
+func (h *FollowHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	if !h.follows.Unfollow(r.Context(), viewer, id) {
+		http.Error(w, "Not following this user", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Followers handles GET /users/{id}/followers endpoint.
+// @Summary Get followers
+// @Description Retrieve the users following a user, most recent first
+// @Tags follows
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.User
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /users/{id}/followers [get]
+func (h *FollowHandler) Followers(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.follows.Followers)
+}
+
+// Following handles GET /users/{id}/following endpoint.
+// @Summary Get followed users
+// @Description Retrieve the users a user follows, most recent first
+// @Tags follows
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.User
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /users/{id}/following [get]
+func (h *FollowHandler) Following(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.follows.Following)
+}
+
+// Feed handles GET /feed endpoint.
+// @Summary Get home feed
+// @Description Retrieve published posts by the users the caller follows, most recently published first.
+// @Description Pass next_cursor from a page as cursor to fetch the following page.
+// @Tags follows
+// @Produce json
+// @Param cursor query string false "Cursor from a previous page"
+// @Param limit query int false "Maximum number of posts (default 20, max 100)"
+// @Success 200 {object} models.FeedPage
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Router /feed [get]
+func (h *FollowHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(w, r.URL.Query().Get("limit"), "limit", services.DefaultFeedLimit)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > services.MaxFeedLimit {
+		http.Error(w, "limit must be between 1 and "+strconv.Itoa(services.MaxFeedLimit), http.StatusBadRequest)
+		return
+	}
+	page, err := h.feed.Feed(r.Context(), viewer, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *FollowHandler) list(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, userID int) ([]models.User, error)) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	users, err := list(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"net/http"
+	"strconv"
+)
+
+// NotificationHandler handles HTTP requests related to the caller's notifications.
+// It contains a reference to the notification service.
+type NotificationHandler struct {
+	service *services.NotificationService
+}
+
+// NewNotificationHandler creates a new instance of NotificationHandler with the provided service.
+// It returns a pointer to the newly created NotificationHandler.
+func NewNotificationHandler(service *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// List handles GET /notifications endpoint.
+// @Summary Get notifications
+// @Description Retrieve the caller's notifications, most recently updated first, with the number of unread ones
+// @Tags notifications
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query int false "Maximum number of notifications (default 20, max 100)"
+// @Param offset query int false "Number of notifications to skip"
+// @Success 200 {object} models.NotificationPage
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Router /notifications [get]
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	unreadOnly, err := strconv.ParseBool(query.Get("unread"))
+	if query.Get("unread") != "" && err != nil {
+		http.Error(w, "unread must be true or false", http.StatusBadRequest)
+		return
+	}
+	limit, ok := queryInt(w, query.Get("limit"), "limit", services.DefaultNotificationLimit)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > services.MaxNotificationLimit {
+		http.Error(w, "limit must be between 1 and "+strconv.Itoa(services.MaxNotificationLimit), http.StatusBadRequest)
+		return
+	}
+	offset, ok := queryInt(w, query.Get("offset"), "offset", 0)
+	if !ok {
+		return
+	}
+	if offset < 0 {
+		http.Error(w, "offset must not be negative", http.StatusBadRequest)
+		return
+	}
+	page := h.service.List(r.Context(), viewer, unreadOnly, limit, offset)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// MarkRead handles POST /notifications/read endpoint.
+// @Summary Mark notifications as read
+// @Description Mark the listed notifications of the caller as read, or all of them with "all": true.
+// @Description Returns the number of unread notifications left. At most 100 ids may be given at once.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body object true "Object with ids, an array of notification IDs, or all"
+// @Success 200 {object} map[string]int
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Router /notifications/read [post]
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		IDs []int `json:"ids"`
+		All bool  `json:"all"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	var unread int
+	switch {
+	case input.All:
+		h.service.MarkAllRead(r.Context(), viewer)
+	case len(input.IDs) > services.MaxNotificationLimit:
+		http.Error(w, "ids must have at most "+strconv.Itoa(services.MaxNotificationLimit)+" elements", http.StatusBadRequest)
+		return
+	case len(input.IDs) > 0:
+		unread = h.service.MarkRead(r.Context(), viewer, input.IDs)
+	default:
+		http.Error(w, "ids or all is required", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"unread": unread})
+}
+
+// Preferences handles GET /notifications/preferences endpoint.
+// @Summary Get notification preferences
+// @Description Retrieve the notification categories the caller has muted
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 401 {string} string
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) Preferences(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.Preferences(r.Context(), viewer))
+}
+
+// SetPreferences handles PUT /notifications/preferences endpoint.
+// @Summary Set notification preferences
+// @Description Replace the caller's muted notification categories (comment, mention, follow, reaction).
+// @Description Muting a category stops new notifications of that type.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param preferences body models.NotificationPreferences true "Notification preferences"
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	var input models.NotificationPreferences
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	prefs, err := h.service.SetPreferences(r.Context(), viewer, input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
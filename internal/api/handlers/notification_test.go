@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"example/api/internal/api/middleware"
+	"example/api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNotificationHandlerMarkRead(t *testing.T) {
+	bus := services.NewBus()
+	users := services.NewUserService(bus)
+	posts := services.NewPostService(bus)
+	notifications := services.NewNotificationService(posts, users, services.NewCommentService(posts))
+	h := NewNotificationHandler(notifications)
+
+	markRead := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/notifications/read", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(middleware.WithUserID(req.Context(), 1))
+		rr := httptest.NewRecorder()
+		h.MarkRead(rr, req)
+		return rr
+	}
+	ids := func(n int) string {
+		list := make([]string, n)
+		for i := range list {
+			list[i] = strconv.Itoa(i + 1)
+		}
+		return `{"ids": [` + strings.Join(list, ",") + `]}`
+	}
+
+	t.Run("Up to the limit of ids", func(t *testing.T) {
+		if rr := markRead(ids(services.MaxNotificationLimit)); rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d: %s", rr.Code, rr.Body)
+		}
+	})
+
+	t.Run("Too many ids", func(t *testing.T) {
+		if rr := markRead(ids(services.MaxNotificationLimit + 1)); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rr.Code)
+		}
+	})
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"example/api/internal/models"
 	"example/api/internal/services"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // PostHandler handles HTTP requests related to post operations.
@@ -21,25 +24,51 @@ func NewPostHandler(service *services.PostService) *PostHandler {
 
 // Create handles POST /posts endpoint.
 // @Summary Create a new post
-// @Description Create a new post with the provided title, content, and user ID
+// @Description Create a new post by the caller with the provided title and content.
+// @Description The optional status (draft or published) and publish_at fields create drafts or scheduled posts.
+// @Description The optional tags array attaches tags to the post.
+// @Description The optional format (plain or markdown, default plain) selects how content is rendered into content_html.
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param post body object true "Post object"
 // @Success 201 {object} map[string]int
 // @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 413 {string} string
+// @Failure 415 {string} string
 // @Router /posts [post]
 func (h *PostHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
 	var input struct {
 		Title   string `json:"title"`
 		Content string `json:"content"`
-		UserID  int    `json:"user_id"`
+		// Status optionally creates the post as a draft or scheduled post
+		Status models.PostStatus `json:"status"`
+		// PublishAt optionally schedules the post for later publication
+		PublishAt *time.Time `json:"publish_at"`
+		// Tags optionally attaches tags to the post
+		Tags []string `json:"tags"`
+		// Format optionally declares the content as markdown
+		Format models.ContentFormat `json:"format"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &input) {
 		return
 	}
-	id, err := h.service.Create(input.Title, input.Content, input.UserID)
+	opts := []services.PostOption{services.WithTags(input.Tags...)}
+	if input.Status != "" {
+		opts = append(opts, services.WithStatus(input.Status))
+	}
+	if input.PublishAt != nil {
+		opts = append(opts, services.WithPublishAt(*input.PublishAt))
+	}
+	if input.Format != "" {
+		opts = append(opts, services.WithFormat(input.Format))
+	}
+	id, err := h.service.Create(r.Context(), input.Title, input.Content, userID, opts...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -50,13 +79,48 @@ func (h *PostHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // List handles GET /posts endpoint.
 // @Summary Get all posts
-// @Description Retrieve a list of all posts
+// @Description Retrieve a list of all published posts, plus the caller's own unpublished posts.
+// @Description tag keeps posts carrying all the given tags, any_tag keeps posts carrying at least one of them.
+// @Description sort=popular orders posts by the number of reactions received during the last window.
 // @Tags posts
 // @Produce json
+// @Param tag query string false "Comma-separated tags that must all be present"
+// @Param any_tag query string false "Comma-separated tags of which at least one must be present"
+// @Param sort query string false "popular orders posts by reactions received during window"
+// @Param window query string false "Popularity window as a duration such as 24h (default 168h)"
 // @Success 200 {array} models.Post
+// @Failure 400 {string} string
 // @Router /posts [get]
 func (h *PostHandler) List(w http.ResponseWriter, r *http.Request) {
-	posts := h.service.List()
+	posts := h.service.List(r.Context())
+	allOf := splitTags(r.URL.Query().Get("tag"))
+	anyOf := splitTags(r.URL.Query().Get("any_tag"))
+	if len(allOf) > 0 || len(anyOf) > 0 {
+		var err error
+		posts, err = h.service.FindByTags(r.Context(), allOf, anyOf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	posts = visiblePosts(posts, viewerID(r))
+	switch r.URL.Query().Get("sort") {
+	case "":
+	case "popular":
+		window := services.DefaultPopularWindow
+		if v := r.URL.Query().Get("window"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				http.Error(w, "window must be a positive duration such as 24h", http.StatusBadRequest)
+				return
+			}
+			window = d
+		}
+		posts = h.service.SortByPopularity(r.Context(), posts, window)
+	default:
+		http.Error(w, "sort must be popular", http.StatusBadRequest)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(posts)
 }
@@ -78,9 +142,35 @@ func (h *PostHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid post ID", http.StatusBadRequest)
 		return
 	}
-	post, err := h.service.FindByID(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	post, err := h.service.FindByID(r.Context(), id)
+	if err != nil || !post.VisibleTo(viewerID(r)) {
+		http.Error(w, services.ErrPostNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+// FindBySlug handles GET /posts/by-slug/{slug} endpoint.
+// Slugs retired by a title change redirect permanently to the post's current slug.
+// @Summary Get post by slug
+// @Description Retrieve a specific post by its slug, redirecting from previous slugs
+// @Tags posts
+// @Produce json
+// @Param slug path string true "Post slug"
+// @Success 200 {object} models.Post
+// @Success 301 {string} string "Location of the post's current slug"
+// @Failure 404 {string} string
+// @Router /posts/by-slug/{slug} [get]
+func (h *PostHandler) FindBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := r.URL.Path[len("/posts/by-slug/"):]
+	post, err := h.service.FindBySlug(r.Context(), slug)
+	if err != nil || !post.VisibleTo(viewerID(r)) {
+		http.Error(w, services.ErrPostNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if post.Slug != slug {
+		http.Redirect(w, r, "/posts/by-slug/"+post.Slug, http.StatusMovedPermanently)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -89,7 +179,7 @@ func (h *PostHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 
 // FindByUserID handles GET /users/{id}/posts endpoint.
 // @Summary Get posts by user ID
-// @Description Retrieve all posts for a specific user
+// @Description Retrieve all posts for a specific user that are visible to the caller
 // @Tags posts
 // @Produce json
 // @Param id path int true "User ID"
@@ -97,37 +187,152 @@ func (h *PostHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 // @Failure 400 {string} string
 // @Router /users/{id}/posts [get]
 func (h *PostHandler) FindByUserID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/users/"):]
-	idStr = idStr[:len(idStr)-len("/posts")]
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
-	posts := h.service.FindByUserID(id)
+	posts := visiblePosts(h.service.FindByUserID(r.Context(), id), viewerID(r))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(posts)
+}
+
+// Mentions handles GET /users/{id}/mentions endpoint.
+// @Summary Get posts mentioning a user
+// @Description Retrieve the posts visible to the caller that @mention a user, most recent first
+// @Tags posts
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.Post
+// @Failure 400 {string} string
+// @Router /users/{id}/mentions [get]
+func (h *PostHandler) Mentions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	posts := visiblePosts(h.service.MentionsOf(r.Context(), id), viewerID(r))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(posts)
 }
 
 // Delete handles DELETE /posts/{id} endpoint.
 // @Summary Delete post
-// @Description Delete a post by its ID
+// @Description Delete a post by its ID. Only the author may delete a post.
 // @Tags posts
 // @Param id path int true "Post ID"
 // @Success 204 "No Content"
 // @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
 // @Failure 404 {string} string
 // @Router /posts/{id} [delete]
 func (h *PostHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/posts/"):]
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+	post, ok := h.visiblePost(w, r)
+	if !ok || !requireAuthor(w, r, post.UserID) {
 		return
 	}
-	if !h.service.Delete(id) {
-		http.Error(w, "Post not found", http.StatusNotFound)
+	if !h.service.Delete(r.Context(), post.ID) {
+		http.Error(w, services.ErrPostNotFound.Error(), http.StatusNotFound)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Publish handles POST /posts/{id}/publish endpoint.
+// @Summary Publish post
+// @Description Publish a draft, scheduled or archived post. A future publish_at in the optional body schedules it instead.
+// @Description Only the author may publish a post.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param schedule body object false "Optional object with publish_at"
+// @Success 200 {object} models.Post
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Router /posts/{id}/publish [post]
+func (h *PostHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		PublishAt *time.Time `json:"publish_at"`
+	}
+	if r.ContentLength != 0 && !decodeJSON(w, r, &input) {
+		return
+	}
+	h.transition(w, r, func(id int) (models.Post, error) {
+		return h.service.Publish(r.Context(), id, input.PublishAt)
+	})
+}
+
+// Archive handles POST /posts/{id}/archive endpoint.
+// @Summary Archive post
+// @Description Archive a post so that only its author can see it. Only the author may archive a post.
+// @Tags posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {object} models.Post
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Router /posts/{id}/archive [post]
+func (h *PostHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, func(id int) (models.Post, error) {
+		return h.service.Archive(r.Context(), id)
+	})
+}
+
+// transition runs a status change on the post named in the path after checking
+// that the caller is its author, and writes the updated post.
+func (h *PostHandler) transition(w http.ResponseWriter, r *http.Request, apply func(id int) (models.Post, error)) {
+	post, ok := h.visiblePost(w, r)
+	if !ok || !requireAuthor(w, r, post.UserID) {
+		return
+	}
+	post, err := apply(post.ID)
+	switch {
+	case errors.Is(err, services.ErrPostNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+// visiblePost loads the post named by the {id} path value if the caller may see it.
+// Otherwise it writes a 400 or 404 response and returns false.
+func (h *PostHandler) visiblePost(w http.ResponseWriter, r *http.Request) (models.Post, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return models.Post{}, false
+	}
+	post, err := h.service.FindByID(r.Context(), id)
+	if err != nil || !post.VisibleTo(viewerID(r)) {
+		http.Error(w, services.ErrPostNotFound.Error(), http.StatusNotFound)
+		return models.Post{}, false
+	}
+	return post, true
+}
+
+// visiblePosts keeps the posts the viewer is allowed to see.
+func visiblePosts(posts []models.Post, viewerID int) []models.Post {
+	visible := make([]models.Post, 0, len(posts))
+	for _, p := range posts {
+		if p.VisibleTo(viewerID) {
+			visible = append(visible, p)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"example/api/internal/api/middleware"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPostHandler(t *testing.T) {
+	ctx := context.Background()
+	bus := services.NewBus()
+	users := services.NewUserService(bus)
+	posts := services.NewPostService(bus)
+	h := NewPostHandler(posts)
+
+	author, _ := users.Register(ctx, "Author", "author@example.com")
+	reader, _ := users.Register(ctx, "Reader", "reader@example.com")
+
+	// serve sends a request as viewer, or anonymously if zero, and returns the response.
+	serve := func(viewer int, method string, pattern string, target string, body string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if viewer != 0 {
+			req = req.WithContext(middleware.WithUserID(req.Context(), viewer))
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc(pattern, handler)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	create := func(viewer int, body string) *httptest.ResponseRecorder {
+		return serve(viewer, http.MethodPost, "POST /posts", "/posts", body, h.Create)
+	}
+	remove := func(viewer int, id int) *httptest.ResponseRecorder {
+		return serve(viewer, http.MethodDelete, "DELETE /posts/{id}", "/posts/"+strconv.Itoa(id), "", h.Delete)
+	}
+
+	t.Run("Create posts as the caller", func(t *testing.T) {
+		if rr := create(0, `{"title": "Hi", "content": "Content"}`); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for an anonymous caller, got %d", rr.Code)
+		}
+		if rr := create(reader, `{"title": "Hi", "content": "Content", "user_id": `+strconv.Itoa(author)+`}`); rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body)
+		}
+		if list := posts.FindByUserID(ctx, reader); len(list) != 1 || len(posts.FindByUserID(ctx, author)) != 0 {
+			t.Errorf("Expected 1 post by the caller whatever the body says, got %+v", list)
+		}
+	})
+
+	t.Run("Only the author deletes a post", func(t *testing.T) {
+		id, _ := posts.Create(ctx, "Public", "Content", author)
+		if rr := remove(0, id); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for an anonymous caller, got %d", rr.Code)
+		}
+		if rr := remove(reader, id); rr.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for another user, got %d", rr.Code)
+		}
+		if rr := remove(author, id); rr.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204 for the author, got %d", rr.Code)
+		}
+		if rr := remove(author, id); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 once deleted, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Hidden posts are not found", func(t *testing.T) {
+		draft, _ := posts.Create(ctx, "Draft", "Content", author, services.WithStatus(models.PostDraft))
+		if rr := remove(reader, draft); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 deleting another user's draft, got %d", rr.Code)
+		}
+		if rr := remove(reader, 999); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a missing post, got %d", rr.Code)
+		}
+		if _, err := posts.FindByID(ctx, draft); err != nil {
+			t.Errorf("Expected the draft to remain, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"example/api/internal/services"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// AvatarFormField is the multipart form field carrying an uploaded avatar image.
+const AvatarFormField = "avatar"
+
+// ProfileHandler handles HTTP requests related to user profiles and avatars.
+// It contains references to the user and avatar services.
+type ProfileHandler struct {
+	users   *services.UserService
+	avatars *services.AvatarService
+}
+
+// NewProfileHandler creates a new instance of ProfileHandler with the provided services.
+// It returns a pointer to the newly created ProfileHandler.
+func NewProfileHandler(users *services.UserService, avatars *services.AvatarService) *ProfileHandler {
+	return &ProfileHandler{users: users, avatars: avatars}
+}
+
+// UpdateProfile handles PATCH /users/{id}/profile endpoint.
+// @Summary Update user profile
+// @Description Change a user's handle, display name, bio, location or website. Omitted fields are left unchanged
+// @Description and empty strings clear a field, except the handle, which cannot be empty. Users may only edit their own profile.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param profile body object true "Object with any of handle, display_name, bio, location and website"
+// @Success 200 {object} models.User
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Router /users/{id}/profile [patch]
+func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.ownUserID(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		Handle      *string `json:"handle"`
+		DisplayName *string `json:"display_name"`
+		Bio         *string `json:"bio"`
+		Location    *string `json:"location"`
+		Website     *string `json:"website"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	user, err := h.users.UpdateProfile(r.Context(), id, services.ProfileUpdate{
+		Handle:      input.Handle,
+		DisplayName: input.DisplayName,
+		Bio:         input.Bio,
+		Location:    input.Location,
+		Website:     input.Website,
+	})
+	if errors.Is(err, services.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, services.ErrHandleTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// UploadAvatar handles PUT /users/{id}/avatar endpoint.
+// @Summary Upload avatar
+// @Description Replace a user's profile picture. The image (JPEG, PNG, GIF or WebP, identified by its content)
+// @Description is cropped to a square and resized to 64, 128 and 256 pixel thumbnails. Users may only change their own avatar.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "User ID"
+// @Param avatar formData file true "Image file"
+// @Success 200 {object} models.User
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 413 {string} string
+// @Failure 415 {string} string
+// @Router /users/{id}/avatar [put]
+func (h *ProfileHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.ownUserID(w, r)
+	if !ok {
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "multipart/form-data" {
+		http.Error(w, "Content-Type must be multipart/form-data", http.StatusUnsupportedMediaType)
+		return
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Invalid multipart body", http.StatusBadRequest)
+		return
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			http.Error(w, "Missing "+AvatarFormField+" file", http.StatusBadRequest)
+			return
+		}
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, services.ErrAvatarTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Invalid multipart body", http.StatusBadRequest)
+			return
+		}
+		if part.FormName() != AvatarFormField {
+			part.Close()
+			continue
+		}
+
+		user, err := h.avatars.Upload(r.Context(), id, part)
+		part.Close()
+		switch {
+		case err == nil:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(user)
+		case errors.Is(err, services.ErrUserNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrAvatarTooLarge), errors.As(err, &maxErr):
+			http.Error(w, services.ErrAvatarTooLarge.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, services.ErrUnsupportedImage):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+}
+
+// Avatar handles GET /users/{id}/avatar/{size} endpoint.
+// @Summary Get avatar
+// @Description Retrieve a user's avatar thumbnail as a PNG image. size is 64, 128 or 256.
+// @Tags users
+// @Produce png
+// @Param id path int true "User ID"
+// @Param size path int true "Thumbnail size in pixels"
+// @Success 200 {file} binary
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /users/{id}/avatar/{size} [get]
+func (h *ProfileHandler) Avatar(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	size, err := strconv.Atoi(r.PathValue("size"))
+	if err != nil {
+		http.Error(w, "Invalid avatar size", http.StatusBadRequest)
+		return
+	}
+	rc, err := h.avatars.Open(r.Context(), id, size)
+	if errors.Is(err, services.ErrAvatarNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Could not read avatar", http.StatusInternalServerError)
+		return
+	}
+	defer rc.Close()
+	w.Header().Set("Content-Type", "image/png")
+	// Avatar URLs carry a version parameter that changes with every upload.
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	io.Copy(w, rc)
+}
+
+// ownUserID parses the user ID in the path and checks that the caller is that user.
+// On failure it writes an error response and returns false.
+func (h *ProfileHandler) ownUserID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return 0, false
+	}
+	if !requireSelf(w, r, id) {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"net/http"
+)
+
+// React handles PUT /posts/{id}/reactions/{type} endpoint.
+// @Summary React to post
+// @Description Leave a reaction (like, love, laugh, wow, sad or angry) on a post as the caller.
+// @Description Repeating the same reaction has no further effect.
+// @Tags reactions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param type path string true "Reaction type"
+// @Success 200 {object} models.Post
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id}/reactions/{type} [put]
+func (h *PostHandler) React(w http.ResponseWriter, r *http.Request) {
+	h.react(w, r, h.service.React)
+}
+
+// Unreact handles DELETE /posts/{id}/reactions/{type} endpoint.
+// @Summary Remove reaction
+// @Description Remove the caller's reaction of the given type from a post. Removing a missing reaction succeeds.
+// @Tags reactions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param type path string true "Reaction type"
+// @Success 200 {object} models.Post
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id}/reactions/{type} [delete]
+func (h *PostHandler) Unreact(w http.ResponseWriter, r *http.Request) {
+	h.react(w, r, h.service.Unreact)
+}
+
+// Reactions handles GET /posts/{id}/reactions endpoint.
+// @Summary Get post reactions
+// @Description Retrieve who reacted to a post and how, most recent first
+// @Tags reactions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param type query string false "Only list reactions of this type"
+// @Success 200 {array} models.Reaction
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id}/reactions [get]
+func (h *PostHandler) Reactions(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	reactions, err := h.service.Reactions(r.Context(), post.ID, models.ReactionType(r.URL.Query().Get("type")))
+	if err != nil {
+		h.writeReactionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reactions)
+}
+
+func (h *PostHandler) react(w http.ResponseWriter, r *http.Request, apply func(ctx context.Context, postID int, userID int, reaction models.ReactionType) (models.Post, error)) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	post, err := apply(r.Context(), post.ID, viewer, models.ReactionType(r.PathValue("type")))
+	if err != nil {
+		h.writeReactionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+func (h *PostHandler) writeReactionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrPostNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"example/api/internal/api/middleware"
+	"net/http"
+)
+
+// viewerID returns the authenticated caller's user ID, or 0 for anonymous requests.
+func viewerID(r *http.Request) int {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+// requireViewer returns the authenticated caller's user ID.
+// For anonymous requests it writes a 401 response and returns false.
+func requireViewer(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+	}
+	return id, ok
+}
+
+// requireAuthor checks that the caller is authenticated as the given author.
+// On failure it writes a 401 or 403 response and returns false.
+func requireAuthor(w http.ResponseWriter, r *http.Request, authorID int) bool {
+	return requireUser(w, r, authorID, "Only the author can perform this action")
+}
+
+// requireSelf checks that the caller is authenticated as the given user, for changes to their own account.
+// On failure it writes a 401 or 403 response and returns false.
+func requireSelf(w http.ResponseWriter, r *http.Request, userID int) bool {
+	return requireUser(w, r, userID, "Users can only change their own account")
+}
+
+func requireUser(w http.ResponseWriter, r *http.Request, userID int, forbidden string) bool {
+	id, ok := requireViewer(w, r)
+	if !ok {
+		return false
+	}
+	if id != userID {
+		http.Error(w, forbidden, http.StatusForbidden)
+		return false
+	}
+	return true
+}
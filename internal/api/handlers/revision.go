@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"net/http"
+	"strconv"
+)
+
+// Update handles PUT /posts/{id} endpoint.
+// @Summary Update post
+// @Description Replace a post's title and content, recording a new revision. Only the author may edit a post.
+// @Description When tags is present the post's tags are replaced as well.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param post body object true "Object with title and content"
+// @Success 200 {object} models.Post
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id} [put]
+func (h *PostHandler) Update(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.visiblePost(w, r)
+	if !ok || !requireAuthor(w, r, post.UserID) {
+		return
+	}
+	var input struct {
+		Title   string    `json:"title"`
+		Content string    `json:"content"`
+		Tags    *[]string `json:"tags"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	var err error
+	if input.Tags != nil {
+		post, err = h.service.UpdateWithTags(r.Context(), post.ID, viewerID(r), input.Title, input.Content, *input.Tags)
+	} else {
+		post, err = h.service.Update(r.Context(), post.ID, viewerID(r), input.Title, input.Content)
+	}
+	h.writeEdit(w, post, err)
+}
+
+// Revisions handles GET /posts/{id}/revisions endpoint.
+// @Summary Get post revisions
+// @Description Retrieve the revision history of a post, oldest first
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {array} models.PostRevision
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id}/revisions [get]
+func (h *PostHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	revisions, err := h.service.Revisions(r.Context(), post.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// Revision handles GET /posts/{id}/revisions/{n} endpoint.
+// @Summary Get post revision
+// @Description Retrieve a single revision of a post by its number
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param n path int true "Revision number"
+// @Success 200 {object} models.PostRevision
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id}/revisions/{n} [get]
+func (h *PostHandler) Revision(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		http.Error(w, "Invalid revision number", http.StatusBadRequest)
+		return
+	}
+	revision, err := h.service.Revision(r.Context(), post.ID, n)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revision)
+}
+
+// Diff handles GET /posts/{id}/revisions/diff endpoint.
+// @Summary Diff post revisions
+// @Description Compare two revisions of a post line by line. Revisions whose title or content has more
+// @Description than 10000 lines, or differs in more than 1000 lines unless no line is shared, cannot be compared.
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param from query int true "Older revision number"
+// @Param to query int true "Newer revision number"
+// @Success 200 {object} models.RevisionDiff
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 422 {string} string "A revision has too many lines, or the revisions too many differences"
+// @Router /posts/{id}/revisions/diff [get]
+func (h *PostHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil {
+		http.Error(w, "Query parameters from and to must be revision numbers", http.StatusBadRequest)
+		return
+	}
+	diff, err := h.service.Diff(r.Context(), post.ID, from, to)
+	if errors.Is(err, services.ErrDiffTooLarge) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// Restore handles POST /posts/{id}/revisions/{n}/restore endpoint.
+// @Summary Restore post revision
+// @Description Restore the title and content of an earlier revision as a new revision. Only the author may restore.
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param n path int true "Revision number"
+// @Success 200 {object} models.Post
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /posts/{id}/revisions/{n}/restore [post]
+func (h *PostHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.visiblePost(w, r)
+	if !ok || !requireAuthor(w, r, post.UserID) {
+		return
+	}
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		http.Error(w, "Invalid revision number", http.StatusBadRequest)
+		return
+	}
+	post, err = h.service.Restore(r.Context(), post.ID, n, viewerID(r))
+	h.writeEdit(w, post, err)
+}
+
+// writeEdit writes the result of an edit: the post, or a 404 or 400 error.
+func (h *PostHandler) writeEdit(w http.ResponseWriter, post models.Post, err error) {
+	if errors.Is(err, services.ErrPostNotFound) || errors.Is(err, services.ErrRevisionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"example/api/internal/search"
+	"example/api/internal/services"
+	"net/http"
+	"strconv"
+)
+
+// Search pagination limits.
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// SearchHandler handles full-text search requests.
+// It contains a reference to the search service that owns the index.
+type SearchHandler struct {
+	service *services.SearchService
+}
+
+// NewSearchHandler creates a new instance of SearchHandler with the provided search service.
+// It returns a pointer to the newly created SearchHandler.
+func NewSearchHandler(service *services.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// SearchResponse is a page of search results.
+type SearchResponse struct {
+	Query   string       `json:"query"`
+	Total   int          `json:"total"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+	Results []search.Hit `json:"results"`
+}
+
+// Search handles GET /search endpoint.
+// @Summary Search posts and users
+// @Description Full-text search over published posts and users, ranked by relevance.
+// @Description Supports "quoted phrases" and prefix* terms; every term must match.
+// @Description Snippets are HTML-escaped with matches wrapped in <mark>.
+// @Tags search
+// @Produce json
+// @Param q query string true "Query"
+// @Param type query string false "Restrict results to posts or users"
+// @Param limit query int false "Maximum number of results (default 10, max 50)"
+// @Param offset query int false "Number of results to skip"
+// @Success 200 {object} SearchResponse
+// @Failure 400 {string} string
+// @Router /search [get]
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := q.Get("q")
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	var kind string
+	switch q.Get("type") {
+	case "":
+	case "posts":
+		kind = services.SearchPosts
+	case "users":
+		kind = services.SearchUsers
+	default:
+		http.Error(w, "type must be posts or users", http.StatusBadRequest)
+		return
+	}
+
+	limit, ok := queryInt(w, q.Get("limit"), "limit", defaultSearchLimit)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxSearchLimit), http.StatusBadRequest)
+		return
+	}
+	offset, ok := queryInt(w, q.Get("offset"), "offset", 0)
+	if !ok {
+		return
+	}
+	if offset < 0 {
+		http.Error(w, "offset cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	results := h.service.Search(r.Context(), query, search.Options{Kind: kind, Limit: limit, Offset: offset})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SearchResponse{
+		Query:   query,
+		Total:   results.Total,
+		Limit:   limit,
+		Offset:  offset,
+		Results: results.Hits,
+	})
+}
+
+// queryInt parses an optional integer query parameter, writing a 400 response if it is malformed.
+func queryInt(w http.ResponseWriter, value string, name string, def int) (int, bool) {
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		http.Error(w, name+" must be an integer", http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
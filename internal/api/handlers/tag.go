@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"example/api/internal/services"
+	"net/http"
+	"strings"
+)
+
+// TagHandler handles HTTP requests related to post tags.
+// It contains a reference to the post service that maintains the tag index.
+type TagHandler struct {
+	service *services.PostService
+}
+
+// NewTagHandler creates a new instance of TagHandler with the provided post service.
+// It returns a pointer to the newly created TagHandler.
+func NewTagHandler(service *services.PostService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// List handles GET /tags endpoint.
+// @Summary Get all tags
+// @Description Retrieve every tag with the number of published posts carrying it, most used first
+// @Tags tags
+// @Produce json
+// @Success 200 {array} models.TagCount
+// @Router /tags [get]
+func (h *TagHandler) List(w http.ResponseWriter, r *http.Request) {
+	tags := h.service.Tags(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// Posts handles GET /tags/{tag}/posts endpoint.
+// @Summary Get posts by tag
+// @Description Retrieve the posts visible to the caller that carry a tag
+// @Tags tags
+// @Produce json
+// @Param tag path string true "Tag"
+// @Success 200 {array} models.Post
+// @Failure 400 {string} string
+// @Router /tags/{tag}/posts [get]
+func (h *TagHandler) Posts(w http.ResponseWriter, r *http.Request) {
+	posts, err := h.service.FindByTags(r.Context(), []string{r.PathValue("tag")}, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(visiblePosts(posts, viewerID(r)))
+}
+
+// Merge handles POST /admin/tags/merge endpoint.
+// @Summary Merge tags
+// @Description Replace the source tags with the target tag on every post, atomically. Requires an admin API key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param merge body object true "Object with sources (array of tags) and target"
+// @Success 200 {object} map[string]int
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /admin/tags/merge [post]
+func (h *TagHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Sources []string `json:"sources"`
+		Target  string   `json:"target"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	h.merge(w, r, input.Sources, input.Target)
+}
+
+// Rename handles POST /admin/tags/{tag}/rename endpoint.
+// @Summary Rename tag
+// @Description Rename a tag on every post, atomically. Renaming to an existing tag merges them. Requires an admin API key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param tag path string true "Tag"
+// @Param rename body object true "Object with the new name in to"
+// @Success 200 {object} map[string]int
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /admin/tags/{tag}/rename [post]
+func (h *TagHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		To string `json:"to"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	h.merge(w, r, []string{r.PathValue("tag")}, input.To)
+}
+
+func (h *TagHandler) merge(w http.ResponseWriter, r *http.Request, sources []string, target string) {
+	n, err := h.service.MergeTags(r.Context(), sources, target)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"posts_updated": n})
+}
+
+// splitTags parses a comma-separated tag list from a query parameter, ignoring blanks.
+func splitTags(value string) []string {
+	var tags []string
+	for _, t := range strings.Split(value, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
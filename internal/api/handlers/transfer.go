@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"example/api/internal/services"
+	"example/api/internal/transfer"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// DefaultMaxImportBytes is the import body size limit used unless configured otherwise.
+const DefaultMaxImportBytes = 64 << 20
+
+// TransferHandler handles HTTP requests for bulk imports and exports of users and posts.
+// It contains a reference to the transfer service.
+type TransferHandler struct {
+	service *services.TransferService
+}
+
+// NewTransferHandler creates a new instance of TransferHandler with the provided service.
+// It returns a pointer to the newly created TransferHandler.
+func NewTransferHandler(service *services.TransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+// Import handles POST /admin/import endpoint.
+// @Summary Import users and posts
+// @Description Create users and posts from NDJSON (one record per line) or CSV (with a header row), in the
+// @Description format of GET /admin/export. Records are imported in order and a failed record does not stop
+// @Description the import; the report lists each failure with its line. A post's user_id refers to a user
+// @Description record of the import with that id, or else to an existing user, and the report maps the records'
+// @Description ids to the ids created. With dry_run, nothing is changed. Requires an admin API key.
+// @Tags admin
+// @Accept application/x-ndjson
+// @Accept text/csv
+// @Produce json
+// @Param format query string false "Format of the body, instead of its Content-Type" Enums(ndjson, csv)
+// @Param dry_run query bool false "Only validate the records"
+// @Success 200 {object} transfer.Report
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 415 {string} string
+// @Router /admin/import [post]
+func (h *TransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	}
+	format, err := transfer.ParseFormat(name)
+	if err != nil || format == transfer.JSON {
+		http.Error(w, "Content-Type must be application/x-ndjson or text/csv, or format must be ndjson or csv", http.StatusUnsupportedMediaType)
+		return
+	}
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "dry_run must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report := h.service.Import(r.Context(), transfer.Read(r.Body, format), dryRun)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// Export handles GET /admin/export endpoint.
+// @Summary Export users and posts
+// @Description Stream every user, then every post whatever its status, as NDJSON (one record per line), CSV
+// @Description (with a header row; tags are joined with commas) or a JSON array. The output can be imported
+// @Description with POST /admin/import. Requires an admin API key.
+// @Tags admin
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce json
+// @Param format query string false "Output format" Enums(ndjson, csv, json) default(ndjson)
+// @Success 200 {array} transfer.Record
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /admin/export [get]
+func (h *TransferHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := transfer.NDJSON
+	if v := r.URL.Query().Get("format"); v != "" {
+		var err error
+		if format, err = transfer.ParseFormat(v); err != nil {
+			http.Error(w, "format must be ndjson, csv or json", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="export.`+string(format)+`"`)
+	tw := transfer.NewWriter(w, format)
+	for rec := range h.service.Export(r.Context()) {
+		if tw.Write(rec) != nil {
+			// The client has gone away; the response cannot be completed.
+			return
+		}
+	}
+	tw.Close()
+}
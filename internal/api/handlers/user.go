@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"example/api/internal/services"
 	"net/http"
 	"strconv"
@@ -22,24 +23,36 @@ func NewUserhandler(service *services.UserService) *UserHandler {
 
 // Register handles POST /users endpoint.
 // @Summary Create a new user
-// @Description Create a new user with the provided name and email
+// @Description Create a new user with the provided name, email and optional handle.
+// @Description Without a handle, one is derived from the name.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body object true "User object"
 // @Success 201 {object} map[string]int
 // @Failure 400 {string} string
+// @Failure 409 {string} string
+// @Failure 413 {string} string
+// @Failure 415 {string} string
 // @Router /users [post]
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
+		Name   string `json:"name"`
+		Email  string `json:"email"`
+		Handle string `json:"handle"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	var opts []services.UserOption
+	if input.Handle != "" {
+		opts = append(opts, services.WithHandle(input.Handle))
+	}
+	id, err := h.service.Register(r.Context(), input.Name, input.Email, opts...)
+	if errors.Is(err, services.ErrHandleTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
 		return
 	}
-	id, err := h.service.Register(input.Name, input.Email)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -56,7 +69,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {array} models.User
 // @Router /users [get]
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
-	users := h.service.List()
+	users := h.service.List(r.Context())
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(users)
 }
@@ -78,7 +91,7 @@ func (h *UserHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
-	user, err := h.service.FindByID(id)
+	user, err := h.service.FindByID(r.Context(), id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -103,7 +116,7 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
-	if !h.service.Delete(id) {
+	if !h.service.Delete(r.Context(), id) {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
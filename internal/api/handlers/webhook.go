@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// WebhookHandler handles HTTP requests related to the caller's webhooks and their deliveries.
+// It contains a reference to the webhook service.
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+// NewWebhookHandler creates a new instance of WebhookHandler with the provided service.
+// It returns a pointer to the newly created WebhookHandler.
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// Create handles POST /webhooks endpoint.
+// @Summary Create webhook
+// @Description Subscribe a URL to events. Each delivery is POSTed with the X-Webhook-ID, X-Webhook-Event,
+// @Description X-Webhook-Timestamp and X-Webhook-Signature headers; the signature is "sha256=" followed by the
+// @Description hex HMAC-SHA256 of "{timestamp}.{body}" keyed with the secret. A random secret is generated if
+// @Description none is given; it is only returned in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body object true "Object with url, events (array of event types) and optionally secret"
+// @Success 201 {object} models.Webhook
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+		Secret string   `json:"secret"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	webhook, err := h.service.Create(r.Context(), viewer, services.WebhookInput{
+		URL:    input.URL,
+		Events: input.Events,
+		Secret: input.Secret,
+	})
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// List handles GET /webhooks endpoint.
+// @Summary Get webhooks
+// @Description Retrieve the caller's webhooks, oldest first, without their secrets
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} models.Webhook
+// @Failure 401 {string} string
+// @Router /webhooks [get]
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.List(r.Context(), viewer))
+}
+
+// Find handles GET /webhooks/{id} endpoint.
+// @Summary Get webhook
+// @Description Retrieve one of the caller's webhooks, without its secret
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) Find(w http.ResponseWriter, r *http.Request) {
+	viewer, id, ok := webhookRequest(w, r)
+	if !ok {
+		return
+	}
+	webhook, err := h.service.Find(r.Context(), viewer, id)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// Update handles PATCH /webhooks/{id} endpoint.
+// @Summary Update webhook
+// @Description Change the URL, event types or secret of one of the caller's webhooks, or pause it with
+// @Description "active": false. Omitted fields are left as they are. Paused webhooks receive no new events.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param webhook body object true "Object with any of url, events, secret and active"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /webhooks/{id} [patch]
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	viewer, id, ok := webhookRequest(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		URL    *string  `json:"url"`
+		Events []string `json:"events"`
+		Secret *string  `json:"secret"`
+		Active *bool    `json:"active"`
+	}
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	webhook, err := h.service.Update(r.Context(), viewer, id, services.WebhookUpdate{
+		URL:    input.URL,
+		Events: input.Events,
+		Secret: input.Secret,
+		Active: input.Active,
+	})
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// Delete handles DELETE /webhooks/{id} endpoint.
+// @Summary Delete webhook
+// @Description Delete one of the caller's webhooks and its delivery log
+// @Tags webhooks
+// @Param id path int true "Webhook ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	viewer, id, ok := webhookRequest(w, r)
+	if !ok {
+		return
+	}
+	if !h.service.Delete(r.Context(), viewer, id) {
+		http.Error(w, services.ErrWebhookNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries handles GET /webhooks/{id}/deliveries endpoint.
+// @Summary Get webhook deliveries
+// @Description Retrieve the delivery log of one of the caller's webhooks, most recent first, with every attempt
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "Only deliveries in this state (pending, succeeded or dead)"
+// @Param limit query int false "Maximum number of deliveries (default 20, max 100)"
+// @Param offset query int false "Number of deliveries to skip"
+// @Success 200 {object} models.WebhookDeliveryPage
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	viewer, id, ok := webhookRequest(w, r)
+	if !ok {
+		return
+	}
+	status := models.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+	valid := []models.WebhookDeliveryStatus{"", models.WebhookPending, models.WebhookSucceeded, models.WebhookDead}
+	if !slices.Contains(valid, status) {
+		http.Error(w, "status must be pending, succeeded or dead", http.StatusBadRequest)
+		return
+	}
+	limit, offset, ok := deliveryPageParams(w, r)
+	if !ok {
+		return
+	}
+	page, err := h.service.Deliveries(r.Context(), viewer, id, status, limit, offset)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// DeadLetters handles GET /webhooks/dead-letters endpoint.
+// @Summary Get dead-lettered deliveries
+// @Description Retrieve the deliveries to the caller's webhooks that failed every attempt, most recent first.
+// @Description They can be sent again with the redeliver endpoint.
+// @Tags webhooks
+// @Produce json
+// @Param limit query int false "Maximum number of deliveries (default 20, max 100)"
+// @Param offset query int false "Number of deliveries to skip"
+// @Success 200 {object} models.WebhookDeliveryPage
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Router /webhooks/dead-letters [get]
+func (h *WebhookHandler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return
+	}
+	limit, offset, ok := deliveryPageParams(w, r)
+	if !ok {
+		return
+	}
+	page := h.service.DeadLetters(r.Context(), viewer, limit, offset)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// Redeliver handles POST /webhooks/{id}/deliveries/{deliveryId}/redeliver endpoint.
+// @Summary Redeliver webhook delivery
+// @Description Send a delivery again right away, with the same X-Webhook-ID, and return it with the outcome.
+// @Description If the attempt fails, the delivery is retried on the usual schedule.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	viewer, id, ok := webhookRequest(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(w, r, "deliveryId", "Invalid delivery ID")
+	if !ok {
+		return
+	}
+	delivery, err := h.service.Redeliver(r.Context(), viewer, id, deliveryID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// webhookRequest returns the authenticated caller and the webhook ID in the path,
+// or writes an error response and returns false.
+func webhookRequest(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	viewer, ok := requireViewer(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	id, ok := pathID(w, r, "id", "Invalid webhook ID")
+	return viewer, id, ok
+}
+
+// deliveryPageParams parses the limit and offset query parameters of delivery listings.
+func deliveryPageParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	query := r.URL.Query()
+	limit, ok := queryInt(w, query.Get("limit"), "limit", services.DefaultWebhookDeliveryLimit)
+	if !ok {
+		return 0, 0, false
+	}
+	if limit < 1 || limit > services.MaxWebhookDeliveryLimit {
+		http.Error(w, "limit must be between 1 and "+strconv.Itoa(services.MaxWebhookDeliveryLimit), http.StatusBadRequest)
+		return 0, 0, false
+	}
+	offset, ok := queryInt(w, query.Get("offset"), "offset", 0)
+	if !ok {
+		return 0, 0, false
+	}
+	if offset < 0 {
+		http.Error(w, "offset must not be negative", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound), errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrWebhookDeliveryInFlight):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,558 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"example/api/internal/api/middleware"
+	"example/api/internal/events"
+	"example/api/internal/models"
+	"example/api/internal/services"
+	"example/api/internal/websocket"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket connection limits.
+const (
+	// wsSendBuffer is the number of replies a client may fall behind by before it is disconnected
+	wsSendBuffer = 16
+	// wsMaxMessageBytes is the largest message a client may send
+	wsMaxMessageBytes = 64 << 10
+	// wsMaxChannels is the number of channels a connection may subscribe to
+	wsMaxChannels = 100
+	// wsWriteTimeout is how long a client may take to accept a message before it is disconnected
+	wsWriteTimeout = 10 * time.Second
+	// wsCloseWait is how long to wait for the client's reply to a close frame
+	wsCloseWait = time.Second
+)
+
+// wsEventTypes are the events relayed to WebSocket channels.
+var wsEventTypes = []events.Type{events.PostCreated, events.PostUpdated, events.PostDeleted, events.CommentCreated, events.CommentDeleted}
+
+// errWSAuthRequired is returned for commands sent on anonymous connections.
+var errWSAuthRequired = errors.New("authentication required")
+
+// wsCommand is a message from a client: a subscription change or a command.
+type wsCommand struct {
+	// ID is echoed in the reply so the client can match it to the command
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// wsMessage is a message to a client: the result or error of a command, or an event
+// on one of its channels.
+type wsMessage struct {
+	ID      string        `json:"id,omitempty"`
+	Type    string        `json:"type"`
+	Channel string        `json:"channel,omitempty"`
+	Data    any           `json:"data,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Event   *events.Event `json:"event,omitempty"`
+}
+
+// wsChannel is a stream of events a client can subscribe to: "user:{id}:posts" for
+// the posts of a user, or "post:{id}:comments" for the comments on a post.
+type wsChannel struct {
+	resource string
+	id       int
+}
+
+// parseWSChannel parses a channel name, reporting whether it is valid.
+func parseWSChannel(name string) (wsChannel, bool) {
+	parts := strings.Split(name, ":")
+	if len(parts) != 3 {
+		return wsChannel{}, false
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil || id <= 0 {
+		return wsChannel{}, false
+	}
+	ch := wsChannel{resource: parts[0], id: id}
+	if ch.String() != name {
+		return wsChannel{}, false
+	}
+	return ch, true
+}
+
+func (c wsChannel) String() string {
+	if c.resource == "user" {
+		return fmt.Sprintf("user:%d:posts", c.id)
+	}
+	return fmt.Sprintf("post:%d:comments", c.id)
+}
+
+// match reports whether e belongs on the channel and may be shown to viewerID.
+// Changes to posts the viewer cannot see are left out.
+func (c wsChannel) match(e events.Event, viewerID int) bool {
+	switch c.resource {
+	case "user":
+		if e.UserID != c.id {
+			return false
+		}
+		switch e.Type {
+		case events.PostCreated, events.PostUpdated:
+			post, ok := e.Data.(models.Post)
+			return ok && post.VisibleTo(viewerID)
+		case events.PostDeleted:
+			return true
+		}
+	case "post":
+		if e.PostID != c.id {
+			return false
+		}
+		return e.Type == events.CommentCreated || e.Type == events.CommentDeleted || e.Type == events.PostDeleted
+	}
+	return false
+}
+
+// WSHandler serves live post updates and commands over WebSocket connections.
+// It contains references to the event log, the post and comment services and the
+// auditor of the commands, and the ping interval.
+type WSHandler struct {
+	log      *events.Log
+	posts    *services.PostService
+	comments *services.CommentService
+	auditor  *middleware.CommandAuditor
+	ping     time.Duration
+	origins  []string
+	limiter  *middleware.RateLimiter
+	limits   map[string]WSCommandLimit
+	sessions sync.WaitGroup
+}
+
+// WSCommandLimit is the rate limit of a WebSocket command. The command takes its tokens
+// from the caller's bucket for the HTTP route named Route, so that it is limited together
+// with the requests doing the same.
+type WSCommandLimit struct {
+	Route string
+	Limit middleware.RateLimit
+}
+
+// NewWSHandler creates a new instance of WSHandler relaying events from log. The changes
+// made by commands are recorded with auditor, if not nil. Clients are pinged every ping
+// interval and disconnected if nothing arrives for two intervals.
+// It returns a pointer to the newly created WSHandler.
+func NewWSHandler(log *events.Log, posts *services.PostService, comments *services.CommentService, auditor *middleware.CommandAuditor, ping time.Duration) *WSHandler {
+	return &WSHandler{log: log, posts: posts, comments: comments, auditor: auditor, ping: ping}
+}
+
+// AllowOrigins lets browser pages from origins, such as "https://example.com", open
+// connections, besides those served from the API's own host.
+func (h *WSHandler) AllowOrigins(origins []string) {
+	h.origins = origins
+}
+
+// LimitCommands rate limits the commands named in limits, such as "post.create", with limiter.
+// Commands are not limited otherwise.
+func (h *WSHandler) LimitCommands(limiter *middleware.RateLimiter, limits map[string]WSCommandLimit) {
+	h.limiter = limiter
+	h.limits = limits
+}
+
+// Serve handles GET /ws endpoint.
+// @Summary Live updates over WebSocket
+// @Description Upgrade to a WebSocket connection exchanging JSON text messages.
+// @Description Clients send {"id", "type", "channel", "data"} messages: subscribe and unsubscribe to
+// @Description user:{id}:posts or post:{id}:comments channels, and post.create, post.delete,
+// @Description comment.create and comment.delete commands, which require authentication.
+// @Description Each command is answered with a result or error message carrying its id, and subscribed
+// @Description channels receive event messages with the post and comment events.
+// @Description Commands share the rate limits of the matching HTTP routes, and handshakes from browser pages
+// @Description of other origins than the API's and WS_ALLOWED_ORIGINS are refused.
+// @Description The server pings every connection; clients that stop answering, or fall too far behind
+// @Description on their messages, are disconnected with close code 1013 and should reconnect.
+// @Tags events
+// @Success 101 "Switching Protocols"
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Origin not allowed"
+// @Failure 426 {string} string
+// @Router /ws [get]
+func (h *WSHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r, h.origins)
+	if err != nil {
+		return
+	}
+	h.sessions.Add(1)
+	defer h.sessions.Done()
+	s := &wsSession{
+		handler:  h,
+		conn:     conn,
+		request:  r,
+		viewerID: viewerID(r),
+		channels: make(map[wsChannel]bool),
+		send:     make(chan wsMessage, wsSendBuffer),
+		slow:     make(chan struct{}),
+	}
+	s.run(r.Context())
+}
+
+// Wait blocks until every connection has ended or ctx is done. The HTTP server does not
+// track WebSocket connections, so call it on shutdown, once the event log is closed,
+// to let clients receive their close frames.
+func (h *WSHandler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.sessions.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wsSession is one client connection. Its reader goroutine handles the client's
+// messages and queues the replies; the writer loop in run sends the replies, the
+// events of the subscribed channels and the pings.
+type wsSession struct {
+	handler *WSHandler
+	conn    *websocket.Conn
+	// request opened the connection; commands are audited as made by its caller
+	request  *http.Request
+	viewerID int
+
+	mu       sync.Mutex
+	channels map[wsChannel]bool
+
+	send chan wsMessage
+	// slow is closed by the reader when it stops because the reply queue is full
+	slow chan struct{}
+}
+
+func (s *wsSession) run(ctx context.Context) {
+	h := s.handler
+	sub := h.log.Subscribe(events.Filter{Types: wsEventTypes, ViewerID: s.viewerID}, 0, events.DefaultBufferSize)
+	defer sub.Cancel()
+
+	pongWait := 2 * h.ping
+	s.conn.SetReadLimit(wsMaxMessageBytes)
+	s.conn.SetWriteTimeout(wsWriteTimeout)
+	s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func([]byte) { s.conn.SetReadDeadline(time.Now().Add(pongWait)) })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.read(ctx, pongWait)
+	}()
+	defer func() {
+		s.conn.Close()
+		<-done
+	}()
+
+	ping := time.NewTicker(h.ping)
+	defer ping.Stop()
+	for {
+		select {
+		case <-done:
+			// The client closed the connection, stopped answering, or stopped reading
+			// the replies, in which case the reader gave up.
+			select {
+			case <-s.slow:
+				s.conn.WriteClose(websocket.CloseTryAgainLater, "client too slow")
+			default:
+			}
+			return
+		case <-ping.C:
+			if s.conn.WriteMessage(websocket.PingMessage, nil) != nil {
+				return
+			}
+		case msg := <-s.send:
+			if !s.write(msg) {
+				return
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				if h.log.Closed() {
+					s.close(done, websocket.CloseGoingAway, "server shutting down")
+				} else {
+					s.close(done, websocket.CloseTryAgainLater, "client too slow")
+				}
+				return
+			}
+			for _, msg := range s.deliveries(e) {
+				if !s.write(msg) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// read handles the client's messages until the connection ends, queueing a reply to each.
+func (s *wsSession) read(ctx context.Context, pongWait time.Duration) {
+	for {
+		typ, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		// Any message shows the client is alive.
+		s.conn.SetReadDeadline(time.Now().Add(pongWait))
+		reply := wsMessage{Type: "error", Error: "messages must be JSON text"}
+		if typ == websocket.TextMessage {
+			reply = s.handle(ctx, data)
+		}
+		select {
+		case s.send <- reply:
+		default:
+			close(s.slow)
+			return
+		}
+	}
+}
+
+// write sends msg to the client, reporting whether the connection is still usable.
+func (s *wsSession) write(msg wsMessage) bool {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return true
+	}
+	return s.conn.WriteMessage(websocket.TextMessage, data) == nil
+}
+
+// close starts the closing handshake and waits briefly for the reader to receive the client's reply.
+func (s *wsSession) close(done <-chan struct{}, code int, reason string) {
+	if s.conn.WriteClose(code, reason) != nil {
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(wsCloseWait):
+	}
+}
+
+// deliveries returns a message for every subscribed channel e belongs on.
+func (s *wsSession) deliveries(e events.Event) []wsMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var msgs []wsMessage
+	for ch := range s.channels {
+		if ch.match(e, s.viewerID) {
+			msgs = append(msgs, wsMessage{Type: "event", Channel: ch.String(), Event: &e})
+		}
+	}
+	return msgs
+}
+
+// handle runs a client message and returns the reply.
+func (s *wsSession) handle(ctx context.Context, data []byte) wsMessage {
+	var cmd wsCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return wsMessage{Type: "error", Error: "invalid message"}
+	}
+	result, err := s.dispatch(ctx, cmd)
+	if err != nil {
+		return wsMessage{ID: cmd.ID, Type: "error", Error: err.Error()}
+	}
+	return wsMessage{ID: cmd.ID, Type: "result", Data: result}
+}
+
+func (s *wsSession) dispatch(ctx context.Context, cmd wsCommand) (any, error) {
+	switch cmd.Type {
+	case "subscribe":
+		return s.subscribe(ctx, cmd.Channel)
+	case "unsubscribe":
+		return s.unsubscribe(cmd.Channel)
+	case "post.create", "post.delete", "comment.create", "comment.delete":
+	default:
+		return nil, fmt.Errorf("unknown message type %q", cmd.Type)
+	}
+
+	if s.viewerID == 0 {
+		return nil, errWSAuthRequired
+	}
+	if limit, ok := s.handler.limits[cmd.Type]; ok && s.handler.limiter != nil {
+		if allowed, retryAfter := s.handler.limiter.Allow(s.request, limit.Route, limit.Limit); !allowed {
+			return nil, fmt.Errorf("rate limit exceeded, retry in %d seconds", int(math.Ceil(retryAfter.Seconds())))
+		}
+	}
+	var result any
+	err := s.handler.auditor.Run(s.request, "WS "+cmd.Type, wsResource(cmd), func(ctx context.Context) error {
+		var err error
+		switch cmd.Type {
+		case "post.create":
+			result, err = s.createPost(ctx, cmd.Data)
+		case "post.delete":
+			result, err = s.deletePost(ctx, cmd.Data)
+		case "comment.create":
+			result, err = s.createComment(ctx, cmd.Data)
+		default:
+			result, err = s.deleteComment(ctx, cmd.Data)
+		}
+		return err
+	})
+	return result, err
+}
+
+// wsResource returns the resource a command changes for its audit entry, such as "post:5"
+// for a post.delete of post 5, or "post" for a post.create.
+func wsResource(cmd wsCommand) string {
+	resource, _, _ := strings.Cut(cmd.Type, ".")
+	var target struct {
+		ID int `json:"id"`
+	}
+	if json.Unmarshal(cmd.Data, &target) == nil && target.ID != 0 {
+		resource += ":" + strconv.Itoa(target.ID)
+	}
+	return resource
+}
+
+func (s *wsSession) subscribe(ctx context.Context, name string) (any, error) {
+	ch, ok := parseWSChannel(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid channel %q", name)
+	}
+	if ch.resource == "post" {
+		if _, err := s.visiblePost(ctx, ch.id); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.channels[ch] && len(s.channels) >= wsMaxChannels {
+		return nil, fmt.Errorf("at most %d channels per connection", wsMaxChannels)
+	}
+	s.channels[ch] = true
+	return map[string]string{"channel": name}, nil
+}
+
+func (s *wsSession) unsubscribe(name string) (any, error) {
+	ch, ok := parseWSChannel(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid channel %q", name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.channels, ch)
+	return map[string]string{"channel": name}, nil
+}
+
+// createPost creates a post authored by the caller, with the options of POST /posts.
+func (s *wsSession) createPost(ctx context.Context, data json.RawMessage) (any, error) {
+	var input struct {
+		Title     string               `json:"title"`
+		Content   string               `json:"content"`
+		Status    models.PostStatus    `json:"status"`
+		PublishAt *time.Time           `json:"publish_at"`
+		Tags      []string             `json:"tags"`
+		Format    models.ContentFormat `json:"format"`
+	}
+	if err := unmarshalWSData(data, &input); err != nil {
+		return nil, err
+	}
+	opts := []services.PostOption{services.WithTags(input.Tags...)}
+	if input.Status != "" {
+		opts = append(opts, services.WithStatus(input.Status))
+	}
+	if input.PublishAt != nil {
+		opts = append(opts, services.WithPublishAt(*input.PublishAt))
+	}
+	if input.Format != "" {
+		opts = append(opts, services.WithFormat(input.Format))
+	}
+	id, err := s.handler.posts.Create(ctx, input.Title, input.Content, s.viewerID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"id": id}, nil
+}
+
+// deletePost deletes one of the caller's posts.
+func (s *wsSession) deletePost(ctx context.Context, data json.RawMessage) (any, error) {
+	var input struct {
+		ID int `json:"id"`
+	}
+	if err := unmarshalWSData(data, &input); err != nil {
+		return nil, err
+	}
+	post, err := s.visiblePost(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if post.UserID != s.viewerID {
+		return nil, errors.New("only the author can delete a post")
+	}
+	if !s.handler.posts.Delete(ctx, input.ID) {
+		return nil, services.ErrPostNotFound
+	}
+	return map[string]int{"id": input.ID}, nil
+}
+
+// createComment comments on a post, or replies to a comment, as the caller.
+func (s *wsSession) createComment(ctx context.Context, data json.RawMessage) (any, error) {
+	var input struct {
+		PostID   int    `json:"post_id"`
+		Body     string `json:"body"`
+		ParentID *int   `json:"parent_id"`
+	}
+	if err := unmarshalWSData(data, &input); err != nil {
+		return nil, err
+	}
+	if _, err := s.visiblePost(ctx, input.PostID); err != nil {
+		return nil, err
+	}
+	id, err := s.handler.comments.Create(ctx, input.PostID, s.viewerID, input.Body, input.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"id": id}, nil
+}
+
+// deleteComment deletes one of the caller's comments and the replies to it.
+func (s *wsSession) deleteComment(ctx context.Context, data json.RawMessage) (any, error) {
+	var input struct {
+		ID int `json:"id"`
+	}
+	if err := unmarshalWSData(data, &input); err != nil {
+		return nil, err
+	}
+	comment, err := s.handler.comments.FindByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != s.viewerID {
+		return nil, errors.New("only the author can delete a comment")
+	}
+	if !s.handler.comments.Delete(ctx, input.ID) {
+		return nil, errors.New("comment not found")
+	}
+	return map[string]int{"id": input.ID}, nil
+}
+
+// visiblePost returns the post with the given ID if the caller may see it.
+func (s *wsSession) visiblePost(ctx context.Context, id int) (models.Post, error) {
+	post, err := s.handler.posts.FindByID(ctx, id)
+	if err != nil || !post.VisibleTo(s.viewerID) {
+		return models.Post{}, services.ErrPostNotFound
+	}
+	return post, nil
+}
+
+// unmarshalWSData decodes a command's data, rejecting unknown fields in strict mode
+// like request bodies.
+func unmarshalWSData(data json.RawMessage, dst any) error {
+	if len(data) == 0 {
+		return errors.New("data is required")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if decodeConfig.Strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(dst); err != nil {
+		return errors.New("invalid data")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"example/api/internal/api/middleware"
+	"example/api/internal/audit"
+	"example/api/internal/events"
+	"example/api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWSCommandsAudit(t *testing.T) {
+	ctx := context.Background()
+	bus := services.NewBus()
+	services.RecordAuditChanges(bus)
+	users := services.NewUserService(bus)
+	posts := services.NewPostService(bus)
+	comments := services.NewCommentService(posts)
+	auditLog := audit.NewLog()
+	h := NewWSHandler(events.NewLog(10), posts, comments, middleware.NewCommandAuditor(auditLog, nil), time.Minute)
+
+	author, _ := users.Register(ctx, "Author", "author@example.com")
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req = req.WithContext(middleware.WithUserID(req.Context(), author))
+	s := &wsSession{handler: h, request: req, viewerID: author, channels: make(map[wsChannel]bool)}
+
+	t.Run("Commands are recorded", func(t *testing.T) {
+		reply := s.handle(req.Context(), []byte(`{"id": "1", "type": "post.create", "data": {"title": "Live", "content": "Content"}}`))
+		if reply.Type != "result" {
+			t.Fatalf("Expected a result, got %+v", reply)
+		}
+		id := reply.Data.(map[string]int)["id"]
+		s.handle(req.Context(), []byte(`{"id": "2", "type": "post.delete", "data": {"id": `+strconv.Itoa(id)+`}}`))
+
+		entries := auditLog.Query(audit.Filter{})
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(entries))
+		}
+		resource := "post:" + strconv.Itoa(id)
+		if e := entries[0]; e.Action != "WS post.create" || e.Resource != resource || e.Actor != "user:"+strconv.Itoa(author) || e.After == nil {
+			t.Errorf("Expected the creation of %s by the author, got %+v", resource, e)
+		}
+		if e := entries[1]; e.Action != "WS post.delete" || e.Resource != resource || e.Before == nil || e.After != nil {
+			t.Errorf("Expected the deletion of %s, got %+v", resource, e)
+		}
+	})
+
+	t.Run("Failed commands are not recorded", func(t *testing.T) {
+		before := len(auditLog.Query(audit.Filter{}))
+		reply := s.handle(req.Context(), []byte(`{"id": "3", "type": "post.delete", "data": {"id": 999}}`))
+		if reply.Type != "error" {
+			t.Errorf("Expected an error, got %+v", reply)
+		}
+		if n := len(auditLog.Query(audit.Filter{})) - before; n != 0 {
+			t.Errorf("Expected no entries, got %d", n)
+		}
+	})
+}
+
+func TestWSCommandsRateLimit(t *testing.T) {
+	bus := services.NewBus()
+	posts := services.NewPostService(bus)
+	h := NewWSHandler(events.NewLog(10), posts, services.NewCommentService(posts), nil, time.Minute)
+	limiter := middleware.NewRateLimiter(nil)
+	limit := middleware.PerPeriod(2, time.Minute)
+	h.LimitCommands(limiter, map[string]WSCommandLimit{"post.create": {Route: "posts.create", Limit: limit}})
+
+	withUser := func(r *http.Request) *http.Request {
+		return r.WithContext(middleware.WithUserID(r.Context(), 1))
+	}
+	req := withUser(httptest.NewRequest(http.MethodGet, "/ws", nil))
+	s := &wsSession{handler: h, request: req, viewerID: 1, channels: make(map[wsChannel]bool)}
+	create := []byte(`{"type": "post.create", "data": {"title": "Live", "content": "Content"}}`)
+
+	if reply := s.handle(req.Context(), create); reply.Type != "result" {
+		t.Fatalf("Expected a result, got %+v", reply)
+	}
+	// The command and the HTTP route share the caller's bucket.
+	rr := httptest.NewRecorder()
+	limiter.Limit("posts.create", limit, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})).ServeHTTP(rr, withUser(httptest.NewRequest(http.MethodPost, "/posts", nil)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", rr.Code)
+	}
+	if reply := s.handle(req.Context(), create); reply.Type != "error" || reply.Error == "" {
+		t.Errorf("Expected a rate limit error, got %+v", reply)
+	}
+	if n := len(slices.Collect(posts.All(context.Background()))); n != 1 {
+		t.Errorf("Expected 1 post, got %d", n)
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"example/api/internal/api/problem"
+	"net/http"
+	"strings"
+)
+
+// ParseAPIKeys splits a comma-separated list of API keys, ignoring blanks.
+func ParseAPIKeys(s string) []string {
+	var keys []string
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// RequireAdmin middleware only lets through requests whose API key is one of adminKeys.
+// Requests without an API key get 401, requests with any other key get 403.
+func RequireAdmin(adminKeys []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := APIKeyFromContext(r.Context())
+		if key == "" {
+			p := problem.New(http.StatusUnauthorized, "An admin API key is required.")
+			p.RequestID = RequestIDFromContext(r.Context())
+			problem.Write(w, p)
+			return
+		}
+		if !IsAdmin(r.Context(), adminKeys) {
+			p := problem.New(http.StatusForbidden, "The API key is not allowed to perform admin operations.")
+			p.RequestID = RequestIDFromContext(r.Context())
+			problem.Write(w, p)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsAdmin reports whether the caller's API key is one of adminKeys.
+func IsAdmin(ctx context.Context, adminKeys []string) bool {
+	key := APIKeyFromContext(ctx)
+	return key != "" && containsKey(adminKeys, key)
+}
+
+// containsKey reports whether key is one of keys.
+func containsKey(keys []string, key string) bool {
+	found := false
+	for _, k := range keys {
+		// Compare every key in constant time so timing does not reveal a match.
+		if subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+			found = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	keys := ParseAPIKeys(" root-key , ,ops-key")
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 keys, got %v", keys)
+	}
+	handler := Authenticate(nil, append(keys, "guest"), RequireAdmin(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	cases := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"No key", "", http.StatusUnauthorized},
+		{"Unknown key", "made-up", http.StatusUnauthorized},
+		{"Client key", "guest", http.StatusForbidden},
+		{"Admin key", "ops-key", http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/tags/merge", nil)
+			if tc.key != "" {
+				req.Header.Set(APIKeyHeader, tc.key)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tc.status {
+				t.Errorf("Expected status %d, got %d", tc.status, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"example/api/internal/audit"
+	"log"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Audit middleware appends an entry to auditLog for every successful POST, PUT, PATCH and
+// DELETE request. The entry's snapshots come from the changes recorded by the services while
+// the request was handled. Failed requests and idempotent replays change nothing and are not logged.
+// It must run inside Authenticate and RequestID, whose values it records.
+func Audit(auditLog *audit.Log, trustedProxies []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, rec := audit.WithRecorder(r.Context())
+		r = r.WithContext(ctx)
+		sr := newStatusRecorder(w)
+		next.ServeHTTP(sr, r)
+		if sr.status < 200 || sr.status > 299 || w.Header().Get("Idempotent-Replayed") != "" {
+			return
+		}
+
+		changes := rec.Changes()
+		appendAuditEntry(auditLog, trustedProxies, r, auditAction(r), auditResource(r, auditActor(r), changes), sr.status, changes)
+	})
+}
+
+// CommandAuditor appends audit entries for the mutations made by commands sent over
+// a long-lived connection, such as WebSocket commands. Audit does not see them: the
+// only request is the GET that opened the connection.
+type CommandAuditor struct {
+	log            *audit.Log
+	trustedProxies []netip.Prefix
+}
+
+// NewCommandAuditor creates a CommandAuditor appending to auditLog. X-Forwarded-For is
+// only honored for connections from trustedProxies, as in Audit.
+func NewCommandAuditor(auditLog *audit.Log, trustedProxies []netip.Prefix) *CommandAuditor {
+	return &CommandAuditor{log: auditLog, trustedProxies: trustedProxies}
+}
+
+// Run runs fn, a command sent over the connection opened by r, and appends an entry for it
+// like Audit does for a request: the actor, IP and request ID are r's, and the snapshots
+// come from the changes recorded while fn ran. The entry's action is action, its status
+// 200, and its resource the only recorded change's or else resource. Nothing is logged
+// if fn fails. A nil CommandAuditor only runs fn.
+func (a *CommandAuditor) Run(r *http.Request, action string, resource string, fn func(ctx context.Context) error) error {
+	if a == nil {
+		return fn(r.Context())
+	}
+	ctx, rec := audit.WithRecorder(r.Context())
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	changes := rec.Changes()
+	if len(changes) == 1 {
+		resource = changes[0].Resource
+	}
+	appendAuditEntry(a.log, a.trustedProxies, r, action, resource, http.StatusOK, changes)
+	return nil
+}
+
+// appendAuditEntry appends the entry of a mutation requested by r to auditLog.
+func appendAuditEntry(auditLog *audit.Log, trustedProxies []netip.Prefix, r *http.Request, action string, resource string, status int, changes []audit.Change) {
+	before, after, err := audit.Snapshots(changes)
+	if err != nil {
+		log.Printf("audit: snapshots of %s on %s: %v", action, resource, err)
+	}
+	auditLog.Append(audit.Entry{
+		Actor:     auditActor(r),
+		Action:    action,
+		Resource:  resource,
+		Status:    status,
+		Before:    before,
+		After:     after,
+		IP:        clientIP(r, trustedProxies),
+		RequestID: RequestIDFromContext(r.Context()),
+	})
+}
+
+// auditActor identifies the caller like callerScope, but by a fingerprint of the API key
+// so that the log does not hold credentials.
+func auditActor(r *http.Request) string {
+	if key := APIKeyFromContext(r.Context()); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:6])
+	}
+	return callerScope(r)
+}
+
+// auditAction returns the route pattern that handled r, such as "DELETE /comments/{id}".
+// Requests served by a catch-all route have their numeric path segments replaced by {id}.
+func auditAction(r *http.Request) string {
+	if strings.HasPrefix(r.Pattern, r.Method+" ") {
+		return r.Pattern
+	}
+	segments := strings.Split(r.URL.Path, "/")
+	for i, s := range segments {
+		if _, err := strconv.Atoi(s); err == nil {
+			segments[i] = "{id}"
+		}
+	}
+	return r.Method + " " + strings.Join(segments, "/")
+}
+
+// auditResource returns the resource changed by r: the only recorded change's, or one
+// derived from the path, such as "post:5" for /posts/5/publish, "user:3" for
+// /users/me/lists when the caller is user 3, or "notification" for /notifications/read.
+func auditResource(r *http.Request, actor string, changes []audit.Change) string {
+	if len(changes) == 1 {
+		return changes[0].Resource
+	}
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if segments[0] == "admin" && len(segments) > 1 {
+		segments = segments[1:]
+	}
+	resource := strings.TrimSuffix(segments[0], "s")
+	if len(segments) < 2 {
+		return resource
+	}
+	if _, err := strconv.Atoi(segments[1]); err != nil && len(segments) == 2 {
+		// An action on the collection, such as /notifications/read or /admin/tags/merge.
+		return resource
+	}
+	if segments[1] == "me" && strings.HasPrefix(actor, "user:") {
+		return actor
+	}
+	return resource + ":" + segments[1]
+}
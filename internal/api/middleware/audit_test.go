@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/audit"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	log := audit.NewLog()
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		audit.Record(r.Context(), audit.Change{Resource: "post:5", Before: map[string]string{"title": "Old"}, After: map[string]string{"title": "New"}})
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/posts/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /users/me/lists", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Invalid", http.StatusBadRequest)
+	})
+	handler := RequestID(Authenticate([]netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}, []string{"secret-key"}, Audit(log, nil, mux)))
+
+	send := func(method, path string, header map[string]string) {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set(RequestIDHeader, "req-1")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("successful mutations are recorded", func(t *testing.T) {
+		send(http.MethodPut, "/posts/5", map[string]string{UserIDHeader: "7"})
+		entries := log.Query(audit.Filter{})
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 entry, got %d", len(entries))
+		}
+		e := entries[0]
+		if e.Actor != "user:7" || e.Action != "PUT /posts/{id}" || e.Resource != "post:5" || e.Status != http.StatusOK {
+			t.Errorf("Expected user:7 updating post:5, got %+v", e)
+		}
+		if e.IP != "192.0.2.1" || e.RequestID != "req-1" {
+			t.Errorf("Expected IP 192.0.2.1 and request ID req-1, got %q and %q", e.IP, e.RequestID)
+		}
+		if string(e.Before) != `{"title":"Old"}` || string(e.After) != `{"title":"New"}` {
+			t.Errorf("Expected the snapshots, got %s and %s", e.Before, e.After)
+		}
+	})
+
+	t.Run("resources and actors are derived from the request", func(t *testing.T) {
+		before := len(log.Query(audit.Filter{}))
+		send(http.MethodDelete, "/posts/9", map[string]string{APIKeyHeader: "secret-key"})
+		send(http.MethodPost, "/users/me/lists", map[string]string{UserIDHeader: "3"})
+		entries := log.Query(audit.Filter{After: uint64(before)})
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(entries))
+		}
+		if e := entries[0]; e.Action != "DELETE /posts/{id}" || e.Resource != "post:9" {
+			t.Errorf("Expected DELETE /posts/{id} on post:9, got %q on %q", e.Action, e.Resource)
+		}
+		if actor := entries[0].Actor; !strings.HasPrefix(actor, "key:") || strings.Contains(actor, "secret-key") {
+			t.Errorf("Expected a fingerprint of the API key, got %q", actor)
+		}
+		if e := entries[1]; e.Resource != "user:3" {
+			t.Errorf("Expected /users/me to resolve to user:3, got %q", e.Resource)
+		}
+	})
+
+	t.Run("commands are recorded as made by the connection's caller", func(t *testing.T) {
+		auditor := NewCommandAuditor(log, nil)
+		var r *http.Request
+		opened := RequestID(Authenticate([]netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}, nil, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r = req
+		})))
+		req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set(UserIDHeader, "4")
+		req.Header.Set(RequestIDHeader, "req-ws")
+		opened.ServeHTTP(httptest.NewRecorder(), req)
+
+		before := len(log.Query(audit.Filter{}))
+		err := auditor.Run(r, "WS post.create", "post", func(ctx context.Context) error {
+			audit.Record(ctx, audit.Change{Resource: "post:8", After: map[string]string{"title": "New"}})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		auditor.Run(r, "WS post.delete", "post:8", func(ctx context.Context) error {
+			return errors.New("only the author can delete a post")
+		})
+		entries := log.Query(audit.Filter{After: uint64(before)})
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 entry, got %d", len(entries))
+		}
+		e := entries[0]
+		if e.Actor != "user:4" || e.Action != "WS post.create" || e.Resource != "post:8" || e.Status != http.StatusOK {
+			t.Errorf("Expected user:4 creating post:8, got %+v", e)
+		}
+		if e.IP != "192.0.2.1" || e.RequestID != "req-ws" || string(e.After) != `{"title":"New"}` {
+			t.Errorf("Expected the IP, request ID and snapshot of the connection, got %+v", e)
+		}
+	})
+
+	t.Run("reads and failures are not recorded", func(t *testing.T) {
+		before := len(log.Query(audit.Filter{}))
+		send(http.MethodGet, "/posts/5", nil)
+		send(http.MethodPost, "/fail", nil)
+		if n := len(log.Query(audit.Filter{})) - before; n != 0 {
+			t.Errorf("Expected no entries, got %d", n)
+		}
+	})
+}
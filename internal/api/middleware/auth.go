@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"example/api/internal/api/problem"
+	"net/http"
+	"net/netip"
+	"strconv"
+)
+
+// Headers carrying the caller's identity. X-User-ID is set by the authenticating gateway
+// in front of the API; X-API-Key is sent by client applications.
+const (
+	UserIDHeader = "X-User-ID"
+	APIKeyHeader = "X-API-Key"
+)
+
+type userIDKey struct{}
+type apiKeyKey struct{}
+type clientIPKey struct{}
+
+// Authenticate middleware stores the caller's user ID, API key and IP address in the request context.
+// X-User-ID is only honored on requests coming directly from one of trustedProxies, the
+// gateway that authenticates users; from anyone else it is ignored, since clients could
+// claim any identity. X-API-Key must be one of apiKeys.
+// Requests without identity headers continue anonymously; a malformed user ID or an
+// unknown API key is rejected.
+func Authenticate(trustedProxies []netip.Prefix, apiKeys []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), clientIPKey{}, clientIP(r, trustedProxies))
+		if v := r.Header.Get(UserIDHeader); v != "" && fromTrustedProxy(r, trustedProxies) {
+			id, err := strconv.Atoi(v)
+			if err != nil || id <= 0 {
+				problem.Write(w, problem.New(http.StatusUnauthorized, "Invalid "+UserIDHeader+" header"))
+				return
+			}
+			ctx = WithUserID(ctx, id)
+		}
+		if key := r.Header.Get(APIKeyHeader); key != "" {
+			if !containsKey(apiKeys, key) {
+				problem.Write(w, problem.New(http.StatusUnauthorized, "Invalid "+APIKeyHeader+" header"))
+				return
+			}
+			ctx = context.WithValue(ctx, apiKeyKey{}, key)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// fromTrustedProxy reports whether the remote address of r is one of trustedProxies.
+func fromTrustedProxy(r *http.Request, trustedProxies []netip.Prefix) bool {
+	remote, err := remoteAddr(r.RemoteAddr)
+	return err == nil && trusted(trustedProxies, remote)
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID.
+func WithUserID(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// UserIDFromContext returns the authenticated user ID, if any.
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(userIDKey{}).(int)
+	return id, ok
+}
+
+// APIKeyFromContext returns the caller's API key, which Authenticate has checked,
+// or "" if none was sent.
+func APIKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(apiKeyKey{}).(string)
+	return key
+}
+
+const anonymousScope = "anonymous"
+
+// callerScope identifies the caller by API key, authenticated user or, for anonymous
+// callers, the client IP recorded by Authenticate. Anonymous requests that did not go
+// through Authenticate share a single scope.
+func callerScope(r *http.Request) string {
+	if key := APIKeyFromContext(r.Context()); key != "" {
+		return "key:" + key
+	}
+	if id, ok := UserIDFromContext(r.Context()); ok {
+		return "user:" + strconv.Itoa(id)
+	}
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return "ip:" + ip
+	}
+	return anonymousScope
+}
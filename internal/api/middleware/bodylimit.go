@@ -0,0 +1,21 @@
+package middleware
+
+import "net/http"
+
+// MaxBodySize middleware caps every request body at maxBytes, so no handler or
+// middleware can be made to read an unbounded body. Reads past the limit fail
+// with *http.MaxBytesError.
+func MaxBodySize(maxBytes int64, next http.Handler) http.Handler {
+	return MaxBodySizeFunc(func(*http.Request) int64 { return maxBytes }, next)
+}
+
+// MaxBodySizeFunc is like MaxBodySize but asks limit for the cap of each request,
+// so upload endpoints can accept larger bodies than the rest of the API.
+func MaxBodySizeFunc(limit func(r *http.Request) int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = http.MaxBytesReader(w, r.Body, limit(r))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
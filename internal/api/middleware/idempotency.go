@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"example/api/internal/api/problem"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client's idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	done        bool
+	status      int
+	header      http.Header
+	body        []byte
+	expires     time.Time
+}
+
+// Idempotency replays the stored response when a request is retried with the same
+// Idempotency-Key header. Keys are scoped to the route and the caller, and expire after ttl.
+// Anonymous callers are scoped by client IP, so that they cannot replay each other's responses;
+// an anonymous retry from another address is executed again.
+// A retry whose body differs from the original is rejected with 422, and a retry that
+// arrives while the original is still being processed is rejected with 409.
+type Idempotency struct {
+	mu         sync.Mutex
+	entries    map[string]*idempotencyEntry
+	ttl        time.Duration
+	sweepEvery time.Duration
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+// NewIdempotency creates an Idempotency store whose keys expire after ttl.
+func NewIdempotency(ttl time.Duration) *Idempotency {
+	return &Idempotency{
+		entries:    make(map[string]*idempotencyEntry),
+		ttl:        ttl,
+		sweepEvery: time.Minute,
+		now:        time.Now,
+	}
+}
+
+// Handle wraps next so that requests carrying an Idempotency-Key are executed at most once.
+// Requests without the header are passed through unchanged.
+func (s *Idempotency) Handle(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > 255 {
+			s.reject(w, r, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters.")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				s.reject(w, r, http.StatusRequestEntityTooLarge, "Request body is too large.")
+				return
+			}
+			s.reject(w, r, http.StatusBadRequest, "Could not read request body.")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := sha256.Sum256(append([]byte(r.Method+" "+r.URL.RequestURI()+"\n"), body...))
+		scoped := route + "|" + callerScope(r) + "|" + key
+
+		s.mu.Lock()
+		now := s.now()
+		s.sweep(now)
+		entry, ok := s.entries[scoped]
+		if ok && entry.done && now.After(entry.expires) {
+			ok = false
+		}
+		if ok {
+			// The original request fills in the entry under s.mu when it completes,
+			// so the entry is copied before unlocking.
+			stored := *entry
+			s.mu.Unlock()
+			switch {
+			case stored.fingerprint != fingerprint:
+				s.reject(w, r, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request body.")
+			case !stored.done:
+				s.reject(w, r, http.StatusConflict, "A request with this Idempotency-Key is still being processed.")
+			default:
+				replay(w, stored)
+			}
+			return
+		}
+		entry = &idempotencyEntry{fingerprint: fingerprint, expires: now.Add(s.ttl)}
+		s.entries[scoped] = entry
+		s.mu.Unlock()
+
+		rec := &capturingWriter{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			// Server errors and panics are not cached so the client can retry.
+			if !completed || rec.status >= http.StatusInternalServerError {
+				delete(s.entries, scoped)
+				return
+			}
+			entry.done = true
+			entry.status = rec.status
+			entry.header = w.Header().Clone()
+			entry.body = rec.body.Bytes()
+		}()
+		next.ServeHTTP(rec, r)
+		completed = true
+	})
+}
+
+func (s *Idempotency) reject(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	p := problem.New(status, detail)
+	p.Instance = r.URL.Path
+	p.RequestID = RequestIDFromContext(r.Context())
+	problem.Write(w, p)
+}
+
+// sweep periodically drops expired keys. Callers must hold s.mu.
+func (s *Idempotency) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepEvery {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if e.done && now.After(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// replay writes a stored response, marking it as a replay. The request ID and rate limit
+// headers of the original request are not replayed, as they describe that request.
+func replay(w http.ResponseWriter, e idempotencyEntry) {
+	for k, v := range e.header {
+		if k == RequestIDHeader || strings.HasPrefix(k, "Ratelimit-") {
+			continue
+		}
+		w.Header()[k] = slices.Clone(v)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.Header().Set("Content-Length", strconv.Itoa(len(e.body)))
+	w.WriteHeader(e.status)
+	w.Write(e.body)
+}
+
+// capturingWriter copies the response body while writing it through.
+type capturingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (c *capturingWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status = status
+		c.wroteHeader = true
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *capturingWriter) Write(b []byte) (int, error) {
+	c.wroteHeader = true
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *capturingWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
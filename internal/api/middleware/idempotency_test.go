@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdempotency(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewIdempotency(time.Hour)
+	store.now = func() time.Time { return now }
+
+	calls := 0
+	create := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":%d}`, calls)
+	})
+	// httptest requests come from 192.0.2.1, which plays the gateway setting X-User-ID.
+	handler := Authenticate([]netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}, nil, store.Handle("posts.create", create))
+
+	sendFrom := func(remote string, key string, body string, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+		req.RemoteAddr = remote
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if user != "" {
+			req.Header.Set(UserIDHeader, user)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	send := func(key string, body string, user string) *httptest.ResponseRecorder {
+		return sendFrom("192.0.2.1:1234", key, body, user)
+	}
+
+	t.Run("First request is executed", func(t *testing.T) {
+		rr := send("abc", `{"title":"a"}`, "1")
+		if rr.Code != http.StatusCreated || rr.Body.String() != `{"id":1}` {
+			t.Errorf("Expected 201 with id 1, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("Retry replays stored response", func(t *testing.T) {
+		rr := send("abc", `{"title":"a"}`, "1")
+		if rr.Code != http.StatusCreated || rr.Body.String() != `{"id":1}` {
+			t.Errorf("Expected replayed 201 with id 1, got %d %s", rr.Code, rr.Body.String())
+		}
+		if rr.Header().Get("Idempotent-Replayed") != "true" {
+			t.Error("Expected Idempotent-Replayed header")
+		}
+		if calls != 1 {
+			t.Errorf("Expected handler to run once, ran %d times", calls)
+		}
+	})
+
+	t.Run("Different body under same key returns 422", func(t *testing.T) {
+		rr := send("abc", `{"title":"b"}`, "1")
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Keys are scoped per caller", func(t *testing.T) {
+		rr := send("abc", `{"title":"a"}`, "2")
+		if rr.Code != http.StatusCreated || calls != 2 {
+			t.Errorf("Expected a new execution for another user, got %d after %d calls", rr.Code, calls)
+		}
+	})
+
+	t.Run("Anonymous keys are scoped per client IP", func(t *testing.T) {
+		before := calls
+		if rr := sendFrom("203.0.113.1:1234", "anon", `{"title":"a"}`, ""); rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", rr.Code)
+		}
+		rr := sendFrom("203.0.113.2:1234", "anon", `{"title":"a"}`, "")
+		if rr.Code != http.StatusCreated || rr.Header().Get("Idempotent-Replayed") != "" || calls != before+2 {
+			t.Errorf("Expected a new execution for another client, got %d after %d calls", rr.Code, calls-before)
+		}
+		rr = sendFrom("203.0.113.1:5678", "anon", `{"title":"a"}`, "")
+		if rr.Header().Get("Idempotent-Replayed") != "true" || calls != before+2 {
+			t.Errorf("Expected a replay for the same client, got %d after %d calls", rr.Code, calls-before)
+		}
+	})
+
+	t.Run("Requests without key are not deduplicated", func(t *testing.T) {
+		before := calls
+		send("", `{"title":"a"}`, "1")
+		send("", `{"title":"a"}`, "1")
+		if calls != before+2 {
+			t.Errorf("Expected 2 calls, got %d", calls-before)
+		}
+	})
+
+	t.Run("Keys expire after the window", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		rr := send("abc", `{"title":"b"}`, "1")
+		if rr.Code != http.StatusCreated || rr.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("Expected fresh execution after expiry, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Server errors are not stored", func(t *testing.T) {
+		failing := true
+		h := store.Handle("users.register", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failing {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		req := func() int {
+			r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`))
+			r.Header.Set(IdempotencyKeyHeader, "retry-me")
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, r)
+			return rr.Code
+		}
+		if code := req(); code != http.StatusInternalServerError {
+			t.Fatalf("Expected status 500, got %d", code)
+		}
+		failing = false
+		if code := req(); code != http.StatusCreated {
+			t.Errorf("Expected retry to run again, got %d", code)
+		}
+	})
+
+	t.Run("Oversized body is rejected", func(t *testing.T) {
+		h := MaxBodySize(8, store.Handle("posts.create", create))
+		req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(`{"title":"too long"}`))
+		req.Header.Set(IdempotencyKeyHeader, "big")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status 413, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Concurrent retry while in flight returns 409", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		h := store.Handle("slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusCreated)
+		}))
+		newReq := func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(`{}`))
+			r.Header.Set(IdempotencyKeyHeader, "slow-key")
+			return r
+		}
+		done := make(chan struct{})
+		go func() {
+			h.ServeHTTP(httptest.NewRecorder(), newReq())
+			close(done)
+		}()
+		<-started
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, newReq())
+		close(release)
+		<-done
+		if rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Retries racing the original's completion", func(t *testing.T) {
+		h := store.Handle("racing", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+		newReq := func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(`{}`))
+			r.Header.Set(IdempotencyKeyHeader, "racing-key")
+			return r
+		}
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rr := httptest.NewRecorder()
+				h.ServeHTTP(rr, newReq())
+				if rr.Code != http.StatusCreated && rr.Code != http.StatusConflict {
+					t.Errorf("Expected status 201 or 409, got %d", rr.Code)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("Rate limit headers are not replayed", func(t *testing.T) {
+		remaining := 5
+		h := store.Handle("limited", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+		// Stands in for the rate limiter, which sets its headers before idempotency runs.
+		limited := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			remaining--
+			h.ServeHTTP(w, r)
+		})
+		send := func() *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(`{}`))
+			r.Header.Set(IdempotencyKeyHeader, "limited-key")
+			rr := httptest.NewRecorder()
+			limited.ServeHTTP(rr, r)
+			return rr
+		}
+		send()
+		rr := send()
+		if rr.Header().Get("Idempotent-Replayed") != "true" {
+			t.Fatal("Expected a replayed response")
+		}
+		if got := rr.Header().Get("RateLimit-Remaining"); got != "4" {
+			t.Errorf("Expected the retry's RateLimit-Remaining 4, got %s", got)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"errors"
+	"example/api/internal/api/problem"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit describes a token bucket: Burst tokens at most, refilled at Rate tokens per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerPeriod returns a limit allowing n requests per period, all of which may be used at once.
+func PerPeriod(n int, period time.Duration) RateLimit {
+	return RateLimit{Rate: float64(n) / period.Seconds(), Burst: n}
+}
+
+// ParseRateLimit parses limits written as "<requests>/<period>", for example "5/1m" or "100/1s".
+func ParseRateLimit(s string) (RateLimit, error) {
+	n, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("rate limit %q: expected <requests>/<period>", s)
+	}
+	count, err := strconv.Atoi(n)
+	if err != nil || count <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q: invalid request count", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q: invalid period", s)
+	}
+	return PerPeriod(count, d), nil
+}
+
+// window is the time an empty bucket takes to refill completely.
+func (l RateLimit) window() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter limits requests per client with token buckets kept in memory.
+// Clients are identified by API key, then authenticated user, then client IP. Keys and
+// users are only those Authenticate accepted, so clients cannot get fresh buckets by
+// sending made-up identities.
+// Buckets that have been idle long enough to be full again are evicted periodically.
+type RateLimiter struct {
+	mu             sync.Mutex
+	buckets        map[string]*bucket
+	trustedProxies []netip.Prefix
+	sweepEvery     time.Duration
+	lastSweep      time.Time
+	maxIdle        time.Duration
+	now            func() time.Time
+}
+
+// NewRateLimiter creates a RateLimiter. X-Forwarded-For is only honored for requests
+// whose remote address falls within one of trustedProxies.
+func NewRateLimiter(trustedProxies []netip.Prefix) *RateLimiter {
+	return &RateLimiter{
+		buckets:        make(map[string]*bucket),
+		trustedProxies: trustedProxies,
+		sweepEvery:     time.Minute,
+		now:            time.Now,
+	}
+}
+
+// ParseTrustedProxies parses a comma-separated list of IP addresses and CIDR prefixes.
+func ParseTrustedProxies(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.Contains(part, "/") {
+			p, err := netip.ParsePrefix(part)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(part)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Limit wraps next with the given limit. The route name separates the buckets
+// of different routes so each can have its own limit.
+func (l *RateLimiter) Limit(route string, limit RateLimit, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := route + "|" + l.clientKey(r)
+		allowed, remaining, retryAfter := l.take(key, limit)
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(retryAfter)))
+		w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, ceilSeconds(limit.window())))
+
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+			p := problem.New(http.StatusTooManyRequests, "Rate limit exceeded, retry later.")
+			p.Instance = r.URL.Path
+			p.RequestID = RequestIDFromContext(r.Context())
+			problem.Write(w, p)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Allow takes a token from the bucket of r's caller for the route, like Limit does for a
+// request, for an action that is not a request of its own, such as a command sent over the
+// WebSocket connection r opened. It reports whether the action is allowed and, if not,
+// how long until it is.
+func (l *RateLimiter) Allow(r *http.Request, route string, limit RateLimit) (bool, time.Duration) {
+	allowed, _, retryAfter := l.take(route+"|"+l.clientKey(r), limit)
+	return allowed, retryAfter
+}
+
+// take removes a token from the bucket for key. It reports whether the request
+// is allowed, the whole tokens left, and how long until the next token is available.
+func (l *RateLimiter) take(key string, limit RateLimit) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.maxIdle = max(l.maxIdle, limit.window())
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return false, 0, wait
+	}
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	return true, int(b.tokens), wait
+}
+
+// sweep evicts buckets idle for longer than the largest refill window, since
+// they would be recreated full anyway. Callers must hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.sweepEvery {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > l.maxIdle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// clientKey identifies the caller by API key, authenticated user or client IP, in that order.
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if scope := callerScope(r); scope != anonymousScope {
+		return scope
+	}
+	return "ip:" + l.clientIP(r)
+}
+
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	return clientIP(r, l.trustedProxies)
+}
+
+// clientIP returns the remote address, or when it is a trusted proxy, the
+// right-most X-Forwarded-For entry that is not itself a trusted proxy.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote, err := remoteAddr(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	if !trusted(trustedProxies, remote) {
+		return remote.String()
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !trusted(trustedProxies, client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func trusted(trustedProxies []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteAddr(hostport string) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, errors.New("invalid remote address")
+	}
+	return addr.Unmap(), nil
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	limiter := NewRateLimiter(proxies)
+	limiter.now = func() time.Time { return now }
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := limiter.Limit("register", PerPeriod(2, time.Minute), ok)
+
+	send := func(remote string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", nil)
+		req.RemoteAddr = remote
+		for k, vs := range header {
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+		rr := httptest.NewRecorder()
+		Authenticate(proxies, []string{"partner"}, handler).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Requests within burst are allowed", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			rr := send("203.0.113.5:1234", nil)
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d", rr.Code)
+			}
+		}
+	})
+
+	t.Run("Exhausted bucket returns 429", func(t *testing.T) {
+		rr := send("203.0.113.5:1234", nil)
+		if rr.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected status 429, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Retry-After"); got != "30" {
+			t.Errorf("Expected Retry-After 30, got %s", got)
+		}
+		if got := rr.Header().Get("RateLimit-Remaining"); got != "0" {
+			t.Errorf("Expected RateLimit-Remaining 0, got %s", got)
+		}
+		if got := rr.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("Expected RateLimit-Limit 2, got %s", got)
+		}
+	})
+
+	t.Run("Other clients have their own bucket", func(t *testing.T) {
+		if rr := send("203.0.113.6:1234", nil); rr.Code != http.StatusCreated {
+			t.Errorf("Expected status 201 for another IP, got %d", rr.Code)
+		}
+		if rr := send("10.0.0.1:1234", http.Header{UserIDHeader: {"7"}, "X-Forwarded-For": {"203.0.113.5"}}); rr.Code != http.StatusCreated {
+			t.Errorf("Expected status 201 for authenticated user, got %d", rr.Code)
+		}
+		if rr := send("203.0.113.5:1234", http.Header{APIKeyHeader: {"partner"}}); rr.Code != http.StatusCreated {
+			t.Errorf("Expected status 201 for API key, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Identities that were not verified share the IP's bucket", func(t *testing.T) {
+		// 203.0.113.6 has one token left.
+		for i, id := range []string{"8", "9"} {
+			rr := send("203.0.113.6:1234", http.Header{UserIDHeader: {id}})
+			if expected := []int{http.StatusCreated, http.StatusTooManyRequests}[i]; rr.Code != expected {
+				t.Errorf("Expected status %d for user ID %s sent directly, got %d", expected, id, rr.Code)
+			}
+		}
+		if rr := send("203.0.113.6:1234", http.Header{APIKeyHeader: {"made-up"}}); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for an unknown API key, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Tokens refill over time", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		if rr := send("203.0.113.5:1234", nil); rr.Code != http.StatusCreated {
+			t.Errorf("Expected status 201 after refill, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Forwarded client behind trusted proxy", func(t *testing.T) {
+		xff := http.Header{"X-Forwarded-For": {"198.51.100.9, 10.1.2.3"}}
+		for i := 0; i < 2; i++ {
+			if rr := send("192.168.1.1:80", xff); rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d", rr.Code)
+			}
+		}
+		if rr := send("10.9.9.9:80", http.Header{"X-Forwarded-For": {"198.51.100.9"}}); rr.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected the forwarded client to share a bucket, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Forwarded header from untrusted peer is ignored", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/users", nil)
+		req.RemoteAddr = "203.0.113.77:5555"
+		req.Header.Set("X-Forwarded-For", "198.51.100.9")
+		if ip := limiter.clientIP(req); ip != "203.0.113.77" {
+			t.Errorf("Expected remote address, got %s", ip)
+		}
+	})
+
+	t.Run("Stale buckets are evicted", func(t *testing.T) {
+		now = now.Add(10 * time.Minute)
+		send("203.0.113.200:1", nil)
+		limiter.mu.Lock()
+		n := len(limiter.buckets)
+		limiter.mu.Unlock()
+		if n != 1 {
+			t.Errorf("Expected 1 bucket after eviction, got %d", n)
+		}
+	})
+}
+
+func TestParseRateLimit(t *testing.T) {
+	limit, err := ParseRateLimit("5/1m")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if limit.Burst != 5 || limit.window() != time.Minute {
+		t.Errorf("Expected 5 per minute, got %+v", limit)
+	}
+	for _, bad := range []string{"5", "x/1m", "5/soon", "0/1s"} {
+		if _, err := ParseRateLimit(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+	if _, err := ParseTrustedProxies("not-an-ip"); err == nil {
+		t.Error("Expected error for invalid proxy")
+	}
+	if p, _ := ParseTrustedProxies("::1"); len(p) != 1 || !p[0].Contains(netip.MustParseAddr("::1")) {
+		t.Errorf("Expected single-address prefix, got %v", p)
+	}
+}
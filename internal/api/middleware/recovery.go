@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"example/api/internal/api/problem"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// panicsRecovered counts handler panics converted into 500 responses.
+// It is published on /debug/vars.
+var panicsRecovered = expvar.NewInt("http_panics_recovered_total")
+
+// Recover middleware converts a panic in the next handler into a 500
+// problem+json response and logs the stack trace with the request ID.
+// http.ErrAbortHandler is re-raised so the server can abort the connection as intended.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := newStatusRecorder(w)
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			requestID := RequestIDFromContext(r.Context())
+			panicsRecovered.Add(1)
+			log.Printf("panic serving %s %s (request_id=%s): %v\n%s", r.Method, r.URL.Path, requestID, v, debug.Stack())
+
+			span := trace.SpanFromContext(r.Context())
+			span.RecordError(fmt.Errorf("panic: %v", v))
+			span.SetStatus(codes.Error, "panic")
+
+			// Nothing sensible can be sent once the handler started the response.
+			if rec.wroteHeader {
+				return
+			}
+			p := problem.New(http.StatusInternalServerError, "The server encountered an unexpected error.")
+			p.Instance = r.URL.Path
+			p.RequestID = requestID
+			problem.Write(rec, p)
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
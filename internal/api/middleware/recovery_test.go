@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"example/api/internal/api/problem"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	// Capture log output
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ids []string
+		_ = ids[3] // index out of range
+	})
+	handler := RequestID(Recover(panicking))
+
+	t.Run("Panic returns problem response", func(t *testing.T) {
+		before := panicsRecovered.Value()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1/posts", nil)
+		req.Header.Set(RequestIDHeader, "req-123")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", rr.Code)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != problem.ContentType {
+			t.Errorf("Expected content type %s, got %s", problem.ContentType, ct)
+		}
+		var p problem.Details
+		if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+			t.Fatalf("Expected problem body, got error %v", err)
+		}
+		if p.Status != http.StatusInternalServerError || p.RequestID != "req-123" || p.Instance != "/users/1/posts" {
+			t.Errorf("Unexpected problem %+v", p)
+		}
+		if panicsRecovered.Value() != before+1 {
+			t.Errorf("Expected panic counter %d, got %d", before+1, panicsRecovered.Value())
+		}
+	})
+
+	t.Run("Panic is logged with request ID and stack", func(t *testing.T) {
+		out := logs.String()
+		if !strings.Contains(out, "request_id=req-123") {
+			t.Errorf("Expected request ID in log, got %q", out)
+		}
+		if !strings.Contains(out, "index out of range") || !strings.Contains(out, "goroutine") {
+			t.Errorf("Expected panic value and stack in log, got %q", out)
+		}
+	})
+
+	t.Run("Panic after response started", func(t *testing.T) {
+		h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("late failure")
+		}))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/posts", nil))
+
+		if rr.Code != http.StatusAccepted {
+			t.Errorf("Expected original status 202, got %d", rr.Code)
+		}
+		if rr.Body.Len() != 0 {
+			t.Errorf("Expected no problem body, got %q", rr.Body.String())
+		}
+	})
+
+	t.Run("Abort handler panic is re-raised", func(t *testing.T) {
+		h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+		defer func() {
+			if v := recover(); v != http.ErrAbortHandler {
+				t.Errorf("Expected ErrAbortHandler, got %v", v)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts", nil))
+	})
+
+	t.Run("No panic passes through", func(t *testing.T) {
+		h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/posts/1", nil))
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing middleware starts a server span for every request.
+// An incoming W3C traceparent header makes the span a child of the caller's trace,
+// and the span context is stored in the request context for handlers and services.
+// The span is named after the route reported by Route, which must wrap the mux.
+func Tracing(next http.Handler) http.Handler {
+	tracer := otel.Tracer("example/api/internal/api/middleware")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("user_agent.original", r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		rec := newStatusRecorder(w)
+		route := &matchedRoute{}
+		ctx = context.WithValue(ctx, routeKey{}, route)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		// The matched pattern keeps span names low-cardinality. Patterns such as
+		// "GET /posts/{id}" start with a method, which is not part of the route.
+		if route.pattern != "" {
+			_, path, found := strings.Cut(route.pattern, " ")
+			if !found {
+				path = route.pattern
+			}
+			span.SetName(r.Method + " " + path)
+			span.SetAttributes(attribute.String("http.route", path))
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", rec.status))
+		}
+	})
+}
+
+type routeKey struct{}
+
+// matchedRoute carries the pattern the mux matched back out to Tracing.
+type matchedRoute struct {
+	pattern string
+}
+
+// Route middleware reports the pattern the mux matched a request with to Tracing. The mux
+// sets the pattern on the request it is given, which the middleware in between replace
+// with copies carrying their context values, so Route must wrap the mux directly.
+func Route(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if route, ok := r.Context().Value(routeKey{}).(*matchedRoute); ok {
+				route.pattern = r.Pattern
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /posts/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	// The middleware in between pass copies of the request on, as in the server.
+	handler := Tracing(RequestID(Authenticate(nil, nil, Route(mux))))
+
+	// serve returns the span of a request to path and its attributes.
+	serve := func(path string) (sdktrace.ReadOnlySpan, attribute.Set) {
+		t.Helper()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		spans := recorder.Ended()
+		if len(spans) == 0 {
+			t.Fatal("Expected a span")
+		}
+		span := spans[len(spans)-1]
+		return span, attribute.NewSet(span.Attributes()...)
+	}
+
+	t.Run("Spans are named after the matched route", func(t *testing.T) {
+		span, attrs := serve("/posts/5")
+		if span.Name() != "GET /posts/{id}" {
+			t.Errorf("Expected span GET /posts/{id}, got %q", span.Name())
+		}
+		if route, _ := attrs.Value("http.route"); route.AsString() != "/posts/{id}" {
+			t.Errorf("Expected http.route /posts/{id}, got %q", route.AsString())
+		}
+	})
+
+	t.Run("Unmatched requests keep the method as name", func(t *testing.T) {
+		span, attrs := serve("/missing")
+		if span.Name() != "GET" {
+			t.Errorf("Expected span GET, got %q", span.Name())
+		}
+		if _, ok := attrs.Value("http.route"); ok {
+			t.Error("Expected no http.route")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+// Package problem writes RFC 9457 problem details responses.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType is the media type of a problem details document.
+const ContentType = "application/problem+json"
+
+// Details is an RFC 9457 problem details document.
+type Details struct {
+	// Type is a URI identifying the problem type
+	Type string `json:"type"`
+	// Title is a short summary of the problem type
+	Title string `json:"title"`
+	// Status is the HTTP status code of the response
+	Status int `json:"status"`
+	// Detail explains this particular occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Instance identifies the request that caused the problem
+	Instance string `json:"instance,omitempty"`
+	// RequestID correlates the response with server logs
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// New returns a problem of the default "about:blank" type for the given status.
+func New(status int, detail string) Details {
+	return Details{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Write sends the problem as the response with its status code.
+func Write(w http.ResponseWriter, p Details) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,258 @@
+// Package audit keeps an append-only record of the changes made through the API:
+// who made each change, to what, from where, and what the resource looked like before
+// and after it.
+//
+// Entries are chained: each one stores the hash of the previous entry and a hash over
+// its own content and that previous hash. Changing, inserting or removing an entry breaks
+// every hash after it, which Verify detects.
+//
+// The changes of a request are gathered with a Recorder carried in its context: services
+// call Record as they change resources, and the HTTP middleware writes them to the Log
+// once the request succeeds.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Query page sizes.
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// ErrTampered is returned by Verify when the hash chain is broken.
+var ErrTampered = errors.New("audit log has been tampered with")
+
+// Entry is one change recorded in the log.
+type Entry struct {
+	// Seq numbers the entries from 1 in the order they were appended
+	Seq uint64 `json:"seq"`
+	// Time is when the entry was appended
+	Time time.Time `json:"time"`
+	// Actor identifies who made the change: "user:{id}", "key:{fingerprint}" for API keys, or "ip:{address}" for anonymous callers
+	Actor string `json:"actor"`
+	// Action is the request that made the change, with IDs replaced by {id}, such as "DELETE /users/{id}",
+	// or the WebSocket command, such as "WS post.create"
+	Action string `json:"action"`
+	// Resource is the resource changed, such as "user:42"
+	Resource string `json:"resource"`
+	// Status is the HTTP status of the response, 200 for WebSocket commands
+	Status int `json:"status"`
+	// Before is the resource before the change, absent for creations. Requests that changed
+	// several resources have an array with one element per resource.
+	Before json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	// After is the resource after the change, absent for deletions, shaped like Before
+	After json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	// IP is the client's address
+	IP string `json:"ip"`
+	// RequestID is the X-Request-ID of the request
+	RequestID string `json:"request_id"`
+	// PrevHash is the Hash of the previous entry, empty for the first one
+	PrevHash string `json:"prev_hash"`
+	// Hash is the hex SHA-256 of PrevHash and the entry's other fields
+	Hash string `json:"hash"`
+}
+
+// Filter selects log entries. The zero Filter matches every entry.
+type Filter struct {
+	// Actor keeps the entries of this actor, if not empty
+	Actor string
+	// Resource keeps the entries of this resource, such as "post:5", or of every
+	// resource of a type, such as "post", if not empty
+	Resource string
+	// Since keeps the entries appended at or after this time, if not zero
+	Since time.Time
+	// After keeps the entries with a greater Seq, to page through results
+	After uint64
+	// Limit is the maximum number of entries returned; it defaults to DefaultLimit and is capped at MaxLimit
+	Limit int
+}
+
+// Match reports whether e passes the filter, ignoring Limit.
+func (f Filter) Match(e Entry) bool {
+	if f.Actor != "" && e.Actor != f.Actor {
+		return false
+	}
+	if f.Resource != "" && e.Resource != f.Resource && !strings.HasPrefix(e.Resource, f.Resource+":") {
+		return false
+	}
+	return !e.Time.Before(f.Since) && e.Seq > f.After
+}
+
+// Log is an in-memory, append-only audit log. It is safe for concurrent use.
+type Log struct {
+	mu      sync.RWMutex
+	entries []Entry
+	now     func() time.Time
+}
+
+// NewLog creates an empty Log.
+func NewLog() *Log {
+	return &Log{now: time.Now}
+}
+
+// Append adds e to the log, assigning its Seq, Time and hashes, and returns the stored entry.
+func (l *Log) Append(e Entry) Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = uint64(len(l.entries)) + 1
+	e.Time = l.now()
+	e.PrevHash = ""
+	if n := len(l.entries); n > 0 {
+		e.PrevHash = l.entries[n-1].Hash
+	}
+	e.Hash = Hash(e)
+	l.entries = append(l.entries, e)
+	return e
+}
+
+// Query returns the entries matching f, oldest first.
+func (l *Log) Query(f Filter) []Entry {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	matched := make([]Entry, 0)
+	for _, e := range l.entries[min(int(f.After), len(l.entries)):] {
+		if f.Match(e) {
+			matched = append(matched, e)
+			if len(matched) == limit {
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// Verify checks the hash chain of the whole log. It returns the number of entries
+// checked, and an error wrapping ErrTampered if any entry does not match its hash.
+func (l *Log) Verify() (int, error) {
+	l.mu.RLock()
+	entries := slices.Clone(l.entries)
+	l.mu.RUnlock()
+	return len(entries), VerifyChain(entries)
+}
+
+// VerifyChain checks that entries form an unbroken hash chain from the start of a log,
+// such as a complete export of GET /admin/audit. Returns an error wrapping ErrTampered
+// that names the first entry that does not match.
+func VerifyChain(entries []Entry) error {
+	prev := ""
+	for i, e := range entries {
+		if e.Seq != uint64(i)+1 {
+			return fmt.Errorf("%w: expected entry %d, found %d", ErrTampered, i+1, e.Seq)
+		}
+		if e.PrevHash != prev || e.Hash != Hash(e) {
+			return fmt.Errorf("%w: entry %d does not match its hash", ErrTampered, e.Seq)
+		}
+		prev = e.Hash
+	}
+	return nil
+}
+
+// Hash returns the hex SHA-256 over e's fields other than Hash, including PrevHash.
+func Hash(e Entry) string {
+	e.Hash = ""
+	// Marshalling a struct is deterministic, and raw snapshots are compacted, so the
+	// same entry always hashes the same.
+	b, _ := json.Marshal(e)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Change is one resource changed during a request.
+type Change struct {
+	// Resource identifies the resource, such as "post:5"
+	Resource string
+	// Before is the resource before the change, nil for creations
+	Before any
+	// After is the resource after the change, nil for deletions
+	After any
+}
+
+// Recorder gathers the changes made while handling one request.
+type Recorder struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a copy of ctx carrying a new Recorder, and the Recorder.
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// WithoutRecorder returns a copy of ctx in which changes are not recorded. Bulk operations
+// use it for their individual changes and record a summary with the original context, so
+// that their entry does not hold a snapshot of every resource they touched.
+func WithoutRecorder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recorderKey{}, (*Recorder)(nil))
+}
+
+// Record adds a change to the Recorder of ctx. It does nothing if ctx has no Recorder,
+// such as for changes made by background jobs.
+func Record(ctx context.Context, c Change) {
+	r, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok || r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, c)
+}
+
+// Changes returns the recorded changes, in the order they were made.
+func (r *Recorder) Changes() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.changes)
+}
+
+// Snapshots returns the JSON of the Before and After of changes: the values themselves for
+// a single change, or arrays of the non-nil values for several. Absent snapshots are nil.
+func Snapshots(changes []Change) (before json.RawMessage, after json.RawMessage, err error) {
+	var befores, afters []any
+	for _, c := range changes {
+		if c.Before != nil {
+			befores = append(befores, c.Before)
+		}
+		if c.After != nil {
+			afters = append(afters, c.After)
+		}
+	}
+	if before, err = snapshot(befores, len(changes) > 1); err != nil {
+		return nil, nil, err
+	}
+	if after, err = snapshot(afters, len(changes) > 1); err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+func snapshot(values []any, several bool) (json.RawMessage, error) {
+	switch {
+	case len(values) == 0:
+		return nil, nil
+	case several:
+		return json.Marshal(values)
+	default:
+		return json.Marshal(values[0])
+	}
+}
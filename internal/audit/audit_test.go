@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLog(t *testing.T) {
+	l := NewLog()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	first := l.Append(Entry{Actor: "user:1", Action: "POST /posts", Resource: "post:1", After: []byte(`{"id":1}`)})
+	now = now.Add(time.Hour)
+	l.Append(Entry{Actor: "user:2", Action: "PATCH /users/{id}/profile", Resource: "user:2"})
+	now = now.Add(time.Hour)
+	third := l.Append(Entry{Actor: "user:1", Action: "DELETE /posts/{id}", Resource: "post:1", Before: []byte(`{"id":1}`)})
+
+	t.Run("entries are chained", func(t *testing.T) {
+		if first.Seq != 1 || first.PrevHash != "" || first.Hash != Hash(first) {
+			t.Errorf("Expected the first entry to start the chain, got %+v", first)
+		}
+		entries := l.Query(Filter{})
+		if third.Seq != 3 || third.PrevHash != entries[1].Hash {
+			t.Errorf("Expected entry 3 to be chained to entry 2, got %+v", third)
+		}
+		if n, err := l.Verify(); n != 3 || err != nil {
+			t.Errorf("Expected 3 valid entries, got %d and %v", n, err)
+		}
+	})
+
+	t.Run("Query filters entries", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			filter   Filter
+			expected []uint64
+		}{
+			{"actor", Filter{Actor: "user:1"}, []uint64{1, 3}},
+			{"resource", Filter{Resource: "post:1"}, []uint64{1, 3}},
+			{"resource type", Filter{Resource: "user"}, []uint64{2}},
+			{"resource type is not a prefix", Filter{Resource: "pos"}, nil},
+			{"since", Filter{Since: now.Add(-time.Hour)}, []uint64{2, 3}},
+			{"after and limit", Filter{After: 1, Limit: 1}, []uint64{2}},
+		}
+		for _, tt := range tests {
+			var got []uint64
+			for _, e := range l.Query(tt.filter) {
+				got = append(got, e.Seq)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("%s: expected entries %v, got %v", tt.name, tt.expected, got)
+			}
+		}
+	})
+
+	t.Run("tampering is detected", func(t *testing.T) {
+		entries := l.Query(Filter{})
+		tests := []struct {
+			name   string
+			tamper func([]Entry) []Entry
+		}{
+			{"changed field", func(e []Entry) []Entry { e[1].Actor = "user:3"; return e }},
+			{"changed snapshot", func(e []Entry) []Entry { e[0].After = []byte(`{"id":2}`); return e }},
+			{"rehashed entry", func(e []Entry) []Entry { e[1].Resource = "user:3"; e[1].Hash = Hash(e[1]); return e }},
+			{"removed entry", func(e []Entry) []Entry { return append(e[:1], e[2:]...) }},
+			{"reordered entries", func(e []Entry) []Entry { e[1], e[2] = e[2], e[1]; return e }},
+		}
+		for _, tt := range tests {
+			copied := append([]Entry(nil), entries...)
+			if err := VerifyChain(tt.tamper(copied)); !errors.Is(err, ErrTampered) {
+				t.Errorf("%s: expected ErrTampered, got %v", tt.name, err)
+			}
+		}
+		if err := VerifyChain(entries); err != nil {
+			t.Errorf("Expected the original entries to verify, got %v", err)
+		}
+
+		l.entries[0].Status = 500
+		if _, err := l.Verify(); !errors.Is(err, ErrTampered) {
+			t.Errorf("Expected Verify to detect a changed entry, got %v", err)
+		}
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	Record(context.Background(), Change{Resource: "post:1"})
+
+	ctx, rec := WithRecorder(context.Background())
+	Record(ctx, Change{Resource: "post:1", Before: map[string]int{"id": 1}, After: map[string]int{"id": 1, "v": 2}})
+	before, after, err := Snapshots(rec.Changes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != `{"id":1}` || string(after) != `{"id":1,"v":2}` {
+		t.Errorf("Expected single snapshots, got %s and %s", before, after)
+	}
+
+	Record(ctx, Change{Resource: "post:2", After: map[string]int{"id": 2}})
+	before, after, _ = Snapshots(rec.Changes())
+	if string(before) != `[{"id":1}]` || string(after) != `[{"id":1,"v":2},{"id":2}]` {
+		t.Errorf("Expected arrays of snapshots, got %s and %s", before, after)
+	}
+	if before, after, _ := Snapshots(nil); before != nil || after != nil {
+		t.Errorf("Expected no snapshots without changes, got %s and %s", before, after)
+	}
+}
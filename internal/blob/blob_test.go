@@ -0,0 +1,55 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStores(t *testing.T) {
+	ctx := context.Background()
+	fs, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for name, s := range map[string]Store{"FS": fs, "Memory": NewMemory()} {
+		t.Run(name, func(t *testing.T) {
+			if err := s.Put(ctx, "avatars/1/64.png", strings.NewReader("first")); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := s.Put(ctx, "avatars/1/64.png", strings.NewReader("second")); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			r, err := s.Get(ctx, "avatars/1/64.png")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			data, _ := io.ReadAll(r)
+			r.Close()
+			if string(data) != "second" {
+				t.Errorf("Expected second, got %q", data)
+			}
+
+			if err := s.Delete(ctx, "avatars/1/64.png"); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if _, err := s.Get(ctx, "avatars/1/64.png"); err != ErrNotFound {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+			if err := s.Delete(ctx, "avatars/1/64.png"); err != nil {
+				t.Errorf("Expected deleting a missing key to succeed, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Keys cannot escape the store", func(t *testing.T) {
+		for _, key := range []string{"", "/etc/passwd", "../secret", "a/../../b", "a//b"} {
+			if err := fs.Put(ctx, key, strings.NewReader("x")); err == nil {
+				t.Errorf("Expected key %q to be rejected", key)
+			}
+		}
+	})
+}
@@ -0,0 +1,217 @@
+// Package events carries notices of changes made by the services to live consumers,
+// such as the GET /events Server-Sent Events stream.
+//
+// Services publish through the Publisher interface after a mutation succeeds. Log is
+// the in-memory implementation: it numbers events, keeps the most recent ones so that
+// reconnecting clients can resume where they left off, and fans them out to subscribers.
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Type names a kind of event, such as "post.created".
+type Type string
+
+// Event types published by the services.
+const (
+	UserRegistered Type = "user.registered"
+	UserDeleted    Type = "user.deleted"
+	PostCreated    Type = "post.created"
+	PostUpdated    Type = "post.updated"
+	PostDeleted    Type = "post.deleted"
+	CommentCreated Type = "comment.created"
+	CommentDeleted Type = "comment.deleted"
+)
+
+// Types lists every event type.
+var Types = []Type{UserRegistered, UserDeleted, PostCreated, PostUpdated, PostDeleted, CommentCreated, CommentDeleted}
+
+// Event is a change that happened in the system.
+type Event struct {
+	// ID increases with every published event; it is assigned by the Log
+	ID uint64 `json:"id"`
+	// Type is the kind of change
+	Type Type `json:"type"`
+	// UserID is the user the event concerns: the registered or deleted user, or the author of a post or comment
+	UserID int `json:"user_id"`
+	// PostID is the post the event concerns, for post and comment events
+	PostID int `json:"post_id,omitempty"`
+	// Data is the event payload, such as the created post
+	Data any `json:"data"`
+	// Private, if not nil, replaces Data for the user the event concerns, such as the
+	// registered user's own email. It is not sent to anyone else.
+	Private any `json:"-"`
+	// OwnerID, if not zero, is the only user who may receive the event, such as for
+	// changes to a draft post. It is not sent to clients.
+	OwnerID int `json:"-"`
+	// Time is when the event was published; it is assigned by the Log
+	Time time.Time `json:"time"`
+}
+
+// For returns e as viewerID receives it: with Private as the payload if the viewer is
+// the user the event concerns, and with Private left out otherwise.
+func (e Event) For(viewerID int) Event {
+	if e.Private != nil && viewerID != 0 && viewerID == e.UserID {
+		e.Data = e.Private
+	}
+	e.Private = nil
+	return e
+}
+
+// Publisher receives the events emitted by the services.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// Filter selects the events a subscriber receives. The zero Filter matches every event
+// that is not restricted to an owner.
+type Filter struct {
+	// Types keeps only events of these types, if not empty
+	Types []Type
+	// UserID keeps only events concerning this user, if not zero
+	UserID int
+	// ViewerID is the user receiving the events, zero for anonymous subscribers.
+	// Events restricted to another owner are left out.
+	ViewerID int
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e Event) bool {
+	if e.OwnerID != 0 && e.OwnerID != f.ViewerID {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	return f.UserID == 0 || e.UserID == f.UserID
+}
+
+// DefaultBufferSize is the number of events a subscriber may fall behind by before it is dropped.
+const DefaultBufferSize = 64
+
+// Subscription delivers the events matching a filter as they are published.
+type Subscription struct {
+	// C receives the events. It is closed when the subscriber falls too far behind,
+	// is cancelled, or the Log is closed.
+	C      <-chan Event
+	c      chan Event
+	filter Filter
+	log    *Log
+	once   sync.Once
+}
+
+// Cancel stops the subscription and closes C.
+func (s *Subscription) Cancel() {
+	s.log.mu.Lock()
+	defer s.log.mu.Unlock()
+	s.log.drop(s)
+}
+
+// Log is a bounded, in-memory history of events that also fans them out to subscribers.
+// It is safe for concurrent use.
+type Log struct {
+	mu          sync.Mutex
+	events      []Event
+	capacity    int
+	nextID      uint64
+	subscribers map[*Subscription]struct{}
+	closed      bool
+	now         func() time.Time
+}
+
+// NewLog creates a Log that keeps the last capacity events for resuming subscribers.
+func NewLog(capacity int) *Log {
+	return &Log{
+		events:      make([]Event, 0, capacity),
+		capacity:    capacity,
+		nextID:      1,
+		subscribers: make(map[*Subscription]struct{}),
+		now:         time.Now,
+	}
+}
+
+// Publish numbers e, records it and delivers it to every matching subscriber.
+// It never blocks: subscribers whose buffer is full are dropped, and can resume
+// from the last event they received by subscribing again.
+func (l *Log) Publish(ctx context.Context, e Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.ID = l.nextID
+	e.Time = l.now()
+	l.nextID++
+	if len(l.events) == l.capacity {
+		l.events = slices.Delete(l.events, 0, 1)
+	}
+	l.events = append(l.events, e)
+
+	for s := range l.subscribers {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			l.drop(s)
+		}
+	}
+}
+
+// Subscribe starts delivering the events matching filter. If after is not zero, the
+// retained events published after the event with that ID are delivered first, so a
+// client can resume from the last event it saw; events already evicted from the log
+// are skipped. buffer is the number of events the subscriber may fall behind by.
+func (l *Log) Subscribe(filter Filter, after uint64, buffer int) *Subscription {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var backlog []Event
+	if after > 0 {
+		for _, e := range l.events {
+			if e.ID > after && filter.Match(e) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+	c := make(chan Event, len(backlog)+buffer)
+	for _, e := range backlog {
+		c <- e
+	}
+	s := &Subscription{C: c, c: c, filter: filter, log: l}
+	if l.closed {
+		close(c)
+		return s
+	}
+	l.subscribers[s] = struct{}{}
+	return s
+}
+
+// Close ends every subscription; later subscriptions end immediately.
+// Use it on shutdown so streaming handlers return.
+func (l *Log) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	for s := range l.subscribers {
+		l.drop(s)
+	}
+}
+
+// Closed reports whether Close has been called, which tells subscribers that were
+// shut down apart from those dropped for falling behind.
+func (l *Log) Closed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// drop removes a subscriber and closes its channel. Callers must hold l.mu.
+func (l *Log) drop(s *Subscription) {
+	delete(l.subscribers, s)
+	s.once.Do(func() { close(s.c) })
+}
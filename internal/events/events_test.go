@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+// ids drains the events already buffered on a subscription.
+func ids(s *Subscription) []uint64 {
+	var out []uint64
+	for {
+		select {
+		case e, ok := <-s.C:
+			if !ok {
+				return out
+			}
+			out = append(out, e.ID)
+		default:
+			return out
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+	ctx := context.Background()
+	l := NewLog(3)
+
+	t.Run("Delivers matching events", func(t *testing.T) {
+		all := l.Subscribe(Filter{}, 0, DefaultBufferSize)
+		posts := l.Subscribe(Filter{Types: []Type{PostCreated}, UserID: 2}, 0, DefaultBufferSize)
+		defer all.Cancel()
+		defer posts.Cancel()
+
+		l.Publish(ctx, Event{Type: UserRegistered, UserID: 2})
+		l.Publish(ctx, Event{Type: PostCreated, UserID: 1})
+		l.Publish(ctx, Event{Type: PostCreated, UserID: 2})
+
+		if got := ids(all); len(got) != 3 || got[0] != 1 || got[2] != 3 {
+			t.Errorf("Expected events 1 to 3, got %v", got)
+		}
+		if got := ids(posts); len(got) != 1 || got[0] != 3 {
+			t.Errorf("Expected event 3, got %v", got)
+		}
+	})
+
+	t.Run("Resumes from retained events", func(t *testing.T) {
+		l.Publish(ctx, Event{Type: PostDeleted, UserID: 2})
+
+		s := l.Subscribe(Filter{}, 2, DefaultBufferSize)
+		defer s.Cancel()
+		if got := ids(s); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+			t.Errorf("Expected events 3 and 4, got %v", got)
+		}
+
+		// Event 2 has been evicted, so resuming from 1 starts at the oldest retained event.
+		s = l.Subscribe(Filter{}, 1, DefaultBufferSize)
+		defer s.Cancel()
+		if got := ids(s); len(got) != 3 || got[0] != 2 {
+			t.Errorf("Expected events 2 to 4, got %v", got)
+		}
+	})
+
+	t.Run("Drops slow subscribers", func(t *testing.T) {
+		s := l.Subscribe(Filter{}, 0, 1)
+		l.Publish(ctx, Event{Type: UserDeleted, UserID: 3})
+		l.Publish(ctx, Event{Type: UserDeleted, UserID: 4})
+
+		if got := ids(s); len(got) != 1 || got[0] != 5 {
+			t.Errorf("Expected event 5 before the channel closed, got %v", got)
+		}
+		if _, ok := <-s.C; ok {
+			t.Error("Expected channel to be closed")
+		}
+		s.Cancel()
+	})
+
+	t.Run("Close ends subscriptions", func(t *testing.T) {
+		s := l.Subscribe(Filter{}, 0, DefaultBufferSize)
+		l.Close()
+		if _, ok := <-s.C; ok {
+			t.Error("Expected channel to be closed")
+		}
+		if _, ok := <-l.Subscribe(Filter{}, 0, DefaultBufferSize).C; ok {
+			t.Error("Expected subscriptions after Close to be closed")
+		}
+	})
+}
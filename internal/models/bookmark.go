@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Bookmark is a post a user saved to read later.
+type Bookmark struct {
+	// Post is the bookmarked post
+	Post Post `json:"post"`
+	// SavedAt is the time the post was bookmarked
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// BookmarkPage is one page of a user's bookmarks.
+type BookmarkPage struct {
+	// Bookmarks are the page's bookmarks, most recently saved first
+	Bookmarks []Bookmark `json:"bookmarks"`
+	// NextCursor fetches the following page; it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ReadingList is a named, ordered collection of posts kept by a user.
+type ReadingList struct {
+	// ID is the unique identifier of the reading list
+	ID int `json:"id"`
+	// UserID is the ID of the user who owns the list
+	UserID int `json:"user_id"`
+	// Name is the list's name, unique among the owner's lists
+	Name string `json:"name"`
+	// PostIDs are the IDs of the posts in the list, in reading order
+	PostIDs []int `json:"post_ids"`
+	// CreatedAt is the time the list was created
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is the time the list was last changed
+	UpdatedAt time.Time `json:"updated_at"`
+}
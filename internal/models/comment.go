@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Comment represents a comment left on a post.
+// Comments can reply to another comment on the same post to form threads.
+type Comment struct {
+	// ID is the unique identifier for the comment
+	ID int `json:"id"`
+	// PostID is the ID of the post the comment belongs to
+	PostID int `json:"post_id"`
+	// UserID is the ID of the user who wrote the comment
+	UserID int `json:"user_id"`
+	// ParentID is the ID of the comment being replied to, if any
+	ParentID *int `json:"parent_id,omitempty"`
+	// Body represents the comment's text
+	Body string `json:"body"`
+	// CreatedAt is the time the comment was created
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is the time the comment was last modified
+	UpdatedAt time.Time `json:"updated_at"`
+}
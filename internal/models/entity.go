@@ -0,0 +1,33 @@
+package models
+
+// PostEntities are the mentions and hashtags found in a post's content.
+// Offsets count Unicode code points from the start of the content; Start points at
+// the leading '@' or '#' and End is exclusive.
+type PostEntities struct {
+	// Mentions are the @handles that belong to a user, in order of appearance
+	Mentions []Mention `json:"mentions"`
+	// Hashtags are the #tags, in order of appearance
+	Hashtags []Hashtag `json:"hashtags"`
+}
+
+// Mention is a reference to a user by their handle.
+type Mention struct {
+	// Handle is the mentioned handle, lower-cased and without the '@'
+	Handle string `json:"handle"`
+	// UserID is the ID of the user the handle belonged to when the content was written
+	UserID int `json:"user_id"`
+	// Start is the offset of the '@'
+	Start int `json:"start"`
+	// End is the offset just past the handle
+	End int `json:"end"`
+}
+
+// Hashtag is a #tag written in a post's content.
+type Hashtag struct {
+	// Tag is the normalized tag, without the '#'
+	Tag string `json:"tag"`
+	// Start is the offset of the '#'
+	Start int `json:"start"`
+	// End is the offset just past the tag
+	End int `json:"end"`
+}
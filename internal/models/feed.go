@@ -0,0 +1,9 @@
+package models
+
+// FeedPage is one page of a user's home feed.
+type FeedPage struct {
+	// Posts are the page's posts, most recently published first
+	Posts []Post `json:"posts"`
+	// NextCursor fetches the following page; it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// NotificationType is the category of event a notification reports.
+type NotificationType string
+
+// Notification categories.
+const (
+	NotificationComment  NotificationType = "comment"
+	NotificationMention  NotificationType = "mention"
+	NotificationFollow   NotificationType = "follow"
+	NotificationReaction NotificationType = "reaction"
+)
+
+// NotificationTypes lists every notification category.
+var NotificationTypes = []NotificationType{NotificationComment, NotificationMention, NotificationFollow, NotificationReaction}
+
+// Notification tells a user that others interacted with them or their content.
+// Events of the same type about the same subject are aggregated into one unread
+// notification, such as "3 people reacted to your post".
+type Notification struct {
+	// ID is the unique identifier for the notification
+	ID int `json:"id"`
+	// UserID is the ID of the user the notification is for
+	UserID int `json:"user_id"`
+	// Type is the category of event
+	Type NotificationType `json:"type"`
+	// PostID is the ID of the post involved, if any
+	PostID int `json:"post_id,omitempty"`
+	// CommentID is the ID of the comment replied to, for notifications about replies
+	CommentID int `json:"comment_id,omitempty"`
+	// ActorIDs are the IDs of the users who caused the events, most recent first
+	ActorIDs []int `json:"actor_ids"`
+	// Message is a human-readable summary, such as "3 people reacted to your post"
+	Message string `json:"message"`
+	// Read reports whether the user has marked the notification as read
+	Read bool `json:"read"`
+	// CreatedAt is the time of the first event
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is the time of the latest event
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPage is one page of a user's notifications.
+type NotificationPage struct {
+	// Notifications are the page's notifications, most recently updated first
+	Notifications []Notification `json:"notifications"`
+	// Total is the number of notifications matching the request
+	Total int `json:"total"`
+	// Unread is the number of unread notifications the user has
+	Unread int `json:"unread"`
+}
+
+// NotificationPreferences are a user's notification settings.
+type NotificationPreferences struct {
+	// Muted lists the categories the user does not want to be notified about
+	Muted []NotificationType `json:"muted"`
+}
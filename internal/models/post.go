@@ -1,14 +1,66 @@
 package models
 
+import "time"
+
+// PostStatus is the lifecycle state of a post.
+type PostStatus string
+
+// Post lifecycle states. Only published posts are visible to users other than the author.
+const (
+	PostDraft     PostStatus = "draft"
+	PostScheduled PostStatus = "scheduled"
+	PostPublished PostStatus = "published"
+	PostArchived  PostStatus = "archived"
+)
+
+// ContentFormat is the markup language a post's content is written in.
+type ContentFormat string
+
+// Supported content formats.
+const (
+	FormatPlain    ContentFormat = "plain"
+	FormatMarkdown ContentFormat = "markdown"
+)
+
 // Post represents a post entity in the system.
-// It contains basic post information such as ID, title, content, and user ID.
+// It contains basic post information such as ID, title, content, and user ID,
+// along with its lifecycle status.
 type Post struct {
 	// ID is the unique identifier for the post
 	ID int `json:"id"`
 	// Title represents the post's title
 	Title string `json:"title"`
+	// Slug is the post's unique, URL-friendly name derived from its title
+	Slug string `json:"slug"`
 	// Content represents the post's content
 	Content string `json:"content"`
+	// Format is the markup language of Content
+	Format ContentFormat `json:"format"`
+	// ContentHTML is Content rendered as sanitized HTML, computed once for each version of the content
+	ContentHTML string `json:"content_html"`
+	// Entities are the mentions and hashtags found in Content
+	Entities PostEntities `json:"entities"`
 	// UserID is the ID of the user who created the post
 	UserID int `json:"user_id"`
+	// Tags are the post's normalized, deduplicated tags in alphabetical order
+	Tags []string `json:"tags"`
+	// Reactions counts the post's reactions by type; types nobody used are omitted
+	Reactions map[ReactionType]int `json:"reactions"`
+	// Status is the post's lifecycle state
+	Status PostStatus `json:"status"`
+	// PublishAt is the time a scheduled post will be published
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// PublishedAt is the time the post was last published
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// CreatedAt is the time the post was created
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is the time the post was last modified
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// VisibleTo reports whether the user with the given ID may see the post.
+// Published posts are public; other states are visible only to the author.
+// A userID of 0 stands for an anonymous viewer.
+func (p Post) VisibleTo(userID int) bool {
+	return p.Status == PostPublished || (userID != 0 && p.UserID == userID)
 }
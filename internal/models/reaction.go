@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ReactionType is one of the fixed set of reactions users can leave on a post.
+type ReactionType string
+
+// Supported reactions.
+const (
+	ReactionLike  ReactionType = "like"
+	ReactionLove  ReactionType = "love"
+	ReactionLaugh ReactionType = "laugh"
+	ReactionWow   ReactionType = "wow"
+	ReactionSad   ReactionType = "sad"
+	ReactionAngry ReactionType = "angry"
+)
+
+// ReactionTypes lists every supported reaction.
+var ReactionTypes = []ReactionType{ReactionLike, ReactionLove, ReactionLaugh, ReactionWow, ReactionSad, ReactionAngry}
+
+// Reaction records that a user reacted to a post.
+// A user may leave several reactions on the same post, but only one of each type.
+type Reaction struct {
+	// PostID is the ID of the post reacted to
+	PostID int `json:"post_id"`
+	// UserID is the ID of the user who reacted
+	UserID int `json:"user_id"`
+	// Type is the kind of reaction
+	Type ReactionType `json:"type"`
+	// CreatedAt is the time the reaction was left
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// PostRevision is an immutable snapshot of a post's title and content.
+// A revision is recorded when a post is created and every time it is edited.
+type PostRevision struct {
+	// PostID is the ID of the post the revision belongs to
+	PostID int `json:"post_id"`
+	// Number is the revision's position in the post's history, starting at 1
+	Number int `json:"number"`
+	// Title is the post's title at this revision
+	Title string `json:"title"`
+	// Content is the post's content at this revision
+	Content string `json:"content"`
+	// EditorID is the ID of the user who made the change
+	EditorID int `json:"editor_id"`
+	// RestoredFrom is the number of the revision this one restored, if any
+	RestoredFrom *int `json:"restored_from,omitempty"`
+	// CreatedAt is the time the revision was recorded
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DiffLine is one line of a line-based diff.
+type DiffLine struct {
+	// Op is "equal", "insert" or "delete"
+	Op string `json:"op"`
+	// Text is the line's content without the trailing newline
+	Text string `json:"text"`
+}
+
+// RevisionDiff describes the changes between two revisions of a post.
+type RevisionDiff struct {
+	// From is the number of the older revision
+	From int `json:"from"`
+	// To is the number of the newer revision
+	To int `json:"to"`
+	// Title holds the diff of the titles
+	Title []DiffLine `json:"title"`
+	// Content holds the diff of the contents
+	Content []DiffLine `json:"content"`
+}
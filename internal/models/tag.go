@@ -0,0 +1,9 @@
+package models
+
+// TagCount is a tag together with the number of published posts carrying it.
+type TagCount struct {
+	// Tag is the normalized tag name
+	Tag string `json:"tag"`
+	// Count is the number of published posts with the tag
+	Count int `json:"count"`
+}
@@ -1,7 +1,10 @@
 package models
 
+import "time"
+
 // User represents a user entity in the system.
-// It contains basic user information such as ID, name, and email.
+// It contains basic user information such as ID, name, and email,
+// along with the public profile the user chooses to share.
 type User struct {
 	// ID is the unique identifier for the user
 	ID int `json:"id"`
@@ -9,4 +12,24 @@ type User struct {
 	Name string `json:"name"`
 	// Email is the user's email address
 	Email string `json:"email"`
+	// Handle is the unique, lower-case name others use to @mention the user
+	Handle string `json:"handle"`
+	// DisplayName is the name shown on the user's profile, if different from Name
+	DisplayName string `json:"display_name"`
+	// Bio is a short description the user writes about themselves
+	Bio string `json:"bio"`
+	// Location is where the user says they are
+	Location string `json:"location"`
+	// Website is an http or https URL the user links to
+	Website string `json:"website"`
+	// Avatar holds the user's profile picture thumbnails, if one was uploaded
+	Avatar *Avatar `json:"avatar,omitempty"`
+}
+
+// Avatar describes the thumbnails generated from a user's uploaded profile picture.
+type Avatar struct {
+	// URLs maps each thumbnail size, in pixels, to the URL serving it
+	URLs map[int]string `json:"urls"`
+	// UpdatedAt is the time the picture was uploaded
+	UpdatedAt time.Time `json:"updated_at"`
 }
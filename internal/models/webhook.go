@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is a subscription that sends events to an external URL.
+type Webhook struct {
+	// ID is the unique identifier for the webhook
+	ID int `json:"id"`
+	// UserID is the ID of the user who owns the webhook; it only receives events about posts they can see
+	UserID int `json:"user_id"`
+	// URL is the http or https endpoint events are POSTed to
+	URL string `json:"url"`
+	// Events lists the event types sent to the webhook, such as "post.created"
+	Events []string `json:"events"`
+	// Secret signs the deliveries. It is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+	// Active reports whether new events are sent to the webhook
+	Active bool `json:"active"`
+	// CreatedAt is the time the webhook was created
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryStatus is the state of a webhook delivery.
+type WebhookDeliveryStatus string
+
+// Webhook delivery states.
+const (
+	// WebhookPending deliveries are waiting for their next attempt
+	WebhookPending WebhookDeliveryStatus = "pending"
+	// WebhookSucceeded deliveries were acknowledged with a 2xx response
+	WebhookSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDead deliveries failed every attempt and are no longer retried
+	WebhookDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery is one event sent to a webhook, with every attempt made to send it.
+type WebhookDelivery struct {
+	// ID is the unique identifier for the delivery, sent in the X-Webhook-ID header.
+	// It stays the same across retries and redeliveries, so receivers can use it to ignore duplicates.
+	ID int `json:"id"`
+	// WebhookID is the ID of the webhook the delivery is for
+	WebhookID int `json:"webhook_id"`
+	// Event is the event type
+	Event string `json:"event"`
+	// EventID identifies the event, sent in the X-Webhook-Event-ID header. An event published
+	// again after an interrupted relay keeps its ID and is not delivered twice.
+	EventID string `json:"event_id,omitempty"`
+	// Payload is the request body, in the same format as the events of GET /events
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+	// Status is the state of the delivery
+	Status WebhookDeliveryStatus `json:"status"`
+	// Attempts are the attempts made so far, oldest first
+	Attempts []WebhookAttempt `json:"attempts"`
+	// NextAttemptAt is when a pending delivery is retried
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	// CreatedAt is the time the event was published
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookAttempt is the outcome of one request to a webhook.
+type WebhookAttempt struct {
+	// At is the time the request was sent
+	At time.Time `json:"at"`
+	// StatusCode is the response status, if a response was received
+	StatusCode int `json:"status_code,omitempty"`
+	// Error describes why the attempt failed, if it did
+	Error string `json:"error,omitempty"`
+	// DurationMS is how long the request took, in milliseconds
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// WebhookDeliveryPage is one page of webhook deliveries.
+type WebhookDeliveryPage struct {
+	// Deliveries are the page's deliveries, most recent first
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	// Total is the number of deliveries matching the request
+	Total int `json:"total"`
+}
@@ -0,0 +1,87 @@
+// Package render turns post content into sanitized HTML.
+//
+// Markdown is rendered with GitHub-flavored extensions and raw HTML disabled, then
+// passed through an allowlist sanitizer as a second line of defense: only basic
+// formatting elements survive, links are limited to http, https and mailto, and
+// every script, style, event handler and unknown attribute is dropped.
+package render
+
+import (
+	"bytes"
+	"example/api/internal/models"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	),
+)
+
+var policy = newPolicy()
+
+// newPolicy builds the allowlist applied to every rendered document.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements(
+		"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+		"blockquote", "pre", "code", "em", "strong", "del",
+		"ul", "ol", "li", "table", "thead", "tbody", "tr", "th", "td",
+	)
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowAttrs("src", "alt", "title").OnElements("img")
+	p.AllowAttrs("title").OnElements("a")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^(checked|disabled|)$`)).OnElements("input")
+
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.AllowRelativeURLs(true)
+	p.RequireParseableURLs(true)
+	p.RequireNoFollowOnLinks(true)
+	return p
+}
+
+// HTML renders content written in format as sanitized HTML.
+// Plain text is escaped, with blank lines separating paragraphs and single newlines kept as line breaks.
+func HTML(format models.ContentFormat, content string) (string, error) {
+	switch format {
+	case models.FormatPlain:
+		return plain(content), nil
+	case models.FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(content), &buf); err != nil {
+			return "", fmt.Errorf("rendering markdown: %w", err)
+		}
+		return policy.Sanitize(buf.String()), nil
+	default:
+		return "", fmt.Errorf("unsupported content format %q", format)
+	}
+}
+
+func plain(content string) string {
+	var b strings.Builder
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	for _, para := range strings.Split(content, "\n\n") {
+		para = strings.Trim(para, "\n")
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
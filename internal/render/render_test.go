@@ -0,0 +1,70 @@
+package render
+
+import (
+	"example/api/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	t.Run("Plain text is escaped", func(t *testing.T) {
+		got, err := HTML(models.FormatPlain, "a < b\nnext line\n\n<script>alert(1)</script>")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := "<p>a &lt; b<br>\nnext line</p>\n<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"
+		if got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("Markdown is rendered", func(t *testing.T) {
+		got, _ := HTML(models.FormatMarkdown, "# Title\n\n**bold** and ~~gone~~\n\n```go\nfmt.Println()\n```\n\n- [x] done")
+		for _, want := range []string{
+			"<h1>Title</h1>",
+			"<strong>bold</strong>",
+			"<del>gone</del>",
+			`<code class="language-go">`,
+			`<input checked="" disabled="" type="checkbox"`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("Expected output to contain %q, got %q", want, got)
+			}
+		}
+	})
+
+	t.Run("Dangerous markup is stripped", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			banned  string
+		}{
+			{"raw script", "<script>alert(1)</script>", "<script"},
+			{"event handler", `<img src="x.png" onerror="alert(1)">`, "onerror"},
+			{"javascript link", "[click](javascript:alert(1))", "javascript:"},
+			{"data image", "![x](data:text/html;base64,PHNjcmlwdD4=)", "data:"},
+			{"inline style", `<p style="color:red">hi</p>`, "style="},
+			{"iframe", `<iframe src="https://example.com"></iframe>`, "<iframe"},
+		}
+		for _, tt := range tests {
+			got, _ := HTML(models.FormatMarkdown, tt.content)
+			if strings.Contains(got, tt.banned) {
+				t.Errorf("%s: expected %q to be stripped, got %q", tt.name, tt.banned, got)
+			}
+		}
+	})
+
+	t.Run("Links are kept with nofollow", func(t *testing.T) {
+		got, _ := HTML(models.FormatMarkdown, "[docs](https://example.com/docs)")
+		expected := `<a href="https://example.com/docs" rel="nofollow">docs</a>`
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected output to contain %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("Unknown formats are rejected", func(t *testing.T) {
+		if _, err := HTML("rst", "text"); err == nil {
+			t.Error("Expected unsupported format error")
+		}
+	})
+}
@@ -0,0 +1,373 @@
+// Package search implements an in-memory full-text index with BM25 ranking.
+//
+// Documents are tokenized, accent-folded and stemmed with an English or Spanish
+// stemmer chosen per document. Queries support plain terms, "quoted phrases" and
+// prefix terms ending in '*'; a document must match every clause to be returned.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field is a named piece of text in a document. Weight boosts matches in the field.
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+}
+
+// Document is the unit of indexing, identified by its kind and ID.
+type Document struct {
+	// Kind groups documents, such as "post" or "user"
+	Kind string
+	// ID identifies the document within its kind
+	ID int
+	// Title is returned with results
+	Title string
+	// Fields hold the searchable text
+	Fields []Field
+}
+
+type docKey struct {
+	kind string
+	id   int
+}
+
+// indexedField is a field with its tokens and their stems.
+type indexedField struct {
+	Field
+	tokens []token
+	stems  []string
+}
+
+type indexedDoc struct {
+	doc    Document
+	lang   Language
+	fields []indexedField
+	length float64
+}
+
+// posting records where a stem occurs in one document.
+type posting struct {
+	// freq is the weighted term frequency across fields
+	freq float64
+	// positions maps a field index to the token positions of the stem
+	positions map[int][]int
+}
+
+// Index is an inverted index safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[docKey]*indexedDoc
+	postings map[string]map[docKey]*posting
+	// surface maps folded words to the stems they produced, for prefix queries
+	surface  map[string]map[string]int
+	totalLen float64
+}
+
+// NewIndex creates an empty Index.
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[docKey]*indexedDoc),
+		postings: make(map[string]map[docKey]*posting),
+		surface:  make(map[string]map[string]int),
+	}
+}
+
+// Upsert adds a document to the index, replacing any previous version.
+func (ix *Index) Upsert(doc Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	key := docKey{doc.Kind, doc.ID}
+	ix.remove(key)
+
+	var all []token
+	fields := make([]indexedField, len(doc.Fields))
+	for i, f := range doc.Fields {
+		if f.Weight <= 0 {
+			f.Weight = 1
+		}
+		fields[i] = indexedField{Field: f, tokens: tokenize(f.Text)}
+		all = append(all, fields[i].tokens...)
+	}
+	d := &indexedDoc{doc: doc, lang: detectLanguage(all), fields: fields}
+
+	for fi := range fields {
+		f := &fields[fi]
+		f.stems = make([]string, len(f.tokens))
+		for pos, t := range f.tokens {
+			s := stem(t.term, d.lang)
+			f.stems[pos] = s
+			p := ix.postingFor(s, key)
+			p.freq += f.Weight
+			p.positions[fi] = append(p.positions[fi], pos)
+			if ix.surface[t.term] == nil {
+				ix.surface[t.term] = make(map[string]int)
+			}
+			ix.surface[t.term][s]++
+		}
+		d.length += f.Weight * float64(len(f.tokens))
+	}
+
+	ix.docs[key] = d
+	ix.totalLen += d.length
+}
+
+// Remove deletes a document from the index. Removing an unknown document is a no-op.
+func (ix *Index) Remove(kind string, id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(docKey{kind, id})
+}
+
+// Len returns the number of indexed documents.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+func (ix *Index) postingFor(stem string, key docKey) *posting {
+	docs := ix.postings[stem]
+	if docs == nil {
+		docs = make(map[docKey]*posting)
+		ix.postings[stem] = docs
+	}
+	p := docs[key]
+	if p == nil {
+		p = &posting{positions: make(map[int][]int)}
+		docs[key] = p
+	}
+	return p
+}
+
+// remove deletes a document. Callers must hold ix.mu for writing.
+func (ix *Index) remove(key docKey) {
+	d, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+	for _, f := range d.fields {
+		for pos, s := range f.stems {
+			delete(ix.postings[s], key)
+			if len(ix.postings[s]) == 0 {
+				delete(ix.postings, s)
+			}
+			term := f.tokens[pos].term
+			if ix.surface[term][s]--; ix.surface[term][s] <= 0 {
+				delete(ix.surface[term], s)
+				if len(ix.surface[term]) == 0 {
+					delete(ix.surface, term)
+				}
+			}
+		}
+	}
+	delete(ix.docs, key)
+	ix.totalLen -= d.length
+}
+
+// Options narrow and paginate a search.
+type Options struct {
+	// Kind restricts results to one kind of document when not empty
+	Kind string
+	// Limit is the maximum number of hits returned
+	Limit int
+	// Offset skips that many hits, for pagination
+	Offset int
+}
+
+// Hit is one search result.
+type Hit struct {
+	Kind    string  `json:"type"`
+	ID      int     `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// Results is a page of hits with the total number of matching documents.
+type Results struct {
+	Total int   `json:"total"`
+	Hits  []Hit `json:"hits"`
+}
+
+// Search runs a query and returns one page of hits ranked by BM25 score.
+// An empty or invalid query matches nothing.
+func (ix *Index) Search(query string, opts Options) Results {
+	clauses := parseQuery(query)
+	if len(clauses) == 0 {
+		return Results{Hits: []Hit{}}
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	type match struct {
+		key   docKey
+		score float64
+		stems map[string]bool
+	}
+	var candidates map[docKey]*match
+	for _, c := range clauses {
+		found := ix.matchClause(c)
+		if candidates == nil {
+			candidates = make(map[docKey]*match, len(found))
+			for key, stems := range found {
+				if opts.Kind == "" || key.kind == opts.Kind {
+					candidates[key] = &match{key: key, stems: stems}
+				}
+			}
+			continue
+		}
+		for key, m := range candidates {
+			stems, ok := found[key]
+			if !ok {
+				delete(candidates, key)
+				continue
+			}
+			for s := range stems {
+				m.stems[s] = true
+			}
+		}
+	}
+
+	matches := make([]*match, 0, len(candidates))
+	for _, m := range candidates {
+		m.score = ix.score(m.key, m.stems)
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if matches[i].key.kind != matches[j].key.kind {
+			return matches[i].key.kind < matches[j].key.kind
+		}
+		return matches[i].key.id > matches[j].key.id
+	})
+
+	results := Results{Total: len(matches), Hits: []Hit{}}
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	end := len(matches)
+	if opts.Limit > 0 {
+		end = min(end, opts.Offset+opts.Limit)
+	}
+	for i := opts.Offset; i < end; i++ {
+		m := matches[i]
+		d := ix.docs[m.key]
+		results.Hits = append(results.Hits, Hit{
+			Kind:    m.key.kind,
+			ID:      m.key.id,
+			Title:   d.doc.Title,
+			Snippet: snippet(d, m.stems),
+			Score:   math.Round(m.score*1000) / 1000,
+		})
+	}
+	return results
+}
+
+// matchClause returns the documents matching a clause, with the stems that matched.
+func (ix *Index) matchClause(c clause) map[docKey]map[string]bool {
+	found := make(map[docKey]map[string]bool)
+	add := func(key docKey, s string) {
+		if found[key] == nil {
+			found[key] = make(map[string]bool)
+		}
+		found[key][s] = true
+	}
+
+	switch {
+	case c.prefix:
+		for term, stems := range ix.surface {
+			if !strings.HasPrefix(term, c.terms[0]) {
+				continue
+			}
+			for s := range stems {
+				for key := range ix.postings[s] {
+					add(key, s)
+				}
+			}
+		}
+	case len(c.terms) == 1:
+		for _, s := range queryStems(c.terms[0]) {
+			for key := range ix.postings[s] {
+				add(key, s)
+			}
+		}
+	default:
+		for key, d := range ix.docs {
+			if stems := phraseMatch(d, c.terms); stems != nil {
+				for _, s := range stems {
+					add(key, s)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// score computes the BM25 score of a document for the matched stems.
+func (ix *Index) score(key docKey, stems map[string]bool) float64 {
+	n := float64(len(ix.docs))
+	avgLen := ix.totalLen / n
+	d := ix.docs[key]
+	score := 0.0
+	for s := range stems {
+		p := ix.postings[s][key]
+		if p == nil {
+			continue
+		}
+		df := float64(len(ix.postings[s]))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		norm := 1 - bm25B + bm25B*d.length/avgLen
+		score += idf * p.freq * (bm25K1 + 1) / (p.freq + bm25K1*norm)
+	}
+	return score
+}
+
+// queryStems returns the stems a query term may have been indexed under, since
+// the query's language is unknown.
+func queryStems(term string) []string {
+	en := stem(term, English)
+	es := stem(term, Spanish)
+	if en == es {
+		return []string{en}
+	}
+	return []string{en, es}
+}
+
+// phraseMatch reports the stems of a phrase found as consecutive tokens in one
+// of the document's fields, or nil.
+func phraseMatch(d *indexedDoc, terms []string) []string {
+	want := make([]string, len(terms))
+	for i, t := range terms {
+		want[i] = stem(t, d.lang)
+	}
+	for _, f := range d.fields {
+		for start := 0; start+len(want) <= len(f.stems); start++ {
+			ok := true
+			for i, s := range want {
+				if f.stems[start+i] != s {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				return want
+			}
+		}
+	}
+	return nil
+}
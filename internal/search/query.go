@@ -0,0 +1,42 @@
+package search
+
+import "strings"
+
+// clause is one part of a query that a document must match.
+type clause struct {
+	// terms are folded words; more than one makes a phrase
+	terms []string
+	// prefix matches any word starting with the single term
+	prefix bool
+}
+
+// parseQuery splits a query into clauses. Text in double quotes is a phrase,
+// and a word ending in '*' matches words with that prefix.
+func parseQuery(q string) []clause {
+	var clauses []clause
+	for i, part := range strings.Split(q, `"`) {
+		if i%2 == 1 {
+			var terms []string
+			for _, t := range tokenize(part) {
+				terms = append(terms, t.term)
+			}
+			if len(terms) > 0 {
+				clauses = append(clauses, clause{terms: terms})
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			prefix := strings.HasSuffix(word, "*")
+			tokens := tokenize(word)
+			for j, t := range tokens {
+				c := clause{terms: []string{t.term}}
+				// Only the last word of "foo-bar*" is a prefix.
+				if prefix && j == len(tokens)-1 {
+					c.prefix = true
+				}
+				clauses = append(clauses, c)
+			}
+		}
+	}
+	return clauses
+}
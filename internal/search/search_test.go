@@ -0,0 +1,136 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStemmers(t *testing.T) {
+	english := map[string]string{
+		"running":     "run",
+		"caresses":    "caress",
+		"ponies":      "poni",
+		"relational":  "relat",
+		"hopeful":     "hope",
+		"generalize":  "gener",
+		"connections": "connect",
+	}
+	for word, want := range english {
+		if got := stemEnglish(word); got != want {
+			t.Errorf("stemEnglish(%q) = %q, want %q", word, got, want)
+		}
+	}
+
+	// Words sharing a root must share a stem.
+	spanish := [][]string{
+		{"canciones", "cancion"},
+		{"programacion", "programador", "programadores"},
+		{"nacional", "nacionales"},
+		{"gatos", "gato", "gata"},
+	}
+	for _, group := range spanish {
+		want := stemSpanish(Fold(group[0]))
+		for _, word := range group[1:] {
+			if got := stemSpanish(Fold(word)); got != want {
+				t.Errorf("stemSpanish(%q) = %q, want %q (stem of %q)", word, got, want, group[0])
+			}
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	ix := NewIndex()
+	ix.Upsert(Document{Kind: "post", ID: 1, Title: "Running in Go", Fields: []Field{
+		{Name: "title", Text: "Running in Go", Weight: 2},
+		{Name: "content", Text: "The runner runs a quick benchmark of the Go scheduler."},
+	}})
+	ix.Upsert(Document{Kind: "post", ID: 2, Title: "Canciones", Fields: []Field{
+		{Name: "title", Text: "Canciones de programación", Weight: 2},
+		{Name: "content", Text: "La canción más rápida del programador es la de los gatos."},
+	}})
+	ix.Upsert(Document{Kind: "user", ID: 1, Title: "Gopher Runner", Fields: []Field{
+		{Name: "name", Text: "Gopher Runner"},
+	}})
+
+	t.Run("Stemmed term matches inflections", func(t *testing.T) {
+		res := ix.Search("run", Options{})
+		if res.Total != 1 || res.Hits[0].ID != 1 {
+			t.Errorf("Expected post 1, got %+v", res)
+		}
+	})
+
+	t.Run("Spanish term with accents", func(t *testing.T) {
+		res := ix.Search("cancion", Options{})
+		if res.Total != 1 || res.Hits[0].ID != 2 {
+			t.Fatalf("Expected post 2, got %+v", res)
+		}
+		if !strings.Contains(res.Hits[0].Snippet, "<mark>canción</mark>") {
+			t.Errorf("Expected highlighted snippet, got %q", res.Hits[0].Snippet)
+		}
+	})
+
+	t.Run("Phrase query", func(t *testing.T) {
+		if res := ix.Search(`"quick benchmark"`, Options{}); res.Total != 1 {
+			t.Errorf("Expected 1 phrase match, got %d", res.Total)
+		}
+		if res := ix.Search(`"benchmark quick"`, Options{}); res.Total != 0 {
+			t.Errorf("Expected no match for reversed phrase, got %d", res.Total)
+		}
+	})
+
+	t.Run("Prefix query", func(t *testing.T) {
+		res := ix.Search("runn*", Options{})
+		if res.Total != 2 {
+			t.Errorf("Expected post and user, got %+v", res)
+		}
+		if res := ix.Search("runn*", Options{Kind: "user"}); res.Total != 1 || res.Hits[0].Kind != "user" {
+			t.Errorf("Expected only the user, got %+v", res)
+		}
+	})
+
+	t.Run("All clauses must match", func(t *testing.T) {
+		if res := ix.Search("go gatos", Options{}); res.Total != 0 {
+			t.Errorf("Expected no match, got %d", res.Total)
+		}
+	})
+
+	t.Run("Title matches rank higher", func(t *testing.T) {
+		ix.Upsert(Document{Kind: "post", ID: 3, Title: "Notes", Fields: []Field{
+			{Name: "title", Text: "Notes", Weight: 2},
+			{Name: "content", Text: "Some notes about the scheduler in Go and other things worth reading later."},
+		}})
+		res := ix.Search("go", Options{Kind: "post"})
+		if res.Total != 2 || res.Hits[0].ID != 1 {
+			t.Errorf("Expected post 1 first, got %+v", res.Hits)
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		res := ix.Search("go", Options{Limit: 1, Offset: 1})
+		if res.Total != 2 || len(res.Hits) != 1 || res.Hits[0].ID != 3 {
+			t.Errorf("Expected second page with post 3, got %+v", res)
+		}
+	})
+
+	t.Run("Snippets escape HTML", func(t *testing.T) {
+		ix.Upsert(Document{Kind: "post", ID: 4, Fields: []Field{{Name: "content", Text: "<script>alert(1)</script> xss"}}})
+		res := ix.Search("xss", Options{})
+		if strings.Contains(res.Hits[0].Snippet, "<script>") {
+			t.Errorf("Expected escaped snippet, got %q", res.Hits[0].Snippet)
+		}
+	})
+
+	t.Run("Update and remove", func(t *testing.T) {
+		ix.Upsert(Document{Kind: "post", ID: 2, Fields: []Field{{Name: "content", Text: "Now about dogs"}}})
+		if res := ix.Search("gatos", Options{}); res.Total != 0 {
+			t.Errorf("Expected old content to be gone, got %d", res.Total)
+		}
+		ix.Remove("post", 2)
+		if res := ix.Search("dogs", Options{}); res.Total != 0 {
+			t.Errorf("Expected removed post to be gone, got %d", res.Total)
+		}
+		if _, ok := ix.surface["dogs"]; ok {
+			t.Error("Expected prefix vocabulary to be cleaned up")
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Snippet sizes, in tokens.
+const (
+	snippetBefore = 8
+	snippetLength = 30
+)
+
+// snippet returns an HTML-escaped excerpt of the document around the first
+// matched word, with matched words wrapped in <mark> elements. Fields are tried
+// from last to first, so body text is preferred over titles.
+func snippet(d *indexedDoc, stems map[string]bool) string {
+	for fi := len(d.fields) - 1; fi >= 0; fi-- {
+		f := d.fields[fi]
+		first := -1
+		for pos, s := range f.stems {
+			if stems[s] {
+				first = pos
+				break
+			}
+		}
+		if first < 0 {
+			continue
+		}
+		start := max(0, first-snippetBefore)
+		end := min(len(f.tokens), start+snippetLength)
+		return highlight(f, start, end, stems)
+	}
+	// No field contains a match, for example after a prefix query on a title
+	// word; show the beginning of the last field.
+	if len(d.fields) == 0 {
+		return ""
+	}
+	f := d.fields[len(d.fields)-1]
+	return highlight(f, 0, min(len(f.tokens), snippetLength), stems)
+}
+
+func highlight(f indexedField, start int, end int, stems map[string]bool) string {
+	if start >= end {
+		return ""
+	}
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	text := f.Text
+	cursor := f.tokens[start].start
+	for pos := start; pos < end; pos++ {
+		t := f.tokens[pos]
+		b.WriteString(html.EscapeString(text[cursor:t.start]))
+		word := html.EscapeString(text[t.start:t.end])
+		if stems[f.stems[pos]] {
+			b.WriteString("<mark>" + word + "</mark>")
+		} else {
+			b.WriteString(word)
+		}
+		cursor = t.end
+	}
+	if end < len(f.tokens) {
+		b.WriteString(" …")
+	} else {
+		b.WriteString(html.EscapeString(text[cursor:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,182 @@
+package search
+
+import "strings"
+
+// stemSpanish implements a version of the Snowball Spanish stemmer that works on
+// accent-folded words. Pronoun suffixes attached to verbs are not removed.
+func stemSpanish(word string) string {
+	if len(word) <= 3 || !isASCIILower(strings.ReplaceAll(word, "ñ", "n")) {
+		return word
+	}
+	rv, r1, r2 := spanishRegions(word)
+
+	w, changed := spanishStandardSuffix(word, r1, r2)
+	if !changed {
+		w, changed = spanishYVerbSuffix(w, rv)
+	}
+	if !changed {
+		w = spanishVerbSuffix(w, rv)
+	}
+	return spanishResidualSuffix(w, rv)
+}
+
+func isSpanishVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	}
+	return false
+}
+
+// spanishRegions returns the start offsets of the RV, R1 and R2 regions.
+func spanishRegions(w string) (int, int, int) {
+	n := len(w)
+	rv := n
+	if n >= 2 {
+		switch {
+		case !isSpanishVowel(w[1]):
+			for i := 2; i < n; i++ {
+				if isSpanishVowel(w[i]) {
+					rv = i + 1
+					break
+				}
+			}
+		case isSpanishVowel(w[0]):
+			for i := 2; i < n; i++ {
+				if !isSpanishVowel(w[i]) {
+					rv = i + 1
+					break
+				}
+			}
+		default:
+			rv = 3
+		}
+	}
+	r1 := regionAfterVowelConsonant(w, 0)
+	r2 := regionAfterVowelConsonant(w, r1)
+	return min(rv, n), r1, r2
+}
+
+// regionAfterVowelConsonant returns the offset after the first non-vowel that
+// follows a vowel, searching from start.
+func regionAfterVowelConsonant(w string, start int) int {
+	for i := start + 1; i < len(w); i++ {
+		if !isSpanishVowel(w[i]) && isSpanishVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+// inRegion reports whether suffix ends w and starts at or after region.
+func inRegion(w string, suffix string, region int) bool {
+	return strings.HasSuffix(w, suffix) && len(w)-len(suffix) >= region
+}
+
+// longestSuffix returns the longest of suffixes that ends w, or "".
+func longestSuffix(w string, suffixes []string) string {
+	best := ""
+	for _, s := range suffixes {
+		if len(s) > len(best) && strings.HasSuffix(w, s) {
+			best = s
+		}
+	}
+	return best
+}
+
+var spanishStandardRules = []struct {
+	suffixes    []string
+	replacement string
+	useR1       bool
+}{
+	{[]string{"anza", "anzas", "ico", "ica", "icos", "icas", "ismo", "ismos", "able", "ables", "ible", "ibles",
+		"ista", "istas", "oso", "osa", "osos", "osas", "amiento", "amientos", "imiento", "imientos"}, "", false},
+	{[]string{"adora", "ador", "acion", "adoras", "adores", "aciones", "ante", "antes", "ancia", "ancias"}, "", false},
+	{[]string{"logia", "logias"}, "log", false},
+	{[]string{"ucion", "uciones"}, "u", false},
+	{[]string{"encia", "encias"}, "ente", false},
+	{[]string{"amente"}, "", true},
+	{[]string{"mente"}, "", false},
+	{[]string{"idad", "idades"}, "", false},
+	{[]string{"iva", "ivo", "ivas", "ivos"}, "", false},
+}
+
+func spanishStandardSuffix(w string, r1 int, r2 int) (string, bool) {
+	// Find the longest suffix across all rules, as Snowball does.
+	best, bestRule := "", -1
+	for i, rule := range spanishStandardRules {
+		if s := longestSuffix(w, rule.suffixes); len(s) > len(best) {
+			best, bestRule = s, i
+		}
+	}
+	if bestRule < 0 {
+		return w, false
+	}
+	rule := spanishStandardRules[bestRule]
+	region := r2
+	if rule.useR1 {
+		region = r1
+	}
+	if !inRegion(w, best, region) {
+		return w, false
+	}
+	return w[:len(w)-len(best)] + rule.replacement, true
+}
+
+var spanishYSuffixes = []string{"ya", "ye", "yan", "yen", "yeron", "yendo", "yo", "yas", "yes", "yais", "yamos"}
+
+func spanishYVerbSuffix(w string, rv int) (string, bool) {
+	s := longestSuffix(w, spanishYSuffixes)
+	if s == "" || !inRegion(w, s, rv) || !strings.HasSuffix(w[:len(w)-len(s)], "u") {
+		return w, false
+	}
+	return w[:len(w)-len(s)], true
+}
+
+var spanishVerbSuffixes = []string{
+	"arian", "arias", "aran", "aras", "ariais", "aria", "areis", "ariamos", "aremos", "ara", "are",
+	"erian", "erias", "eran", "eras", "eriais", "eria", "ereis", "eriamos", "eremos", "era", "ere",
+	"irian", "irias", "iran", "iras", "iriais", "iria", "ireis", "iriamos", "iremos", "ira", "ire",
+	"aba", "ada", "ida", "ia", "iera", "ad", "ed", "id", "ase", "iese", "aste", "iste", "an", "aban",
+	"ian", "ieran", "asen", "iesen", "aron", "ieron", "ado", "ido", "ando", "iendo", "io", "ar", "er",
+	"ir", "as", "abas", "adas", "idas", "ias", "ieras", "ases", "ieses", "is", "ais", "abais", "iais",
+	"arais", "ierais", "aseis", "ieseis", "asteis", "isteis", "ados", "idos", "amos", "abamos", "iamos",
+	"imos", "aramos", "ieramos", "iesemos", "asemos",
+}
+
+// spanishGuSuffixes also remove the u of a preceding "gu".
+var spanishGuSuffixes = []string{"en", "es", "eis", "emos"}
+
+func spanishVerbSuffix(w string, rv int) string {
+	verb := longestSuffix(w, spanishVerbSuffixes)
+	gu := longestSuffix(w, spanishGuSuffixes)
+	if len(gu) > len(verb) {
+		if !inRegion(w, gu, rv) {
+			return w
+		}
+		stem := w[:len(w)-len(gu)]
+		if strings.HasSuffix(stem, "gu") {
+			stem = stem[:len(stem)-1]
+		}
+		return stem
+	}
+	if verb != "" && inRegion(w, verb, rv) {
+		return w[:len(w)-len(verb)]
+	}
+	return w
+}
+
+func spanishResidualSuffix(w string, rv int) string {
+	for _, s := range []string{"os", "a", "o", "i"} {
+		if inRegion(w, s, rv) {
+			return w[:len(w)-len(s)]
+		}
+	}
+	if inRegion(w, "e", rv) {
+		w = w[:len(w)-1]
+		if strings.HasSuffix(w, "gu") && len(w)-1 >= rv {
+			w = w[:len(w)-1]
+		}
+	}
+	return w
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// token is a word found in a text, with its normalized form and byte offsets.
+type token struct {
+	// term is the lower-cased, accent-folded word
+	term string
+	// start and end delimit the word in the original text
+	start int
+	end   int
+}
+
+// tokenize splits text into words made of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{term: Fold(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: Fold(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// Fold lower-cases s and strips diacritics, so "Canción" and "cancion" compare equal.
+func Fold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Small stop word lists used only to guess the language of a document.
+var (
+	englishStopWords = toSet("the", "and", "of", "to", "in", "is", "it", "that", "for", "with", "this", "are", "was", "on", "be", "you", "not", "have", "from", "by")
+	spanishStopWords = toSet("el", "la", "de", "que", "y", "en", "los", "las", "del", "se", "por", "un", "una", "con", "para", "es", "al", "lo", "como", "mas")
+)
+
+// Language identifies the stemmer applied to a document.
+type Language string
+
+// Languages with a stemmer.
+const (
+	English Language = "en"
+	Spanish Language = "es"
+)
+
+// detectLanguage guesses whether tokens are Spanish or English by counting stop words.
+// English wins ties.
+func detectLanguage(tokens []token) Language {
+	en, es := 0, 0
+	for _, t := range tokens {
+		if englishStopWords[t.term] {
+			en++
+		}
+		if spanishStopWords[t.term] {
+			es++
+		}
+	}
+	if es > en {
+		return Spanish
+	}
+	return English
+}
+
+// stem reduces a folded term to its stem in the given language.
+func stem(term string, lang Language) string {
+	if lang == Spanish {
+		return stemSpanish(term)
+	}
+	return stemEnglish(term)
+}
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
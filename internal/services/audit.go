@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/audit"
+	"strconv"
+)
+
+// RecordAuditChanges records the resources changed by the events of b in the audit
+// Recorder of the context they were published with, giving audit entries their
+// before and after snapshots.
+func RecordAuditChanges(b *Bus) {
+	Subscribe(b, func(ctx context.Context, e DomainEvent) error {
+		if c, ok := auditChange(e); ok {
+			audit.Record(ctx, c)
+		}
+		return nil
+	})
+}
+
+// auditChange describes the resource changed by e, reporting false for events without snapshots.
+func auditChange(e DomainEvent) (audit.Change, bool) {
+	switch e := e.(type) {
+	case UserRegistered:
+		return audit.Change{Resource: userAggregate(e.User.ID), After: e.User}, true
+	case UserUpdated:
+		return audit.Change{Resource: userAggregate(e.User.ID), Before: e.Before, After: e.User}, true
+	case UserDeleted:
+		return audit.Change{Resource: userAggregate(e.User.ID), Before: e.User}, true
+	case PostCreated:
+		return audit.Change{Resource: postAggregate(e.Post.ID), After: e.Post}, true
+	case PostUpdated:
+		return audit.Change{Resource: postAggregate(e.Post.ID), Before: e.Before, After: e.Post}, true
+	case PostDeleted:
+		return audit.Change{Resource: postAggregate(e.Post.ID), Before: e.Post}, true
+	case PostReacted:
+		return audit.Change{Resource: postAggregate(e.Reaction.PostID), After: e.Reaction}, true
+	case CommentCreated:
+		return audit.Change{Resource: "comment:" + strconv.Itoa(e.Comment.ID), After: e.Comment}, true
+	case CommentDeleted:
+		return audit.Change{Resource: "comment:" + strconv.Itoa(e.Comment.ID), Before: e.Comment}, true
+	}
+	return audit.Change{}, false
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"example/api/internal/audit"
+	"example/api/internal/models"
+	"testing"
+)
+
+func TestRecordAuditChanges(t *testing.T) {
+	bus := NewBus()
+	users := NewUserService(bus)
+	posts := NewPostService(bus)
+	RecordAuditChanges(bus)
+
+	userID, _ := users.Register(context.Background(), "Ana", "ana@example.com")
+
+	ctx, rec := audit.WithRecorder(context.Background())
+	postID, _ := posts.Create(ctx, "Title", "Content", userID)
+	posts.Update(ctx, postID, userID, "New title", "Content")
+	posts.Delete(ctx, postID)
+
+	changes := rec.Changes()
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %d", len(changes))
+	}
+	for _, c := range changes {
+		if c.Resource != postAggregate(postID) {
+			t.Errorf("Expected changes to %s, got %s", postAggregate(postID), c.Resource)
+		}
+	}
+	if changes[0].Before != nil || changes[2].After != nil {
+		t.Errorf("Expected no snapshot before the creation or after the deletion, got %+v", changes)
+	}
+	before, after, err := audit.Snapshots(changes[1:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var old, updated models.Post
+	json.Unmarshal(before, &old)
+	json.Unmarshal(after, &updated)
+	if old.Title != "Title" || updated.Title != "New title" {
+		t.Errorf("Expected the update to go from Title to New title, got %q and %q", old.Title, updated.Title)
+	}
+}
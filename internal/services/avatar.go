@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"example/api/internal/blob"
+	"example/api/internal/models"
+	"fmt"
+	"image"
+	_ "image/gif"  // registers the GIF decoder
+	_ "image/jpeg" // registers the JPEG decoder
+	"image/png"
+	"io"
+	"slices"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+)
+
+// AvatarSizes are the square thumbnail sizes generated for every avatar, in pixels.
+var AvatarSizes = []int{64, 128, 256}
+
+// DefaultMaxAvatarBytes is the default upload size limit for avatars.
+const DefaultMaxAvatarBytes = 5 << 20
+
+// MaxAvatarDimension bounds the width and height of uploaded images, so small files
+// cannot decode into huge bitmaps.
+const MaxAvatarDimension = 4096
+
+// Avatar upload errors.
+var (
+	ErrAvatarTooLarge   = errors.New("avatar image is too large")
+	ErrUnsupportedImage = errors.New("unsupported image format: use JPEG, PNG, GIF or WebP")
+	ErrAvatarNotFound   = errors.New("avatar not found")
+)
+
+// imageSignatures maps the magic bytes at the start of a file to the image format they identify.
+var imageSignatures = []struct {
+	format string
+	prefix []byte
+	// offset optionally matches a second marker further into the file
+	offset int
+	marker []byte
+}{
+	{format: "jpeg", prefix: []byte{0xFF, 0xD8, 0xFF}},
+	{format: "png", prefix: []byte("\x89PNG\r\n\x1a\n")},
+	{format: "gif", prefix: []byte("GIF87a")},
+	{format: "gif", prefix: []byte("GIF89a")},
+	{format: "webp", prefix: []byte("RIFF"), offset: 8, marker: []byte("WEBP")},
+}
+
+// AvatarService validates uploaded profile pictures, resizes them to the standard
+// thumbnail sizes and keeps the thumbnails in a blob store.
+type AvatarService struct {
+	users    *UserService
+	store    blob.Store
+	maxBytes int64
+	now      func() time.Time
+}
+
+// NewAvatarService creates an AvatarService that stores thumbnails in store and accepts
+// uploads of up to maxBytes. A user's thumbnails are removed when the user is deleted.
+func NewAvatarService(users *UserService, store blob.Store, maxBytes int64) *AvatarService {
+	s := &AvatarService{
+		users:    users,
+		store:    store,
+		maxBytes: maxBytes,
+		now:      time.Now,
+	}
+	Subscribe(users.bus, s.userDeleted)
+	return s
+}
+
+// MaxBytes returns the upload size limit.
+func (s *AvatarService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Upload replaces a user's avatar with the image read from r.
+// Returns the updated user, ErrUserNotFound, ErrAvatarTooLarge, ErrUnsupportedImage,
+// or an error if the image cannot be decoded.
+func (s *AvatarService) Upload(ctx context.Context, userID int, r io.Reader) (models.User, error) {
+	ctx, span := startSpan(ctx, "AvatarService.Upload")
+	defer span.End()
+
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		return models.User{}, fail(span, err)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.maxBytes+1))
+	if err != nil {
+		return models.User{}, fail(span, err)
+	}
+	if int64(len(data)) > s.maxBytes {
+		return models.User{}, fail(span, ErrAvatarTooLarge)
+	}
+	img, err := decodeImage(data)
+	if err != nil {
+		return models.User{}, fail(span, err)
+	}
+
+	now := s.now()
+	avatar := &models.Avatar{URLs: make(map[int]string, len(AvatarSizes)), UpdatedAt: now}
+	for _, size := range AvatarSizes {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, thumbnail(img, size)); err != nil {
+			return models.User{}, fail(span, err)
+		}
+		put := startStorageSpan(ctx, "avatars", "put")
+		err := s.store.Put(ctx, avatarKey(userID, size), &buf)
+		put.End()
+		if err != nil {
+			return models.User{}, fail(span, err)
+		}
+		avatar.URLs[size] = fmt.Sprintf("/users/%d/avatar/%d?v=%d", userID, size, now.Unix())
+	}
+
+	user, err := s.users.SetAvatar(ctx, userID, avatar)
+	if err != nil {
+		return models.User{}, fail(span, err)
+	}
+	return user, nil
+}
+
+// Open returns the PNG thumbnail of the given size for a user's avatar.
+// Returns ErrAvatarNotFound if the size is not one of AvatarSizes or the user has no avatar.
+func (s *AvatarService) Open(ctx context.Context, userID int, size int) (io.ReadCloser, error) {
+	ctx, span := startSpan(ctx, "AvatarService.Open")
+	defer span.End()
+
+	if !slices.Contains(AvatarSizes, size) {
+		return nil, fail(span, ErrAvatarNotFound)
+	}
+	get := startStorageSpan(ctx, "avatars", "get")
+	defer get.End()
+	rc, err := s.store.Get(ctx, avatarKey(userID, size))
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, fail(span, ErrAvatarNotFound)
+	}
+	if err != nil {
+		return nil, fail(span, err)
+	}
+	return rc, nil
+}
+
+// userDeleted removes a deleted user's thumbnails.
+func (s *AvatarService) userDeleted(ctx context.Context, e UserDeleted) error {
+	var errs []error
+	for _, size := range AvatarSizes {
+		if err := s.store.Delete(ctx, avatarKey(e.User.ID, size)); err != nil {
+			errs = append(errs, fmt.Errorf("deleting avatar of user %d: %w", e.User.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func avatarKey(userID int, size int) string {
+	return fmt.Sprintf("avatars/%d/%d.png", userID, size)
+}
+
+// sniffImage identifies the image format from the file's magic bytes, or returns "".
+func sniffImage(data []byte) string {
+	for _, sig := range imageSignatures {
+		if !bytes.HasPrefix(data, sig.prefix) {
+			continue
+		}
+		if sig.marker != nil && !bytes.HasPrefix(data[min(sig.offset, len(data)):], sig.marker) {
+			continue
+		}
+		return sig.format
+	}
+	return ""
+}
+
+// decodeImage checks the file's magic bytes and dimensions before decoding it.
+func decodeImage(data []byte) (image.Image, error) {
+	format := sniffImage(data)
+	if format == "" {
+		return nil, ErrUnsupportedImage
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s image: %w", format, err)
+	}
+	if cfg.Width == 0 || cfg.Height == 0 {
+		return nil, fmt.Errorf("invalid %s image: empty image", format)
+	}
+	if cfg.Width > MaxAvatarDimension || cfg.Height > MaxAvatarDimension {
+		return nil, fmt.Errorf("%w: images must be at most %dx%d pixels", ErrAvatarTooLarge, MaxAvatarDimension, MaxAvatarDimension)
+	}
+	img, decoded, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s image: %w", format, err)
+	}
+	if decoded != format {
+		return nil, ErrUnsupportedImage
+	}
+	return img, nil
+}
+
+// thumbnail crops the centre square of img and scales it to size×size pixels.
+func thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		b.Min.X+(b.Dx()-side)/2,
+		b.Min.Y+(b.Dy()-side)/2,
+	))
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+	return dst
+}
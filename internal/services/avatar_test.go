@@ -0,0 +1,96 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"example/api/internal/blob"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Encoding test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestAvatarService(t *testing.T) {
+	// Initialize services with an in-memory blob store
+	ctx := context.Background()
+	users := NewUserService(NewBus())
+	store := blob.NewMemory()
+	s := NewAvatarService(users, store, 64<<10)
+	id, _ := users.Register(ctx, "Alice", "alice@example.com")
+
+	t.Run("Upload generates thumbnails", func(t *testing.T) {
+		user, err := s.Upload(ctx, id, bytes.NewReader(encodePNG(t, 600, 400)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if user.Avatar == nil || len(user.Avatar.URLs) != len(AvatarSizes) {
+			t.Fatalf("Expected %d avatar URLs, got %+v", len(AvatarSizes), user.Avatar)
+		}
+		for _, size := range AvatarSizes {
+			rc, err := s.Open(ctx, id, size)
+			if err != nil {
+				t.Fatalf("Expected thumbnail %d, got %v", size, err)
+			}
+			img, err := png.Decode(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatalf("Expected a PNG thumbnail, got %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+				t.Errorf("Expected %dx%d thumbnail, got %dx%d", size, size, b.Dx(), b.Dy())
+			}
+		}
+	})
+
+	t.Run("Upload validates magic bytes", func(t *testing.T) {
+		data := append([]byte("<svg "), encodePNG(t, 10, 10)...)
+		if _, err := s.Upload(ctx, id, bytes.NewReader(data)); !errors.Is(err, ErrUnsupportedImage) {
+			t.Errorf("Expected ErrUnsupportedImage, got %v", err)
+		}
+		truncated := encodePNG(t, 10, 10)[:20]
+		if _, err := s.Upload(ctx, id, bytes.NewReader(truncated)); err == nil || errors.Is(err, ErrUnsupportedImage) {
+			t.Errorf("Expected a decoding error, got %v", err)
+		}
+	})
+
+	t.Run("Upload enforces size limits", func(t *testing.T) {
+		big := make([]byte, 65<<10)
+		copy(big, encodePNG(t, 10, 10))
+		if _, err := s.Upload(ctx, id, bytes.NewReader(big)); !errors.Is(err, ErrAvatarTooLarge) {
+			t.Errorf("Expected ErrAvatarTooLarge, got %v", err)
+		}
+		if _, err := s.Upload(ctx, id, bytes.NewReader(encodePNG(t, MaxAvatarDimension+1, 1))); !errors.Is(err, ErrAvatarTooLarge) {
+			t.Errorf("Expected ErrAvatarTooLarge for oversized dimensions, got %v", err)
+		}
+	})
+
+	t.Run("Open rejects unknown sizes", func(t *testing.T) {
+		if _, err := s.Open(ctx, id, 100); err != ErrAvatarNotFound {
+			t.Errorf("Expected ErrAvatarNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Deleting the user removes thumbnails", func(t *testing.T) {
+		users.Delete(ctx, id)
+		if _, err := s.Open(ctx, id, AvatarSizes[0]); err != ErrAvatarNotFound {
+			t.Errorf("Expected ErrAvatarNotFound, got %v", err)
+		}
+		if _, err := s.Upload(ctx, id, bytes.NewReader(encodePNG(t, 10, 10))); err != ErrUserNotFound {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
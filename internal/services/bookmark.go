@@ -0,0 +1,444 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Bookmark page sizes.
+const (
+	DefaultBookmarkLimit = 20
+	MaxBookmarkLimit     = 100
+)
+
+// Reading list limits.
+const (
+	MaxReadingListNameLength = 100
+	MaxReadingListPosts      = 500
+)
+
+// Reading list errors.
+var (
+	ErrReadingListNotFound = errors.New("reading list not found")
+	ErrReadingListExists   = errors.New("a reading list with that name already exists")
+)
+
+// BookmarkService manages the posts users save for later, either as bookmarks or
+// in named reading lists. Entries pointing to a deleted post are removed along with it,
+// and posts that are no longer visible to their owner are left out when reading.
+type BookmarkService struct {
+	mu sync.RWMutex
+	// bookmarks maps a user to their bookmarks, most recently saved first
+	bookmarks map[int][]timelineEntry
+	lists     map[int]models.ReadingList
+	nextID    int
+	posts     *PostService
+	now       func() time.Time
+}
+
+// NewBookmarkService creates a BookmarkService and subscribes to post and user events so entries are
+// removed when their post or owner is deleted.
+func NewBookmarkService(posts *PostService, users *UserService) *BookmarkService {
+	s := &BookmarkService{
+		bookmarks: make(map[int][]timelineEntry),
+		lists:     make(map[int]models.ReadingList),
+		nextID:    1,
+		posts:     posts,
+		now:       time.Now,
+	}
+	Subscribe(posts.bus, s.postDeleted)
+	Subscribe(users.bus, s.userDeleted)
+	return s
+}
+
+// Bookmark saves a post for userID. Bookmarking a post twice keeps the original time.
+// Returns ErrPostNotFound if the post doesn't exist or isn't visible to the user.
+func (s *BookmarkService) Bookmark(ctx context.Context, userID int, postID int) (models.Bookmark, error) {
+	ctx, span := startSpan(ctx, "BookmarkService.Bookmark")
+	defer span.End()
+
+	post, err := s.visiblePost(ctx, userID, postID)
+	if err != nil {
+		return models.Bookmark{}, fail(span, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := bookmarkIndex(s.bookmarks[userID], postID); i >= 0 {
+		return models.Bookmark{Post: post, SavedAt: s.bookmarks[userID][i].at}, nil
+	}
+	insert := startStorageSpan(ctx, "bookmarks", "insert")
+	defer insert.End()
+	e := timelineEntry{at: s.now(), postID: postID}
+	bookmarks := s.bookmarks[userID]
+	i := sort.Search(len(bookmarks), func(i int) bool { return e.newer(bookmarks[i]) })
+	s.bookmarks[userID] = slices.Insert(bookmarks, i, e)
+	return models.Bookmark{Post: post, SavedAt: e.at}, nil
+}
+
+// Unbookmark removes a post from userID's bookmarks.
+// Returns true if the post was bookmarked, false otherwise.
+func (s *BookmarkService) Unbookmark(ctx context.Context, userID int, postID int) bool {
+	ctx, span := startSpan(ctx, "BookmarkService.Unbookmark")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := bookmarkIndex(s.bookmarks[userID], postID)
+	if i < 0 {
+		return false
+	}
+	del := startStorageSpan(ctx, "bookmarks", "delete")
+	defer del.End()
+	s.removeBookmark(userID, i)
+	return true
+}
+
+// Bookmarks returns a page of userID's bookmarks, most recently saved first. An empty
+// cursor starts at the newest bookmark; otherwise it must be a NextCursor from a previous page.
+// limit defaults to DefaultBookmarkLimit and is capped at MaxBookmarkLimit.
+// Returns ErrInvalidCursor if the cursor is malformed.
+func (s *BookmarkService) Bookmarks(ctx context.Context, userID int, cursor string, limit int) (models.BookmarkPage, error) {
+	ctx, span := startSpan(ctx, "BookmarkService.Bookmarks")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = DefaultBookmarkLimit
+	}
+	limit = min(limit, MaxBookmarkLimit)
+
+	s.mu.RLock()
+	scan := startStorageSpan(ctx, "bookmarks", "scan")
+	bookmarks := s.bookmarks[userID]
+	i := 0
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			scan.End()
+			s.mu.RUnlock()
+			return models.BookmarkPage{}, fail(span, err)
+		}
+		i = sort.Search(len(bookmarks), func(i int) bool { return after.newer(bookmarks[i]) })
+	}
+	// Take one extra entry to learn whether another page follows.
+	entries := slices.Clone(bookmarks[i:min(i+limit+1, len(bookmarks))])
+	scan.End()
+	s.mu.RUnlock()
+
+	page := models.BookmarkPage{Bookmarks: make([]models.Bookmark, 0, min(limit, len(entries)))}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = encodeCursor(entries[limit-1])
+	}
+	for _, e := range entries {
+		// Skip posts unpublished since they were bookmarked, or deleted since the bookmarks were read.
+		if post, err := s.visiblePost(ctx, userID, e.postID); err == nil {
+			page.Bookmarks = append(page.Bookmarks, models.Bookmark{Post: post, SavedAt: e.at})
+		}
+	}
+	return page, nil
+}
+
+// CreateList creates an empty reading list for userID.
+// Returns an error if the name is empty or too long, or ErrReadingListExists.
+func (s *BookmarkService) CreateList(ctx context.Context, userID int, name string) (models.ReadingList, error) {
+	ctx, span := startSpan(ctx, "BookmarkService.CreateList")
+	defer span.End()
+
+	name, err := validateListName(name)
+	if err != nil {
+		return models.ReadingList{}, fail(span, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nameTaken(userID, name, 0) {
+		return models.ReadingList{}, fail(span, ErrReadingListExists)
+	}
+	insert := startStorageSpan(ctx, "reading_lists", "insert")
+	defer insert.End()
+	now := s.now()
+	list := models.ReadingList{
+		ID:        s.nextID,
+		UserID:    userID,
+		Name:      name,
+		PostIDs:   []int{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.lists[list.ID] = list
+	s.nextID++
+	return list, nil
+}
+
+// Lists returns userID's reading lists, oldest first.
+func (s *BookmarkService) Lists(ctx context.Context, userID int) []models.ReadingList {
+	ctx, span := startSpan(ctx, "BookmarkService.Lists")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "reading_lists", "scan")
+	defer scan.End()
+	lists := make([]models.ReadingList, 0)
+	for _, list := range s.lists {
+		if list.UserID == userID {
+			lists = append(lists, list)
+		}
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].ID < lists[j].ID })
+	return lists
+}
+
+// FindList returns one of userID's reading lists.
+// Returns ErrReadingListNotFound if the list doesn't exist or belongs to another user.
+func (s *BookmarkService) FindList(ctx context.Context, userID int, listID int) (models.ReadingList, error) {
+	ctx, span := startSpan(ctx, "BookmarkService.FindList")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.list(ctx, userID, listID)
+	if !ok {
+		return models.ReadingList{}, fail(span, ErrReadingListNotFound)
+	}
+	return list, nil
+}
+
+// ListPosts returns the posts in one of userID's reading lists, in list order.
+// Posts that are no longer visible to the user are left out.
+// Returns ErrReadingListNotFound if the list doesn't exist or belongs to another user.
+func (s *BookmarkService) ListPosts(ctx context.Context, userID int, listID int) ([]models.Post, error) {
+	ctx, span := startSpan(ctx, "BookmarkService.ListPosts")
+	defer span.End()
+
+	list, err := s.FindList(ctx, userID, listID)
+	if err != nil {
+		return nil, fail(span, err)
+	}
+	posts := make([]models.Post, 0, len(list.PostIDs))
+	for _, id := range list.PostIDs {
+		if post, err := s.visiblePost(ctx, userID, id); err == nil {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+// RenameList changes the name of one of userID's reading lists.
+// Returns an error if the name is empty or too long, ErrReadingListExists, or ErrReadingListNotFound.
+func (s *BookmarkService) RenameList(ctx context.Context, userID int, listID int, name string) (models.ReadingList, error) {
+	ctx, span := startSpan(ctx, "BookmarkService.RenameList")
+	defer span.End()
+
+	name, err := validateListName(name)
+	if err != nil {
+		return models.ReadingList{}, fail(span, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.list(ctx, userID, listID)
+	if !ok {
+		return models.ReadingList{}, fail(span, ErrReadingListNotFound)
+	}
+	if s.nameTaken(userID, name, listID) {
+		return models.ReadingList{}, fail(span, ErrReadingListExists)
+	}
+	list.Name = name
+	return s.saveList(ctx, list), nil
+}
+
+// DeleteList deletes one of userID's reading lists.
+// Returns true if the list was found and deleted, false otherwise.
+func (s *BookmarkService) DeleteList(ctx context.Context, userID int, listID int) bool {
+	ctx, span := startSpan(ctx, "BookmarkService.DeleteList")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.list(ctx, userID, listID); !ok {
+		return false
+	}
+	del := startStorageSpan(ctx, "reading_lists", "delete")
+	defer del.End()
+	delete(s.lists, listID)
+	return true
+}
+
+// AddToList puts a post in one of userID's reading lists at position, counted from 0.
+// A negative position or one past the end appends the post. A post already in the list
+// is moved to the new position.
+// Returns ErrReadingListNotFound, ErrPostNotFound if the post isn't visible to the user,
+// or an error if the list is full.
+func (s *BookmarkService) AddToList(ctx context.Context, userID int, listID int, postID int, position int) (models.ReadingList, error) {
+	ctx, span := startSpan(ctx, "BookmarkService.AddToList")
+	defer span.End()
+
+	if _, err := s.visiblePost(ctx, userID, postID); err != nil {
+		return models.ReadingList{}, fail(span, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.list(ctx, userID, listID)
+	if !ok {
+		return models.ReadingList{}, fail(span, ErrReadingListNotFound)
+	}
+	// The IDs are copied because earlier copies of the list share the slice.
+	ids := slices.DeleteFunc(slices.Clone(list.PostIDs), func(id int) bool { return id == postID })
+	if len(ids) >= MaxReadingListPosts {
+		return models.ReadingList{}, fail(span, fmt.Errorf("reading lists can have at most %d posts", MaxReadingListPosts))
+	}
+	if position < 0 || position > len(ids) {
+		position = len(ids)
+	}
+	list.PostIDs = slices.Insert(ids, position, postID)
+	return s.saveList(ctx, list), nil
+}
+
+// RemoveFromList takes a post out of one of userID's reading lists.
+// Returns ErrReadingListNotFound, or ErrPostNotFound if the post isn't in the list.
+func (s *BookmarkService) RemoveFromList(ctx context.Context, userID int, listID int, postID int) (models.ReadingList, error) {
+	ctx, span := startSpan(ctx, "BookmarkService.RemoveFromList")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.list(ctx, userID, listID)
+	if !ok {
+		return models.ReadingList{}, fail(span, ErrReadingListNotFound)
+	}
+	if !slices.Contains(list.PostIDs, postID) {
+		return models.ReadingList{}, fail(span, ErrPostNotFound)
+	}
+	list.PostIDs = slices.DeleteFunc(slices.Clone(list.PostIDs), func(id int) bool { return id == postID })
+	return s.saveList(ctx, list), nil
+}
+
+// visiblePost returns a post if it exists and userID may see it, or ErrPostNotFound.
+func (s *BookmarkService) visiblePost(ctx context.Context, userID int, postID int) (models.Post, error) {
+	post, err := s.posts.FindByID(ctx, postID)
+	if err != nil || !post.VisibleTo(userID) {
+		return models.Post{}, ErrPostNotFound
+	}
+	return post, nil
+}
+
+// list returns the reading list with the given ID if userID owns it. Callers must hold s.mu.
+func (s *BookmarkService) list(ctx context.Context, userID int, listID int) (models.ReadingList, bool) {
+	get := startStorageSpan(ctx, "reading_lists", "get")
+	defer get.End()
+	list, ok := s.lists[listID]
+	return list, ok && list.UserID == userID
+}
+
+// saveList stores a changed reading list. Callers must hold s.mu for writing.
+func (s *BookmarkService) saveList(ctx context.Context, list models.ReadingList) models.ReadingList {
+	update := startStorageSpan(ctx, "reading_lists", "update")
+	defer update.End()
+	list.UpdatedAt = s.now()
+	s.lists[list.ID] = list
+	return list
+}
+
+// nameTaken reports whether userID has a list other than exceptID with the given name,
+// ignoring case. Callers must hold s.mu.
+func (s *BookmarkService) nameTaken(userID int, name string, exceptID int) bool {
+	for _, list := range s.lists {
+		if list.UserID == userID && list.ID != exceptID && strings.EqualFold(list.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeBookmark drops the i-th bookmark of userID. Callers must hold s.mu for writing.
+func (s *BookmarkService) removeBookmark(userID int, i int) {
+	bookmarks := slices.Delete(s.bookmarks[userID], i, i+1)
+	if len(bookmarks) == 0 {
+		delete(s.bookmarks, userID)
+		return
+	}
+	s.bookmarks[userID] = bookmarks
+}
+
+// postDeleted removes a deleted post from every bookmark and reading list.
+func (s *BookmarkService) postDeleted(ctx context.Context, e PostDeleted) error {
+	postID := e.Post.ID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	del := startStorageSpan(ctx, "bookmarks", "delete")
+	for userID, bookmarks := range s.bookmarks {
+		if i := bookmarkIndex(bookmarks, postID); i >= 0 {
+			s.removeBookmark(userID, i)
+		}
+	}
+	del.End()
+
+	now := s.now()
+	update := startStorageSpan(ctx, "reading_lists", "update")
+	defer update.End()
+	for listID, list := range s.lists {
+		if slices.Contains(list.PostIDs, postID) {
+			list.PostIDs = slices.DeleteFunc(slices.Clone(list.PostIDs), func(id int) bool { return id == postID })
+			list.UpdatedAt = now
+			s.lists[listID] = list
+		}
+	}
+	return nil
+}
+
+// userDeleted removes the bookmarks and reading lists of a deleted user.
+func (s *BookmarkService) userDeleted(ctx context.Context, e UserDeleted) error {
+	userID := e.User.ID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	del := startStorageSpan(ctx, "reading_lists", "delete")
+	defer del.End()
+	delete(s.bookmarks, userID)
+	for id, list := range s.lists {
+		if list.UserID == userID {
+			delete(s.lists, id)
+		}
+	}
+	return nil
+}
+
+// bookmarkIndex returns the position of postID in bookmarks, or -1.
+func bookmarkIndex(bookmarks []timelineEntry, postID int) int {
+	return slices.IndexFunc(bookmarks, func(e timelineEntry) bool { return e.postID == postID })
+}
+
+// validateListName trims a reading list name and checks its length.
+func validateListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if utf8.RuneCountInString(name) > MaxReadingListNameLength {
+		return "", fmt.Errorf("name must be at most %d characters", MaxReadingListNameLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBookmarkService(t *testing.T) {
+	// Initialize services with a controllable clock
+	ctx := context.Background()
+	users := NewUserService(NewBus())
+	posts := NewPostService(NewBus())
+	s := NewBookmarkService(posts, users)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	reader, _ := users.Register(ctx, "Reader", "reader@example.com")
+	author, _ := users.Register(ctx, "Author", "author@example.com")
+	var ids []int
+	for i := range 5 {
+		id, _ := posts.Create(ctx, fmt.Sprintf("Post %d", i), "Content", author)
+		ids = append(ids, id)
+	}
+	draft, _ := posts.Create(ctx, "Draft", "Content", author, WithStatus(models.PostDraft))
+
+	bookmarked := func(page models.BookmarkPage) []int {
+		out := make([]int, 0, len(page.Bookmarks))
+		for _, b := range page.Bookmarks {
+			out = append(out, b.Post.ID)
+		}
+		return out
+	}
+
+	t.Run("Bookmark is idempotent", func(t *testing.T) {
+		first, err := s.Bookmark(ctx, reader, ids[0])
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		now = now.Add(time.Minute)
+		again, _ := s.Bookmark(ctx, reader, ids[0])
+		if !again.SavedAt.Equal(first.SavedAt) {
+			t.Errorf("Expected saved time %v to be kept, got %v", first.SavedAt, again.SavedAt)
+		}
+	})
+
+	t.Run("Bookmark rejects posts the user cannot see", func(t *testing.T) {
+		if _, err := s.Bookmark(ctx, reader, draft); err != ErrPostNotFound {
+			t.Errorf("Expected ErrPostNotFound for another user's draft, got %v", err)
+		}
+		if _, err := s.Bookmark(ctx, author, draft); err != nil {
+			t.Errorf("Expected authors to bookmark their own drafts, got %v", err)
+		}
+	})
+
+	t.Run("Pages through bookmarks most recent first", func(t *testing.T) {
+		for _, id := range ids[1:] {
+			now = now.Add(time.Minute)
+			s.Bookmark(ctx, reader, id)
+		}
+		var got []int
+		cursor := ""
+		for {
+			page, err := s.Bookmarks(ctx, reader, cursor, 2)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			got = append(got, bookmarked(page)...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		expected := []int{ids[4], ids[3], ids[2], ids[1], ids[0]}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+		if _, err := s.Bookmarks(ctx, reader, "not a cursor", 2); err != ErrInvalidCursor {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
+		}
+	})
+
+	t.Run("Unbookmark", func(t *testing.T) {
+		if !s.Unbookmark(ctx, reader, ids[4]) {
+			t.Error("Expected bookmark to be removed")
+		}
+		if s.Unbookmark(ctx, reader, ids[4]) {
+			t.Error("Expected second removal to report false")
+		}
+	})
+
+	t.Run("Reading lists keep posts in order", func(t *testing.T) {
+		list, err := s.CreateList(ctx, reader, "  Weekend  ")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if list.Name != "Weekend" {
+			t.Errorf("Expected trimmed name, got %q", list.Name)
+		}
+		if _, err := s.CreateList(ctx, reader, "weekend"); err != ErrReadingListExists {
+			t.Errorf("Expected ErrReadingListExists, got %v", err)
+		}
+		if _, err := s.CreateList(ctx, author, "Weekend"); err != nil {
+			t.Errorf("Expected other users to reuse the name, got %v", err)
+		}
+
+		s.AddToList(ctx, reader, list.ID, ids[0], -1)
+		s.AddToList(ctx, reader, list.ID, ids[1], -1)
+		before, _ := s.AddToList(ctx, reader, list.ID, ids[2], 0)
+		list, _ = s.AddToList(ctx, reader, list.ID, ids[1], 0)
+		expected := []int{ids[1], ids[2], ids[0]}
+		if !reflect.DeepEqual(list.PostIDs, expected) {
+			t.Errorf("Expected %v, got %v", expected, list.PostIDs)
+		}
+		if !reflect.DeepEqual(before.PostIDs, []int{ids[2], ids[0], ids[1]}) {
+			t.Errorf("Expected earlier copy to be unchanged, got %v", before.PostIDs)
+		}
+
+		list, _ = s.RemoveFromList(ctx, reader, list.ID, ids[2])
+		if !reflect.DeepEqual(list.PostIDs, []int{ids[1], ids[0]}) {
+			t.Errorf("Expected %v, got %v", []int{ids[1], ids[0]}, list.PostIDs)
+		}
+		if _, err := s.RemoveFromList(ctx, reader, list.ID, ids[2]); err != ErrPostNotFound {
+			t.Errorf("Expected ErrPostNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Reading lists are private", func(t *testing.T) {
+		list := s.Lists(ctx, reader)[0]
+		if _, err := s.FindList(ctx, author, list.ID); err != ErrReadingListNotFound {
+			t.Errorf("Expected ErrReadingListNotFound, got %v", err)
+		}
+		if _, err := s.AddToList(ctx, author, list.ID, ids[0], -1); err != ErrReadingListNotFound {
+			t.Errorf("Expected ErrReadingListNotFound, got %v", err)
+		}
+		if s.DeleteList(ctx, author, list.ID) {
+			t.Error("Expected other users not to delete the list")
+		}
+	})
+
+	t.Run("Deleted posts are removed everywhere", func(t *testing.T) {
+		posts.Delete(ctx, ids[1])
+
+		page, _ := s.Bookmarks(ctx, reader, "", 10)
+		expected := []int{ids[3], ids[2], ids[0]}
+		if got := bookmarked(page); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+		list := s.Lists(ctx, reader)[0]
+		if !reflect.DeepEqual(list.PostIDs, []int{ids[0]}) {
+			t.Errorf("Expected %v, got %v", []int{ids[0]}, list.PostIDs)
+		}
+	})
+
+	t.Run("Hidden posts are left out when reading", func(t *testing.T) {
+		posts.Archive(ctx, ids[0])
+		list := s.Lists(ctx, reader)[0]
+		listed, _ := s.ListPosts(ctx, reader, list.ID)
+		if len(listed) != 0 {
+			t.Errorf("Expected archived post to be left out, got %v", listed)
+		}
+		page, _ := s.Bookmarks(ctx, reader, "", 10)
+		if got := bookmarked(page); !reflect.DeepEqual(got, []int{ids[3], ids[2]}) {
+			t.Errorf("Expected %v, got %v", []int{ids[3], ids[2]}, got)
+		}
+	})
+
+	t.Run("Deleted users lose their lists", func(t *testing.T) {
+		users.Delete(ctx, reader)
+		if lists := s.Lists(ctx, reader); len(lists) != 0 {
+			t.Errorf("Expected no lists, got %v", lists)
+		}
+		page, _ := s.Bookmarks(ctx, reader, "", 10)
+		if len(page.Bookmarks) != 0 {
+			t.Errorf("Expected no bookmarks, got %v", page.Bookmarks)
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"runtime"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DomainEvent is a change made by a service, published on its Bus after the change succeeds.
+type DomainEvent interface {
+	// EventName names the kind of change, such as "post.created"
+	EventName() string
+	// AggregateID identifies the entity the event belongs to, such as "post:5".
+	// Asynchronous subscribers receive the events of an aggregate in publication order.
+	AggregateID() string
+}
+
+// Bus delivers domain events to the subscribers interested in them.
+//
+// Synchronous subscribers run in the publisher's goroutine before Publish returns, in the
+// order they subscribed, so they can keep derived data such as comments or search results
+// consistent with the change. Asynchronous subscribers run on background workers and suit
+// slow side effects such as calling other systems: the events of one aggregate are handled one
+// at a time and in order, while different aggregates proceed in parallel.
+//
+// A subscriber that fails or panics is reported to the OnError function and does not
+// affect the publisher or the other subscribers.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+	onError     func(ctx context.Context, e DomainEvent, err error)
+	shards      []*busShard
+	startOnce   sync.Once
+	closed      bool
+
+	// pending counts the queued asynchronous deliveries; idle is signalled when it reaches zero
+	pending int
+	idle    *sync.Cond
+}
+
+type subscriber struct {
+	async bool
+	match func(e DomainEvent) bool
+	fn    func(ctx context.Context, e DomainEvent) error
+}
+
+// delivery is an event waiting for its asynchronous subscribers.
+type delivery struct {
+	ctx         context.Context
+	event       DomainEvent
+	subscribers []*subscriber
+}
+
+// busShard runs the asynchronous deliveries of the aggregates hashed to it, in order.
+type busShard struct {
+	mu     sync.Mutex
+	ready  *sync.Cond
+	queue  []delivery
+	closed bool
+}
+
+// NewBus creates a Bus. Its workers start with the first asynchronous subscriber.
+func NewBus() *Bus {
+	b := &Bus{onError: logSubscriberError}
+	b.idle = sync.NewCond(&b.mu)
+	return b
+}
+
+// Subscribe registers fn to run synchronously for every event of type E published on b.
+// Subscribing to DomainEvent itself receives every event.
+func Subscribe[E DomainEvent](b *Bus, fn func(ctx context.Context, e E) error) {
+	b.subscribe(newSubscriber(false, fn))
+}
+
+// SubscribeAsync registers fn to run in the background for every event of type E published on b.
+// fn receives the publisher's context without its cancellation, so it can outlive the request.
+func SubscribeAsync[E DomainEvent](b *Bus, fn func(ctx context.Context, e E) error) {
+	b.startOnce.Do(b.start)
+	b.subscribe(newSubscriber(true, fn))
+}
+
+func newSubscriber[E DomainEvent](async bool, fn func(ctx context.Context, e E) error) *subscriber {
+	return &subscriber{
+		async: async,
+		match: func(e DomainEvent) bool {
+			_, ok := e.(E)
+			return ok
+		},
+		fn: func(ctx context.Context, e DomainEvent) error {
+			return fn(ctx, e.(E))
+		},
+	}
+}
+
+func (b *Bus) subscribe(s *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Copy on write, so Publish can iterate without holding the lock.
+	b.subscribers = append(slices.Clip(b.subscribers), s)
+}
+
+// OnError sets the function told about subscribers that return an error or panic.
+// By default they are logged.
+func (b *Bus) OnError(fn func(ctx context.Context, e DomainEvent, err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onError = fn
+}
+
+// Publish delivers e to its synchronous subscribers, then queues it for the asynchronous ones.
+// Services call it after a change succeeds and without holding their locks.
+// Once the bus is closed, asynchronous subscribers also run before Publish returns.
+func (b *Bus) Publish(ctx context.Context, e DomainEvent) {
+	ctx, span := startSpan(ctx, "Bus.Publish")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("event.name", e.EventName()),
+		attribute.String("event.aggregate", e.AggregateID()),
+	)
+
+	b.mu.RLock()
+	subscribers, closed := b.subscribers, b.closed
+	b.mu.RUnlock()
+
+	var async []*subscriber
+	for _, s := range subscribers {
+		if !s.match(e) {
+			continue
+		}
+		if s.async && !closed {
+			async = append(async, s)
+			continue
+		}
+		b.deliver(ctx, s, e)
+	}
+	if len(async) > 0 {
+		b.enqueue(delivery{ctx: context.WithoutCancel(ctx), event: e, subscribers: async})
+	}
+}
+
+// Wait blocks until every asynchronous delivery queued so far has run. Tests use it
+// before asserting on the effects of asynchronous subscribers.
+func (b *Bus) Wait() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.pending > 0 {
+		b.idle.Wait()
+	}
+}
+
+// Close runs the queued asynchronous deliveries and stops the workers.
+// Events published afterwards reach every subscriber synchronously.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	// Workers empty their queue before they stop.
+	for _, sh := range b.shards {
+		sh.mu.Lock()
+		sh.closed = true
+		sh.mu.Unlock()
+		sh.ready.Signal()
+	}
+	b.Wait()
+}
+
+// start launches one worker per shard.
+func (b *Bus) start() {
+	b.shards = make([]*busShard, runtime.GOMAXPROCS(0))
+	for i := range b.shards {
+		sh := &busShard{}
+		sh.ready = sync.NewCond(&sh.mu)
+		b.shards[i] = sh
+		go b.work(sh)
+	}
+}
+
+// enqueue hands d to the shard of its aggregate. Queues are unbounded, so publishers
+// never block on slow subscribers, including subscribers that publish events themselves.
+func (b *Bus) enqueue(d delivery) {
+	h := fnv.New32a()
+	h.Write([]byte(d.event.AggregateID()))
+	sh := b.shards[h.Sum32()%uint32(len(b.shards))]
+
+	sh.mu.Lock()
+	if sh.closed {
+		// The bus closed while the event was being published.
+		sh.mu.Unlock()
+		for _, s := range d.subscribers {
+			b.deliver(d.ctx, s, d.event)
+		}
+		return
+	}
+	b.mu.Lock()
+	b.pending++
+	b.mu.Unlock()
+	sh.queue = append(sh.queue, d)
+	sh.mu.Unlock()
+	sh.ready.Signal()
+}
+
+func (b *Bus) work(sh *busShard) {
+	for {
+		sh.mu.Lock()
+		for len(sh.queue) == 0 && !sh.closed {
+			sh.ready.Wait()
+		}
+		if len(sh.queue) == 0 {
+			sh.mu.Unlock()
+			return
+		}
+		d := sh.queue[0]
+		sh.queue = slices.Delete(sh.queue, 0, 1)
+		sh.mu.Unlock()
+
+		for _, s := range d.subscribers {
+			b.deliver(d.ctx, s, d.event)
+		}
+
+		b.mu.Lock()
+		b.pending--
+		if b.pending == 0 {
+			b.idle.Broadcast()
+		}
+		b.mu.Unlock()
+	}
+}
+
+// deliver runs one subscriber, reporting its error or panic instead of propagating it.
+func (b *Bus) deliver(ctx context.Context, s *subscriber, e DomainEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.failed(ctx, e, fmt.Errorf("panic: %v", r))
+		}
+	}()
+	if err := s.fn(ctx, e); err != nil {
+		b.failed(ctx, e, err)
+	}
+}
+
+func (b *Bus) failed(ctx context.Context, e DomainEvent, err error) {
+	b.mu.RLock()
+	onError := b.onError
+	b.mu.RUnlock()
+	onError(ctx, e, err)
+}
+
+func logSubscriberError(_ context.Context, e DomainEvent, err error) {
+	log.Printf("Subscriber to %s of %s failed: %v", e.EventName(), e.AggregateID(), err)
+}
+
+// Recorder collects the events published on a bus so tests can assert on them.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	events []DomainEvent
+}
+
+// Record starts recording every event published on b.
+func Record(b *Bus) *Recorder {
+	r := &Recorder{}
+	Subscribe(b, func(_ context.Context, e DomainEvent) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, e)
+		return nil
+	})
+	return r
+}
+
+// Events returns the events recorded so far, in publication order.
+func (r *Recorder) Events() []DomainEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.events)
+}
+
+// Names returns the names of the events recorded so far, in publication order.
+func (r *Recorder) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, len(r.events))
+	for i, e := range r.events {
+		names[i] = e.EventName()
+	}
+	return names
+}
+
+// Reset forgets the events recorded so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = nil
+}
+
+// Expect reports a test error unless exactly the named events were recorded, in order.
+// t is usually a *testing.T.
+func (r *Recorder) Expect(t interface {
+	Helper()
+	Errorf(format string, args ...any)
+}, names ...string) {
+	t.Helper()
+	if got := r.Names(); !slices.Equal(got, names) {
+		t.Errorf("Expected events %v, got %v", names, got)
+	}
+}
+
+// Recorded returns the recorded events of type E, in publication order.
+func Recorded[E DomainEvent](r *Recorder) []E {
+	var matched []E
+	for _, e := range r.Events() {
+		if e, ok := e.(E); ok {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
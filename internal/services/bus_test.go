@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("typed synchronous delivery", func(t *testing.T) {
+		bus := NewBus()
+		var created []int
+		var all []string
+		Subscribe(bus, func(_ context.Context, e PostCreated) error {
+			created = append(created, e.Post.ID)
+			return nil
+		})
+		Subscribe(bus, func(_ context.Context, e DomainEvent) error {
+			all = append(all, e.EventName())
+			return nil
+		})
+
+		bus.Publish(ctx, PostCreated{Post: models.Post{ID: 1}})
+		bus.Publish(ctx, PostDeleted{Post: models.Post{ID: 1}})
+		bus.Publish(ctx, PostCreated{Post: models.Post{ID: 2}})
+
+		if !reflect.DeepEqual(created, []int{1, 2}) {
+			t.Errorf("Expected created posts [1 2], got %v", created)
+		}
+		if !reflect.DeepEqual(all, []string{"post.created", "post.deleted", "post.created"}) {
+			t.Errorf("Expected every event, got %v", all)
+		}
+	})
+
+	t.Run("failing subscribers are isolated", func(t *testing.T) {
+		bus := NewBus()
+		var failures []string
+		bus.OnError(func(_ context.Context, e DomainEvent, err error) {
+			failures = append(failures, e.EventName()+": "+err.Error())
+		})
+		delivered := 0
+		Subscribe(bus, func(context.Context, UserDeleted) error { return errors.New("boom") })
+		Subscribe(bus, func(context.Context, UserDeleted) error { panic("oops") })
+		Subscribe(bus, func(context.Context, UserDeleted) error {
+			delivered++
+			return nil
+		})
+
+		bus.Publish(ctx, UserDeleted{User: models.User{ID: 1}})
+
+		if delivered != 1 {
+			t.Errorf("Expected the healthy subscriber to run once, got %d", delivered)
+		}
+		expected := []string{"user.deleted: boom", "user.deleted: panic: oops"}
+		if !reflect.DeepEqual(failures, expected) {
+			t.Errorf("Expected failures %v, got %v", expected, failures)
+		}
+	})
+
+	t.Run("asynchronous delivery is ordered per aggregate", func(t *testing.T) {
+		bus := NewBus()
+		var mu sync.Mutex
+		seen := make(map[string][]int)
+		SubscribeAsync(bus, func(_ context.Context, e PostReacted) error {
+			// Slow handling must not reorder the events of a post.
+			time.Sleep(time.Duration(e.Reaction.UserID%3) * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			key := e.AggregateID()
+			seen[key] = append(seen[key], e.Reaction.UserID)
+			return nil
+		})
+
+		for userID := 1; userID <= 20; userID++ {
+			for postID := 1; postID <= 3; postID++ {
+				bus.Publish(ctx, PostReacted{Reaction: models.Reaction{PostID: postID, UserID: userID}})
+			}
+		}
+		bus.Wait()
+
+		for _, key := range []string{"post:1", "post:2", "post:3"} {
+			got := seen[key]
+			if len(got) != 20 {
+				t.Fatalf("Expected 20 events for %s, got %d", key, len(got))
+			}
+			for i, userID := range got {
+				if userID != i+1 {
+					t.Errorf("Expected events of %s in publication order, got %v", key, got)
+					break
+				}
+			}
+		}
+	})
+
+	t.Run("asynchronous subscribers outlive the request", func(t *testing.T) {
+		bus := NewBus()
+		errs := make(chan error, 1)
+		release := make(chan struct{})
+		SubscribeAsync(bus, func(ctx context.Context, _ PostCreated) error {
+			<-release
+			errs <- ctx.Err()
+			return nil
+		})
+
+		reqCtx, cancel := context.WithCancel(ctx)
+		bus.Publish(reqCtx, PostCreated{Post: models.Post{ID: 1}})
+		cancel()
+		close(release)
+		bus.Close()
+
+		if err := <-errs; err != nil {
+			t.Errorf("Expected an uncancelled context, got %v", err)
+		}
+	})
+
+	t.Run("close runs later events synchronously", func(t *testing.T) {
+		bus := NewBus()
+		delivered := 0
+		SubscribeAsync(bus, func(context.Context, PostCreated) error {
+			delivered++
+			return nil
+		})
+		bus.Close()
+		bus.Publish(ctx, PostCreated{Post: models.Post{ID: 1}})
+		if delivered != 1 {
+			t.Errorf("Expected delivery before Publish returns, got %d deliveries", delivered)
+		}
+	})
+
+	t.Run("recorder", func(t *testing.T) {
+		bus := NewBus()
+		rec := Record(bus)
+		users := NewUserService(bus)
+		posts := NewPostService(bus)
+		follows := NewFollowService(users)
+
+		ana, _ := users.Register(ctx, "Ana", "ana@example.com")
+		bob, _ := users.Register(ctx, "Bob", "bob@example.com")
+		follows.Follow(ctx, ana, bob)
+		follows.Follow(ctx, ana, bob)
+		postID, _ := posts.Create(ctx, "Title", "Content", ana)
+		posts.React(ctx, postID, bob, models.ReactionLike)
+		posts.Delete(ctx, postID)
+
+		rec.Expect(t, "user.registered", "user.registered", "user.followed", "post.created", "post.reacted", "post.deleted")
+		deleted := Recorded[PostDeleted](rec)
+		if len(deleted) != 1 || deleted[0].Post.Title != "Title" {
+			t.Errorf("Expected the deleted post as it was, got %+v", deleted)
+		}
+
+		rec.Reset()
+		users.Delete(ctx, bob)
+		rec.Expect(t, "user.deleted")
+	})
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"sync"
+	"time"
+)
+
+// CommentService manages comments on posts, including threaded replies.
+// It maintains an in-memory collection of comments and removes a post's comments
+// when the post is deleted through PostService.Delete.
+//
+// Comments are only added while s.mu is held and their post still exists, and the comments
+// of a deleted post are removed under s.mu once the post is gone, so no comment outlives its post.
+type CommentService struct {
+	mu       sync.RWMutex
+	comments []models.Comment
+	nextId   int
+	posts    *PostService
+	now      func() time.Time
+}
+
+// NewCommentService creates and returns a new instance of CommentService bound to the given post service.
+func NewCommentService(posts *PostService) *CommentService {
+	s := &CommentService{
+		comments: make([]models.Comment, 0),
+		nextId:   1,
+		posts:    posts,
+		now:      time.Now,
+	}
+	Subscribe(posts.bus, s.postDeleted)
+	return s
+}
+
+// Create adds a comment to a post, optionally as a reply to parentID.
+// Returns the new comment's ID and an error if creation fails.
+// Creation fails if the body is empty, the post doesn't exist, or the parent
+// comment doesn't exist on the same post.
+func (s *CommentService) Create(ctx context.Context, postID int, userID int, body string, parentID *int) (int, error) {
+	ctx, span := startSpan(ctx, "CommentService.Create")
+	defer span.End()
+
+	if body == "" {
+		return 0, fail(span, errors.New("body is required"))
+	}
+
+	s.mu.Lock()
+	// The post is looked up under s.mu: were it deleted in between, the removal of its
+	// comments could run before this one is added, leaving it behind.
+	if _, err := s.posts.FindByID(ctx, postID); err != nil {
+		s.mu.Unlock()
+		return 0, fail(span, err)
+	}
+	if parentID != nil {
+		parent, ok := s.find(*parentID)
+		if !ok {
+			s.mu.Unlock()
+			return 0, fail(span, errors.New("parent comment not found"))
+		}
+		if parent.PostID != postID {
+			s.mu.Unlock()
+			return 0, fail(span, errors.New("parent comment belongs to another post"))
+		}
+	}
+
+	now := s.now()
+	comment := models.Comment{
+		ID:        s.nextId,
+		PostID:    postID,
+		UserID:    userID,
+		ParentID:  parentID,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	insert := startStorageSpan(ctx, "comments", "insert")
+	s.comments = append(s.comments, comment)
+	s.nextId++
+	insert.End()
+	s.mu.Unlock()
+
+	s.posts.bus.Publish(ctx, CommentCreated{Comment: comment})
+	return comment.ID, nil
+}
+
+// FindByPostID returns the comments of a post in creation order.
+// Returns an error if the post doesn't exist.
+func (s *CommentService) FindByPostID(ctx context.Context, postID int) ([]models.Comment, error) {
+	ctx, span := startSpan(ctx, "CommentService.FindByPostID")
+	defer span.End()
+
+	if _, err := s.posts.FindByID(ctx, postID); err != nil {
+		return nil, fail(span, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "comments", "scan")
+	defer scan.End()
+	postComments := make([]models.Comment, 0)
+	for _, c := range s.comments {
+		if c.PostID == postID {
+			postComments = append(postComments, c)
+		}
+	}
+	return postComments, nil
+}
+
+// FindByID searches for a comment by its ID.
+// Returns the comment if found, or an error if no comment exists with the given ID.
+func (s *CommentService) FindByID(ctx context.Context, id int) (models.Comment, error) {
+	_, span := startSpan(ctx, "CommentService.FindByID")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if c, ok := s.find(id); ok {
+		return c, nil
+	}
+	return models.Comment{}, fail(span, errors.New("comment not found"))
+}
+
+// Delete removes a comment and all replies beneath it.
+// Returns true if the comment was found and deleted, false otherwise.
+func (s *CommentService) Delete(ctx context.Context, id int) bool {
+	ctx, span := startSpan(ctx, "CommentService.Delete")
+	defer span.End()
+
+	s.mu.Lock()
+	if _, ok := s.find(id); !ok {
+		s.mu.Unlock()
+		return false
+	}
+
+	del := startStorageSpan(ctx, "comments", "delete")
+	removed := map[int]bool{id: true}
+	var deleted []models.Comment
+	// Replies are always created after their parent, so one pass in order
+	// collects the whole subtree.
+	for _, c := range s.comments {
+		if c.ID == id || c.ParentID != nil && removed[*c.ParentID] {
+			removed[c.ID] = true
+			deleted = append(deleted, c)
+		}
+	}
+	s.removeWhere(func(c models.Comment) bool { return removed[c.ID] })
+	del.End()
+	s.mu.Unlock()
+
+	for _, c := range deleted {
+		s.posts.bus.Publish(ctx, CommentDeleted{Comment: c})
+	}
+	return true
+}
+
+// postDeleted cascades a post deletion to its comments, publishing CommentDeleted for each.
+func (s *CommentService) postDeleted(ctx context.Context, e PostDeleted) error {
+	s.mu.Lock()
+	del := startStorageSpan(ctx, "comments", "delete")
+	var deleted []models.Comment
+	for _, c := range s.comments {
+		if c.PostID == e.Post.ID {
+			deleted = append(deleted, c)
+		}
+	}
+	s.removeWhere(func(c models.Comment) bool { return c.PostID == e.Post.ID })
+	del.End()
+	s.mu.Unlock()
+
+	for _, c := range deleted {
+		s.posts.bus.Publish(ctx, CommentDeleted{Comment: c, Post: &e.Post})
+	}
+	return nil
+}
+
+// find returns the comment with the given ID. Callers must hold s.mu.
+func (s *CommentService) find(id int) (models.Comment, bool) {
+	for _, c := range s.comments {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return models.Comment{}, false
+}
+
+// removeWhere drops all comments matching fn. Callers must hold s.mu for writing.
+func (s *CommentService) removeWhere(fn func(models.Comment) bool) {
+	kept := s.comments[:0]
+	for _, c := range s.comments {
+		if !fn(c) {
+			kept = append(kept, c)
+		}
+	}
+	s.comments = kept
+}
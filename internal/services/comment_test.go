@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCommentService(t *testing.T) {
+	// Initialize services
+	ctx := context.Background()
+	bus := NewBus()
+	posts := NewPostService(bus)
+	s := NewCommentService(posts)
+	postID, _ := posts.Create(ctx, "Test Post", "This is a test post", 1)
+	otherPostID, _ := posts.Create(ctx, "Other Post", "This is another post", 1)
+
+	// Test Create
+	t.Run("Create valid comment", func(t *testing.T) {
+		id, err := s.Create(ctx, postID, 2, "Nice post", nil)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if id != 1 {
+			t.Errorf("Expected ID 1, got %d", id)
+		}
+	})
+
+	t.Run("Create reply", func(t *testing.T) {
+		parent := 1
+		id, err := s.Create(ctx, postID, 1, "Thanks!", &parent)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		c, _ := s.FindByID(ctx, id)
+		if c.ParentID == nil || *c.ParentID != 1 {
+			t.Errorf("Expected parent 1, got %v", c.ParentID)
+		}
+	})
+
+	t.Run("Create comment with empty body", func(t *testing.T) {
+		_, err := s.Create(ctx, postID, 2, "", nil)
+		if err == nil || err.Error() != "body is required" {
+			t.Errorf("Expected body required error, got %v", err)
+		}
+	})
+
+	t.Run("Create comment on missing post", func(t *testing.T) {
+		_, err := s.Create(ctx, 999, 2, "Hello?", nil)
+		if err != ErrPostNotFound {
+			t.Errorf("Expected post not found error, got %v", err)
+		}
+	})
+
+	t.Run("Create reply to comment on another post", func(t *testing.T) {
+		parent := 1
+		_, err := s.Create(ctx, otherPostID, 2, "Wrong thread", &parent)
+		if err == nil || err.Error() != "parent comment belongs to another post" {
+			t.Errorf("Expected parent mismatch error, got %v", err)
+		}
+	})
+
+	// Test FindByPostID
+	t.Run("Find comments by post ID", func(t *testing.T) {
+		s.Create(ctx, otherPostID, 3, "Comment on other post", nil)
+		comments, err := s.FindByPostID(ctx, postID)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if len(comments) != 2 {
+			t.Errorf("Expected 2 comments, got %d", len(comments))
+		}
+		if comments[0].CreatedAt.IsZero() {
+			t.Error("Expected creation time to be set")
+		}
+	})
+
+	// Test Delete
+	t.Run("Delete comment removes replies", func(t *testing.T) {
+		if !s.Delete(ctx, 1) {
+			t.Error("Expected true, got false")
+		}
+		comments, _ := s.FindByPostID(ctx, postID)
+		if len(comments) != 0 {
+			t.Errorf("Expected 0 comments, got %d", len(comments))
+		}
+	})
+
+	t.Run("Delete non-existent comment", func(t *testing.T) {
+		if s.Delete(ctx, 999) {
+			t.Error("Expected false, got true")
+		}
+	})
+
+	t.Run("Deleting post cascades to comments", func(t *testing.T) {
+		rec := Record(bus)
+		posts.Delete(ctx, otherPostID)
+		if _, err := s.FindByID(ctx, 3); err == nil {
+			t.Error("Expected comment to be removed with its post")
+		}
+		deleted := Recorded[CommentDeleted](rec)
+		if len(deleted) != 1 || deleted[0].Comment.ID != 3 || deleted[0].Post == nil || deleted[0].Post.ID != otherPostID {
+			t.Errorf("Expected comment.deleted for comment 3 along with post %d, got %+v", otherPostID, deleted)
+		}
+	})
+
+	t.Run("Create comment on deleted post", func(t *testing.T) {
+		if _, err := s.Create(ctx, otherPostID, 2, "Too late", nil); err != ErrPostNotFound {
+			t.Errorf("Expected post not found error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"cmp"
+	"example/api/internal/models"
+	"slices"
+	"strings"
+)
+
+// diffLines computes a line-based diff turning a into b with Myers' algorithm, which takes
+// O((N+M)·D) time and O(N+M) memory for N and M lines and D differing lines.
+// Deletions are listed before insertions at each change. Returns ErrDiffTooLarge if a or b
+// has more than MaxDiffLines lines, or if they differ in more than MaxDiffChanges lines
+// while having lines in common, which bounds the time taken.
+func diffLines(a string, b string) ([]models.DiffLine, error) {
+	x := splitLines(a)
+	y := splitLines(b)
+	if len(x) > MaxDiffLines || len(y) > MaxDiffLines {
+		return nil, ErrDiffTooLarge
+	}
+
+	// Lines are compared as numbers, each distinct line getting its own.
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, l := range lines {
+			id, ok := ids[l]
+			if !ok {
+				id = len(ids)
+				ids[l] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	d := differ{x: x, y: y, xs: intern(x), ys: intern(y)}
+	if !d.shareLines() {
+		// Nothing to search for: every line is replaced, however many there are.
+		d.change(0, len(x), 0, len(y))
+		return d.out, nil
+	}
+	d.diff(0, len(x), 0, len(y))
+	if d.err != nil {
+		return nil, d.err
+	}
+	return deletionsFirst(d.out), nil
+}
+
+// deletionsFirst moves the deletions of each run of changed lines before its insertions.
+func deletionsFirst(diff []models.DiffLine) []models.DiffLine {
+	for start := 0; start < len(diff); start++ {
+		if diff[start].Op == "equal" {
+			continue
+		}
+		end := start
+		for end < len(diff) && diff[end].Op != "equal" {
+			end++
+		}
+		slices.SortStableFunc(diff[start:end], func(a, b models.DiffLine) int {
+			return cmp.Compare(opOrder(a.Op), opOrder(b.Op))
+		})
+		start = end
+	}
+	return diff
+}
+
+func opOrder(op string) int {
+	if op == "delete" {
+		return 0
+	}
+	return 1
+}
+
+// differ holds the lines being compared and the diff built so far.
+type differ struct {
+	x, y   []string
+	xs, ys []int
+	out    []models.DiffLine
+	// err is set to ErrDiffTooLarge when the search is given up
+	err error
+}
+
+// shareLines reports whether x and y have a line in common.
+func (d *differ) shareLines() bool {
+	seen := make(map[int]bool, len(d.xs))
+	for _, id := range d.xs {
+		seen[id] = true
+	}
+	for _, id := range d.ys {
+		if seen[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// diff appends the diff turning x[x0:x1] into y[y0:y1].
+func (d *differ) diff(x0 int, x1 int, y0 int, y1 int) {
+	if d.err != nil {
+		return
+	}
+	for x0 < x1 && y0 < y1 && d.xs[x0] == d.ys[y0] {
+		d.out = append(d.out, models.DiffLine{Op: "equal", Text: d.x[x0]})
+		x0++
+		y0++
+	}
+	suffix := 0
+	for x0 < x1-suffix && y0 < y1-suffix && d.xs[x1-suffix-1] == d.ys[y1-suffix-1] {
+		suffix++
+	}
+	x1 -= suffix
+	y1 -= suffix
+
+	if x0 == x1 || y0 == y1 {
+		d.change(x0, x1, y0, y1)
+	} else if sx, sy, ok := d.middle(x0, x1, y0, y1); ok {
+		d.diff(x0, sx, y0, sy)
+		d.diff(sx, x1, sy, y1)
+	} else {
+		d.change(x0, x1, y0, y1)
+	}
+
+	for i := range suffix {
+		d.out = append(d.out, models.DiffLine{Op: "equal", Text: d.x[x1+i]})
+	}
+}
+
+// change appends the deletion of x[x0:x1] and the insertion of y[y0:y1].
+func (d *differ) change(x0 int, x1 int, y0 int, y1 int) {
+	for _, l := range d.x[x0:x1] {
+		d.out = append(d.out, models.DiffLine{Op: "delete", Text: l})
+	}
+	for _, l := range d.y[y0:y1] {
+		d.out = append(d.out, models.DiffLine{Op: "insert", Text: l})
+	}
+}
+
+// middle finds the middle snake of a shortest edit script turning x[x0:x1] into y[y0:y1],
+// which must not be empty nor share their first or last line, by searching forward from
+// the start and backward from the end at once. It returns a point the script passes
+// through, which splits the problem in two, or false if the only script replaces every line
+// or the script has more than MaxDiffChanges edits, in which case d.err is set.
+func (d *differ) middle(x0 int, x1 int, y0 int, y1 int) (int, int, bool) {
+	n, m := x1-x0, y1-y0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// forward[offset+k] and backward[offset+k] are the furthest x reached on diagonal k
+	// (x-y), counting from the start and from the end respectively; -1 if not reached.
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+	delta := n - m
+	// With an odd delta the paths meet while extending forward, otherwise backward.
+	odd := delta%2 != 0
+	// Diagonals that run off the edges are skipped.
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+
+	for step := 0; step < maxD; step++ {
+		// The paths have taken 2*step edits between them. The script of a part of the
+		// input is no longer than the whole's, so checking every part bounds the whole.
+		if 2*step > MaxDiffChanges {
+			d.err = ErrDiffTooLarge
+			return 0, 0, false
+		}
+		for k := -step + fStart; k <= step-fEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.xs[x0+x] == d.ys[y0+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				b := offset + delta - k
+				if b >= 0 && b < len(backward) && backward[b] != -1 && x >= n-backward[b] {
+					return x0 + x, y0 + y, true
+				}
+			}
+		}
+		for k := -step + bStart; k <= step-bEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.xs[x1-x-1] == d.ys[y1-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				f := offset + delta - k
+				if f >= 0 && f < len(forward) && forward[f] != -1 {
+					fx := forward[f]
+					fy := fx - (delta - k)
+					if fx >= n-x {
+						return x0 + fx, y0 + fy, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"example/api/internal/models"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffLines(t *testing.T) {
+	t.Run("Shortest diff", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			a, b     string
+			expected []models.DiffLine
+		}{
+			{"empty", "", "", []models.DiffLine{}},
+			{"added", "", "a\nb", []models.DiffLine{{Op: "insert", Text: "a"}, {Op: "insert", Text: "b"}}},
+			{"removed", "a\nb", "", []models.DiffLine{{Op: "delete", Text: "a"}, {Op: "delete", Text: "b"}}},
+			{"replaced", "a\nb\nc", "a\nx\ny\nc", []models.DiffLine{
+				{Op: "equal", Text: "a"},
+				{Op: "delete", Text: "b"},
+				{Op: "insert", Text: "x"},
+				{Op: "insert", Text: "y"},
+				{Op: "equal", Text: "c"},
+			}},
+			{"rewritten", "a\nb", "c\nd", []models.DiffLine{
+				{Op: "delete", Text: "a"},
+				{Op: "delete", Text: "b"},
+				{Op: "insert", Text: "c"},
+				{Op: "insert", Text: "d"},
+			}},
+		}
+		for _, tt := range tests {
+			got, _ := diffLines(tt.a, tt.b)
+			if len(got) == 0 && len(tt.expected) == 0 {
+				continue
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+			}
+		}
+	})
+
+	t.Run("Random inputs", func(t *testing.T) {
+		rng := rand.New(rand.NewPCG(1, 2))
+		random := func() []string {
+			lines := make([]string, rng.IntN(30))
+			for i := range lines {
+				lines[i] = strconv.Itoa(rng.IntN(5))
+			}
+			return lines
+		}
+		for range 500 {
+			x, y := random(), random()
+			diff, _ := diffLines(strings.Join(x, "\n"), strings.Join(y, "\n"))
+			checkDiff(t, x, y, diff)
+			if changes, minimum := countChanges(diff), len(x)+len(y)-2*lcsLength(x, y); changes != minimum {
+				t.Fatalf("Expected %d changes turning %v into %v, got %d: %v", minimum, x, y, changes, diff)
+			}
+		}
+	})
+
+	t.Run("Large inputs", func(t *testing.T) {
+		const n = MaxDiffLines
+		x := make([]string, n)
+		y := make([]string, 0, n)
+		for i := range x {
+			x[i] = "line " + strconv.Itoa(i)
+			switch {
+			case i%1000 == 0:
+				y = append(y, "changed "+strconv.Itoa(i))
+			case i%1000 != 1:
+				y = append(y, x[i])
+			}
+		}
+		started := time.Now()
+		diff, _ := diffLines(strings.Join(x, "\n"), strings.Join(y, "\n"))
+		checkDiff(t, x, y, diff)
+		if changes := countChanges(diff); changes != 30 {
+			t.Errorf("Expected 30 changed lines, got %d", changes)
+		}
+
+		unrelated := make([]string, 5000)
+		for i := range unrelated {
+			unrelated[i] = "other " + strconv.Itoa(i)
+		}
+		diff, _ = diffLines(strings.Join(x[:5000], "\n"), strings.Join(unrelated, "\n"))
+		checkDiff(t, x[:5000], unrelated, diff)
+		if elapsed := time.Since(started); elapsed > 10*time.Second {
+			t.Errorf("Expected large diffs to be quick, took %v", elapsed)
+		}
+	})
+
+	t.Run("Too many lines", func(t *testing.T) {
+		long := strings.Repeat("line\n", MaxDiffLines+1)
+		if _, err := diffLines(long, "short"); !errors.Is(err, ErrDiffTooLarge) {
+			t.Errorf("Expected ErrDiffTooLarge, got %v", err)
+		}
+		if _, err := diffLines(strings.Repeat("line\n", MaxDiffLines), "short"); err != nil {
+			t.Errorf("Expected no error at the limit, got %v", err)
+		}
+	})
+
+	t.Run("Too many changes", func(t *testing.T) {
+		// changed returns MaxDiffLines lines with every step-th line but the middle one
+		// changed, or none if step is 0.
+		changed := func(step int) string {
+			lines := make([]string, MaxDiffLines)
+			for i := range lines {
+				lines[i] = "line " + strconv.Itoa(i)
+				if step > 0 && i%step == step-1 && i != MaxDiffLines/2 {
+					lines[i] = "changed " + strconv.Itoa(i)
+				}
+			}
+			return strings.Join(lines, "\n")
+		}
+		original := changed(0)
+		started := time.Now()
+		if _, err := diffLines(original, changed(1)); !errors.Is(err, ErrDiffTooLarge) {
+			t.Errorf("Expected ErrDiffTooLarge for unrelated revisions with a common line, got %v", err)
+		}
+		if _, err := diffLines(original, changed(9)); !errors.Is(err, ErrDiffTooLarge) {
+			t.Errorf("Expected ErrDiffTooLarge past the limit, got %v", err)
+		}
+		diff, err := diffLines(original, changed(MaxDiffLines*2/MaxDiffChanges))
+		if err != nil {
+			t.Fatalf("Expected no error at the limit, got %v", err)
+		}
+		if changes := countChanges(diff); changes != MaxDiffChanges {
+			t.Errorf("Expected %d changed lines, got %d", MaxDiffChanges, changes)
+		}
+		if elapsed := time.Since(started); elapsed > time.Second {
+			t.Errorf("Expected diffs past the limit to be given up quickly, took %v", elapsed)
+		}
+	})
+}
+
+// checkDiff fails unless diff turns x into y.
+func checkDiff(t *testing.T, x []string, y []string, diff []models.DiffLine) {
+	t.Helper()
+	var from, to []string
+	for _, l := range diff {
+		if l.Op != "insert" {
+			from = append(from, l.Text)
+		}
+		if l.Op != "delete" {
+			to = append(to, l.Text)
+		}
+	}
+	if strings.Join(from, "\n") != strings.Join(x, "\n") || strings.Join(to, "\n") != strings.Join(y, "\n") {
+		t.Fatalf("Expected a diff turning %d lines into %d, got %v", len(x), len(y), diff)
+	}
+}
+
+func countChanges(diff []models.DiffLine) int {
+	n := 0
+	for _, l := range diff {
+		if l.Op != "equal" {
+			n++
+		}
+	}
+	return n
+}
+
+// lcsLength is the length of the longest common subsequence of x and y.
+func lcsLength(x []string, y []string) int {
+	prev := make([]int, len(y)+1)
+	for i := range x {
+		cur := make([]int, len(y)+1)
+		for j := range y {
+			if x[i] == y[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(y)]
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"slices"
+	"sort"
+	"unicode"
+)
+
+// ExtractEntities finds the @mentions and #hashtags in content.
+// A mention is '@' followed by a valid handle; a hashtag is '#' followed by letters, digits
+// and '_', including at least one letter. Either must start the content or follow a character
+// that cannot be part of a word, so e-mail addresses and URL fragments are not matched.
+// Mentions are returned without user IDs.
+func ExtractEntities(content string) models.PostEntities {
+	entities := models.PostEntities{Mentions: []models.Mention{}, Hashtags: []models.Hashtag{}}
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != '@' && r != '#' {
+			continue
+		}
+		if i > 0 && !startsEntity(runes[i-1]) {
+			continue
+		}
+		end := i + 1
+		if r == '@' {
+			for end < len(runes) && isHandleChar(runes[end]) {
+				end++
+			}
+			handle, err := NormalizeHandle(string(runes[i+1 : end]))
+			if err == nil {
+				entities.Mentions = append(entities.Mentions, models.Mention{Handle: handle, Start: i, End: end})
+			}
+		} else {
+			letter := false
+			for end < len(runes) && isHashtagChar(runes[end]) {
+				letter = letter || unicode.IsLetter(runes[end])
+				end++
+			}
+			tag, err := NormalizeTag(string(runes[i+1 : end]))
+			if err == nil && letter {
+				entities.Hashtags = append(entities.Hashtags, models.Hashtag{Tag: tag, Start: i, End: end})
+			}
+		}
+		i = end - 1
+	}
+	return entities
+}
+
+// ResolveMentionsWith sets the function that maps mentioned handles to user IDs, usually
+// UserService.ResolveHandles. Mentions of handles it doesn't know are dropped. Until it is
+// set, posts have no mentions. fn may be called while the post service holds its lock,
+// so it must not call back into the PostService.
+func (s *PostService) ResolveMentionsWith(fn func(ctx context.Context, handles []string) map[string]int) {
+	s.resolveMentions = fn
+}
+
+// MentionsOf returns the posts mentioning userID, most recently created first, whatever their status.
+func (s *PostService) MentionsOf(ctx context.Context, userID int) []models.Post {
+	ctx, span := startSpan(ctx, "PostService.MentionsOf")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "post_mentions", "scan")
+	defer scan.End()
+	posts := make([]models.Post, 0, len(s.mentionIndex[userID]))
+	for id := range s.mentionIndex[userID] {
+		posts = append(posts, s.posts[s.index(id)])
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ID > posts[j].ID })
+	return posts
+}
+
+// entities extracts the entities of content and resolves its mentions.
+func (s *PostService) entities(ctx context.Context, content string) models.PostEntities {
+	entities := ExtractEntities(content)
+	if len(entities.Mentions) == 0 {
+		return entities
+	}
+	var ids map[string]int
+	if s.resolveMentions != nil {
+		handles := make([]string, 0, len(entities.Mentions))
+		for _, m := range entities.Mentions {
+			handles = append(handles, m.Handle)
+		}
+		ids = s.resolveMentions(ctx, slices.Compact(slices.Sorted(slices.Values(handles))))
+	}
+	resolved := entities.Mentions[:0]
+	for _, m := range entities.Mentions {
+		if id, ok := ids[m.Handle]; ok {
+			m.UserID = id
+			resolved = append(resolved, m)
+		}
+	}
+	entities.Mentions = resolved
+	return entities
+}
+
+// indexMentions adds a post to the mention index. Callers must hold s.mu for writing.
+func (s *PostService) indexMentions(post models.Post) {
+	for _, m := range post.Entities.Mentions {
+		if s.mentionIndex[m.UserID] == nil {
+			s.mentionIndex[m.UserID] = make(map[int]struct{})
+		}
+		s.mentionIndex[m.UserID][post.ID] = struct{}{}
+	}
+}
+
+// unindexMentions removes a post from the mention index. Callers must hold s.mu for writing.
+func (s *PostService) unindexMentions(post models.Post) {
+	for _, m := range post.Entities.Mentions {
+		delete(s.mentionIndex[m.UserID], post.ID)
+		if len(s.mentionIndex[m.UserID]) == 0 {
+			delete(s.mentionIndex, m.UserID)
+		}
+	}
+}
+
+// startsEntity reports whether an '@' or '#' following r may start a mention or hashtag.
+func startsEntity(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '@' && r != '#' && r != '/' && r != '&'
+}
+
+func isHashtagChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestExtractEntities(t *testing.T) {
+	tests := []struct {
+		content  string
+		mentions []models.Mention
+		hashtags []models.Hashtag
+	}{
+		{
+			"Hola @Ana, mira #Golang y #café!",
+			[]models.Mention{{Handle: "ana", Start: 5, End: 9}},
+			[]models.Hashtag{{Tag: "golang", Start: 16, End: 23}, {Tag: "café", Start: 26, End: 31}},
+		},
+		{"mail bob@example.com or see https://x.io/#intro", nil, nil},
+		{"#1 and @ab are too short, #go_1 is fine", nil, []models.Hashtag{{Tag: "go_1", Start: 26, End: 31}}},
+		{"(@carol_99)#tag", []models.Mention{{Handle: "carol_99", Start: 1, End: 10}}, []models.Hashtag{{Tag: "tag", Start: 11, End: 15}}},
+		{"# Heading\n##twice @@double", nil, nil},
+	}
+	for _, tt := range tests {
+		got := ExtractEntities(tt.content)
+		if tt.mentions == nil {
+			tt.mentions = []models.Mention{}
+		}
+		if tt.hashtags == nil {
+			tt.hashtags = []models.Hashtag{}
+		}
+		if !reflect.DeepEqual(got.Mentions, tt.mentions) {
+			t.Errorf("ExtractEntities(%q): expected mentions %v, got %v", tt.content, tt.mentions, got.Mentions)
+		}
+		if !reflect.DeepEqual(got.Hashtags, tt.hashtags) {
+			t.Errorf("ExtractEntities(%q): expected hashtags %v, got %v", tt.content, tt.hashtags, got.Hashtags)
+		}
+	}
+}
+
+func TestPostMentions(t *testing.T) {
+	// Initialize services
+	ctx := context.Background()
+	users := NewUserService(NewBus())
+	s := NewPostService(NewBus())
+	s.ResolveMentionsWith(users.ResolveHandles)
+
+	ana, _ := users.Register(ctx, "Ana", "ana@example.com")
+	bob, _ := users.Register(ctx, "Bob", "bob@example.com")
+
+	first, _ := s.Create(ctx, "First", "Hi @ana and @nobody", bob)
+	second, _ := s.Create(ctx, "Second", "@Ana @ana again", bob)
+
+	t.Run("Unknown handles are dropped", func(t *testing.T) {
+		post, _ := s.FindByID(ctx, first)
+		expected := []models.Mention{{Handle: "ana", UserID: ana, Start: 3, End: 7}}
+		if !reflect.DeepEqual(post.Entities.Mentions, expected) {
+			t.Errorf("Expected %v, got %v", expected, post.Entities.Mentions)
+		}
+	})
+
+	t.Run("Lists posts mentioning a user", func(t *testing.T) {
+		posts := s.MentionsOf(ctx, ana)
+		if len(posts) != 2 || posts[0].ID != second || posts[1].ID != first {
+			t.Errorf("Expected posts %d and %d, got %v", second, first, posts)
+		}
+	})
+
+	t.Run("Edits update mentions", func(t *testing.T) {
+		s.Update(ctx, first, bob, "First", "Hi @bob")
+		if posts := s.MentionsOf(ctx, ana); len(posts) != 1 || posts[0].ID != second {
+			t.Errorf("Expected only post %d, got %v", second, posts)
+		}
+		if posts := s.MentionsOf(ctx, bob); len(posts) != 1 || posts[0].ID != first {
+			t.Errorf("Expected only post %d, got %v", first, posts)
+		}
+	})
+
+	t.Run("Deleted posts are unindexed", func(t *testing.T) {
+		s.Delete(ctx, second)
+		if posts := s.MentionsOf(ctx, ana); len(posts) != 0 {
+			t.Errorf("Expected no posts, got %v", posts)
+		}
+	})
+}
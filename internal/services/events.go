@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/events"
+	"example/api/internal/models"
+	"strconv"
+)
+
+// UserRegistered is published when a user registers.
+type UserRegistered struct {
+	User models.User
+}
+
+// UserUpdated is published when a user's profile changes.
+type UserUpdated struct {
+	User models.User
+	// Before is the user as it was before the change
+	Before models.User
+}
+
+// UserDeleted is published when a user is deleted, with the user as it was.
+type UserDeleted struct {
+	User models.User
+}
+
+// UserFollowed is published when a user starts following another.
+type UserFollowed struct {
+	FollowerID int
+	FolloweeID int
+}
+
+// PostCreated is published when a post is created.
+type PostCreated struct {
+	Post models.Post
+}
+
+// PostUpdated is published when a post changes: edits, status changes, tags and reverts.
+type PostUpdated struct {
+	Post models.Post
+	// Before is the post as it was before the change
+	Before models.Post
+}
+
+// PostDeleted is published when a post is deleted, with the post as it was.
+type PostDeleted struct {
+	Post models.Post
+}
+
+// PostReacted is published when a user adds a reaction to a post.
+type PostReacted struct {
+	Reaction models.Reaction
+}
+
+// CommentCreated is published when a comment is created. Comments belong to their post's aggregate.
+type CommentCreated struct {
+	Comment models.Comment
+}
+
+// CommentDeleted is published for a deleted comment and each of the replies deleted with it,
+// and for each comment removed along with its post, after the post's PostDeleted.
+type CommentDeleted struct {
+	Comment models.Comment
+	// Post is the deleted post the comment was removed along with, or nil
+	Post *models.Post
+}
+
+// EventName and AggregateID implement DomainEvent.
+
+func (UserRegistered) EventName() string { return "user.registered" }
+func (UserUpdated) EventName() string    { return "user.updated" }
+func (UserDeleted) EventName() string    { return "user.deleted" }
+func (UserFollowed) EventName() string   { return "user.followed" }
+func (PostCreated) EventName() string    { return "post.created" }
+func (PostUpdated) EventName() string    { return "post.updated" }
+func (PostDeleted) EventName() string    { return "post.deleted" }
+func (PostReacted) EventName() string    { return "post.reacted" }
+func (CommentCreated) EventName() string { return "comment.created" }
+func (CommentDeleted) EventName() string { return "comment.deleted" }
+
+func (e UserRegistered) AggregateID() string { return userAggregate(e.User.ID) }
+func (e UserUpdated) AggregateID() string    { return userAggregate(e.User.ID) }
+func (e UserDeleted) AggregateID() string    { return userAggregate(e.User.ID) }
+func (e UserFollowed) AggregateID() string   { return userAggregate(e.FollowerID) }
+func (e PostCreated) AggregateID() string    { return postAggregate(e.Post.ID) }
+func (e PostUpdated) AggregateID() string    { return postAggregate(e.Post.ID) }
+func (e PostDeleted) AggregateID() string    { return postAggregate(e.Post.ID) }
+func (e PostReacted) AggregateID() string    { return postAggregate(e.Reaction.PostID) }
+func (e CommentCreated) AggregateID() string { return postAggregate(e.Comment.PostID) }
+func (e CommentDeleted) AggregateID() string { return postAggregate(e.Comment.PostID) }
+
+func userAggregate(id int) string { return "user:" + strconv.Itoa(id) }
+func postAggregate(id int) string { return "post:" + strconv.Itoa(id) }
+
+// ForwardEvents relays the events of b that live consumers can see to p, such as the
+// log behind GET /events and the WebSocket channels. Events about posts that are not
+// published, and about their comments, are restricted to the post's author; posts is
+// used to look up the post of comment events.
+func ForwardEvents(b *Bus, posts *PostService, p events.Publisher) {
+	Subscribe(b, func(ctx context.Context, e DomainEvent) error {
+		ev, ok := streamEvent(e)
+		if !ok {
+			return nil
+		}
+		if ev.Type == events.CommentCreated || ev.Type == events.CommentDeleted {
+			post, err := commentPost(ctx, posts, e)
+			if err != nil {
+				return nil
+			}
+			ev.OwnerID = postOwner(post)
+		}
+		p.Publish(ctx, ev)
+		return nil
+	})
+}
+
+// commentPost returns the post of a comment event: the post deleted along with the comment,
+// if any, or else the post as it is now.
+func commentPost(ctx context.Context, posts *PostService, e DomainEvent) (models.Post, error) {
+	switch e := e.(type) {
+	case CommentCreated:
+		return posts.FindByID(ctx, e.Comment.PostID)
+	case CommentDeleted:
+		if e.Post != nil {
+			return *e.Post, nil
+		}
+		return posts.FindByID(ctx, e.Comment.PostID)
+	}
+	return models.Post{}, ErrPostNotFound
+}
+
+// postOwner returns the author of post if only they can see it, or zero if it is public.
+func postOwner(post models.Post) int {
+	if post.VisibleTo(0) {
+		return 0
+	}
+	return post.UserID
+}
+
+// streamEvent converts a domain event to its public form, reporting false for events
+// that are not streamed.
+func streamEvent(e DomainEvent) (events.Event, bool) {
+	switch e := e.(type) {
+	case UserRegistered:
+		// Everyone may follow registrations, but only the user sees their own email.
+		public := map[string]any{"id": e.User.ID, "name": e.User.Name, "handle": e.User.Handle}
+		private := map[string]any{"id": e.User.ID, "name": e.User.Name, "handle": e.User.Handle, "email": e.User.Email}
+		return events.Event{Type: events.UserRegistered, UserID: e.User.ID, Data: public, Private: private}, true
+	case UserDeleted:
+		return events.Event{Type: events.UserDeleted, UserID: e.User.ID, Data: map[string]int{"id": e.User.ID}}, true
+	case PostCreated:
+		return events.Event{Type: events.PostCreated, UserID: e.Post.UserID, PostID: e.Post.ID, Data: e.Post, OwnerID: postOwner(e.Post)}, true
+	case PostUpdated:
+		// Posts being published, and public posts being archived, are announced to everyone.
+		owner := postOwner(e.Post)
+		if e.Before.VisibleTo(0) {
+			owner = 0
+		}
+		return events.Event{Type: events.PostUpdated, UserID: e.Post.UserID, PostID: e.Post.ID, Data: e.Post, OwnerID: owner}, true
+	case PostDeleted:
+		return events.Event{Type: events.PostDeleted, UserID: e.Post.UserID, PostID: e.Post.ID, Data: map[string]int{"id": e.Post.ID, "user_id": e.Post.UserID}, OwnerID: postOwner(e.Post)}, true
+	case CommentCreated:
+		return events.Event{Type: events.CommentCreated, UserID: e.Comment.UserID, PostID: e.Comment.PostID, Data: e.Comment}, true
+	case CommentDeleted:
+		return events.Event{Type: events.CommentDeleted, UserID: e.Comment.UserID, PostID: e.Comment.PostID, Data: map[string]int{"id": e.Comment.ID, "post_id": e.Comment.PostID}}, true
+	}
+	return events.Event{}, false
+}
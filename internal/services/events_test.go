@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/events"
+	"example/api/internal/models"
+	"testing"
+)
+
+func TestServiceEvents(t *testing.T) {
+	// Initialize services publishing to a log
+	ctx := context.Background()
+	log := events.NewLog(10)
+	bus := NewBus()
+	users := NewUserService(bus)
+	posts := NewPostService(bus)
+	ForwardEvents(bus, posts, log)
+	comments := NewCommentService(posts)
+	sub := log.Subscribe(events.Filter{}, 0, events.DefaultBufferSize)
+	defer sub.Cancel()
+
+	userID, _ := users.Register(ctx, "Alice", "alice@example.com")
+	users.Register(ctx, "Alice", "alice@example.com")
+	postID, _ := posts.Create(ctx, "Title", "Content", userID)
+	// Reactions are not streamed
+	posts.React(ctx, postID, userID, models.ReactionLike)
+	posts.Update(ctx, postID, userID, "New title", "Content")
+	commentID, _ := comments.Create(ctx, postID, userID, "First", nil)
+	comments.Create(ctx, postID, userID, "Reply", &commentID)
+	comments.Delete(ctx, commentID)
+	comments.Create(ctx, postID, userID, "Second", nil)
+	posts.Delete(ctx, postID)
+	posts.Delete(ctx, postID)
+	users.Delete(ctx, userID)
+
+	expected := []events.Type{
+		events.UserRegistered, events.PostCreated, events.PostUpdated,
+		events.CommentCreated, events.CommentCreated, events.CommentDeleted, events.CommentDeleted,
+		events.CommentCreated, events.PostDeleted, events.CommentDeleted, events.UserDeleted,
+	}
+	for _, typ := range expected {
+		e := <-sub.C
+		if e.Type != typ || e.UserID != userID {
+			t.Errorf("Expected %s for user %d, got %s for user %d", typ, userID, e.Type, e.UserID)
+		}
+		if e.Type != events.UserRegistered && e.Type != events.UserDeleted && e.PostID != postID {
+			t.Errorf("Expected %s for post %d, got post %d", e.Type, postID, e.PostID)
+		}
+	}
+	select {
+	case e := <-sub.C:
+		t.Errorf("Expected no more events, got %s", e.Type)
+	default:
+	}
+}
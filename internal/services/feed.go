@@ -0,0 +1,239 @@
+package services
+
+import (
+	"container/heap"
+	"context"
+	"encoding/base64"
+	"errors"
+	"example/api/internal/models"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Feed page sizes.
+const (
+	DefaultFeedLimit = 20
+	MaxFeedLimit     = 100
+)
+
+// ErrInvalidCursor is returned when a feed cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// timelineEntry is a post in a time-ordered list, such as an author's published posts
+// or a user's bookmarks.
+type timelineEntry struct {
+	at     time.Time
+	postID int
+}
+
+// newer reports whether e comes before o in a timeline: most recent first,
+// with the post ID breaking ties so the order is total.
+func (e timelineEntry) newer(o timelineEntry) bool {
+	if !e.at.Equal(o.at) {
+		return e.at.After(o.at)
+	}
+	return e.postID > o.postID
+}
+
+// FeedService builds home feeds of posts from followed users.
+//
+// It keeps a timeline of published posts per author, newest first, updated as posts
+// change. A feed page is a k-way merge of the followed authors' timelines starting at
+// the cursor, so reading costs O(k + limit·log k) for k followed users no matter how
+// many posts they have written.
+type FeedService struct {
+	mu sync.RWMutex
+	// timelines maps an author to their published posts, newest first
+	timelines map[int][]timelineEntry
+	// authors maps an indexed post to its author
+	authors map[int]int
+	posts   *PostService
+	follows *FollowService
+}
+
+// NewFeedService creates a FeedService, indexes the current posts, and subscribes to post events
+// so later changes are indexed as they happen.
+func NewFeedService(posts *PostService, follows *FollowService) *FeedService {
+	s := &FeedService{
+		timelines: make(map[int][]timelineEntry),
+		authors:   make(map[int]int),
+		posts:     posts,
+		follows:   follows,
+	}
+	ctx := context.Background()
+	for _, p := range posts.List(ctx) {
+		s.indexPost(ctx, p)
+	}
+	Subscribe(posts.bus, func(ctx context.Context, e PostCreated) error {
+		s.indexPost(ctx, e.Post)
+		return nil
+	})
+	Subscribe(posts.bus, func(ctx context.Context, e PostUpdated) error {
+		s.indexPost(ctx, e.Post)
+		return nil
+	})
+	Subscribe(posts.bus, func(ctx context.Context, e PostDeleted) error {
+		s.removePost(ctx, e.Post.ID)
+		return nil
+	})
+	return s
+}
+
+// Feed returns a page of published posts by the users userID follows, most recently
+// published first. An empty cursor starts at the newest post; otherwise it must be a
+// NextCursor from a previous page. limit defaults to DefaultFeedLimit and is capped at MaxFeedLimit.
+// Returns ErrInvalidCursor if the cursor is malformed.
+func (s *FeedService) Feed(ctx context.Context, userID int, cursor string, limit int) (models.FeedPage, error) {
+	ctx, span := startSpan(ctx, "FeedService.Feed")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = DefaultFeedLimit
+	}
+	limit = min(limit, MaxFeedLimit)
+
+	var after *timelineEntry
+	if cursor != "" {
+		e, err := decodeCursor(cursor)
+		if err != nil {
+			return models.FeedPage{}, fail(span, err)
+		}
+		after = &e
+	}
+	authors := s.follows.FollowingIDs(ctx, userID)
+	span.SetAttributes(attribute.Int("feed.followees", len(authors)))
+
+	s.mu.RLock()
+	merge := startStorageSpan(ctx, "post_timelines", "merge")
+	h := make(timelineHeap, 0, len(authors))
+	for _, author := range authors {
+		timeline := s.timelines[author]
+		i := 0
+		if after != nil {
+			i = sort.Search(len(timeline), func(i int) bool { return after.newer(timeline[i]) })
+		}
+		if i < len(timeline) {
+			h = append(h, timelineCursor{timeline: timeline, i: i})
+		}
+	}
+	heap.Init(&h)
+	// Take one extra entry to learn whether another page follows.
+	entries := make([]timelineEntry, 0, limit+1)
+	for len(h) > 0 && len(entries) <= limit {
+		entries = append(entries, h[0].timeline[h[0].i])
+		h[0].i++
+		if h[0].i == len(h[0].timeline) {
+			heap.Pop(&h)
+		} else {
+			heap.Fix(&h, 0)
+		}
+	}
+	merge.End()
+	s.mu.RUnlock()
+
+	page := models.FeedPage{Posts: make([]models.Post, 0, min(limit, len(entries)))}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = encodeCursor(entries[limit-1])
+	}
+	for _, e := range entries {
+		// Skip posts unpublished or deleted since the timelines were read.
+		if post, err := s.posts.FindByID(ctx, e.postID); err == nil && post.Status == models.PostPublished {
+			page.Posts = append(page.Posts, post)
+		}
+	}
+	return page, nil
+}
+
+// indexPost adds a published post to its author's timeline, or removes it when it is not published.
+func (s *FeedService) indexPost(ctx context.Context, post models.Post) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	update := startStorageSpan(ctx, "post_timelines", "update")
+	defer update.End()
+	s.remove(post.ID)
+	if post.Status != models.PostPublished || post.PublishedAt == nil {
+		return
+	}
+	e := timelineEntry{at: *post.PublishedAt, postID: post.ID}
+	timeline := s.timelines[post.UserID]
+	i := sort.Search(len(timeline), func(i int) bool { return e.newer(timeline[i]) })
+	timeline = append(timeline, timelineEntry{})
+	copy(timeline[i+1:], timeline[i:])
+	timeline[i] = e
+	s.timelines[post.UserID] = timeline
+	s.authors[post.ID] = post.UserID
+}
+
+func (s *FeedService) removePost(ctx context.Context, postID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	del := startStorageSpan(ctx, "post_timelines", "delete")
+	defer del.End()
+	s.remove(postID)
+}
+
+// remove drops a post from its author's timeline. Callers must hold s.mu for writing.
+func (s *FeedService) remove(postID int) {
+	author, ok := s.authors[postID]
+	if !ok {
+		return
+	}
+	delete(s.authors, postID)
+	timeline := s.timelines[author]
+	for i, e := range timeline {
+		if e.postID == postID {
+			timeline = append(timeline[:i], timeline[i+1:]...)
+			break
+		}
+	}
+	if len(timeline) == 0 {
+		delete(s.timelines, author)
+		return
+	}
+	s.timelines[author] = timeline
+}
+
+// encodeCursor makes an opaque cursor pointing just past e.
+func encodeCursor(e timelineEntry) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%d", e.at.UnixNano(), e.postID))
+}
+
+func decodeCursor(cursor string) (timelineEntry, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return timelineEntry{}, ErrInvalidCursor
+	}
+	var nanos int64
+	var id int
+	if n, err := fmt.Sscanf(string(raw), "%d.%d", &nanos, &id); err != nil || n != 2 {
+		return timelineEntry{}, ErrInvalidCursor
+	}
+	return timelineEntry{at: time.Unix(0, nanos), postID: id}, nil
+}
+
+// timelineCursor is a position in one author's timeline.
+type timelineCursor struct {
+	timeline []timelineEntry
+	i        int
+}
+
+// timelineHeap orders timeline cursors by their current entry, newest first.
+type timelineHeap []timelineCursor
+
+func (h timelineHeap) Len() int           { return len(h) }
+func (h timelineHeap) Less(i, j int) bool { return h[i].timeline[h[i].i].newer(h[j].timeline[h[j].i]) }
+func (h timelineHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *timelineHeap) Push(x any)        { *h = append(*h, x.(timelineCursor)) }
+func (h *timelineHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFeedService(t *testing.T) {
+	// Initialize services with a controllable clock
+	ctx := context.Background()
+	users := NewUserService(NewBus())
+	posts := NewPostService(NewBus())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	posts.now = func() time.Time { return now }
+	follows := NewFollowService(users)
+	s := NewFeedService(posts, follows)
+
+	reader, _ := users.Register(ctx, "Reader", "reader@example.com")
+	var authors []int
+	for i := range 3 {
+		id, _ := users.Register(ctx, fmt.Sprintf("Author %d", i), fmt.Sprintf("author%d@example.com", i))
+		authors = append(authors, id)
+	}
+	stranger, _ := users.Register(ctx, "Stranger", "stranger@example.com")
+
+	// Interleave posts by the followed authors, plus posts that must never appear.
+	var expected []int
+	for i := range 9 {
+		now = now.Add(time.Minute)
+		id, _ := posts.Create(ctx, fmt.Sprintf("Post %d", i), "Content", authors[i%3])
+		expected = append([]int{id}, expected...)
+	}
+	posts.Create(ctx, "Not followed", "Content", stranger)
+	posts.Create(ctx, "Draft", "Content", authors[0], WithStatus(models.PostDraft))
+	for _, a := range authors {
+		follows.Follow(ctx, reader, a)
+	}
+
+	ids := func(page models.FeedPage) []int {
+		out := make([]int, 0, len(page.Posts))
+		for _, p := range page.Posts {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	t.Run("Pages through followed posts newest first", func(t *testing.T) {
+		var got []int
+		cursor := ""
+		pages := 0
+		for {
+			page, err := s.Feed(ctx, reader, cursor, 4)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			got = append(got, ids(page)...)
+			pages++
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+		if pages != 3 {
+			t.Errorf("Expected 3 pages, got %d", pages)
+		}
+	})
+
+	t.Run("Cursors stay stable when new posts arrive", func(t *testing.T) {
+		first, _ := s.Feed(ctx, reader, "", 2)
+		now = now.Add(time.Minute)
+		posts.Create(ctx, "Newest", "Content", authors[1])
+		second, _ := s.Feed(ctx, reader, first.NextCursor, 2)
+		if fmt.Sprint(ids(second)) != fmt.Sprint(expected[2:4]) {
+			t.Errorf("Expected %v, got %v", expected[2:4], ids(second))
+		}
+	})
+
+	t.Run("Archived and deleted posts leave the feed", func(t *testing.T) {
+		posts.Archive(ctx, expected[0])
+		posts.Delete(ctx, expected[1])
+		page, _ := s.Feed(ctx, reader, "", 2)
+		for _, id := range ids(page) {
+			if id == expected[0] || id == expected[1] {
+				t.Errorf("Expected post %d to be gone, got %v", id, ids(page))
+			}
+		}
+	})
+
+	t.Run("Unfollowing removes an author's posts", func(t *testing.T) {
+		follows.Unfollow(ctx, reader, authors[0])
+		page, _ := s.Feed(ctx, reader, "", MaxFeedLimit)
+		for _, p := range page.Posts {
+			if p.UserID == authors[0] {
+				t.Errorf("Expected no posts by author %d, got post %d", authors[0], p.ID)
+			}
+		}
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		if _, err := s.Feed(ctx, reader, "not a cursor", 10); err != ErrInvalidCursor {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
+		}
+	})
+}
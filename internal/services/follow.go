@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSelfFollow is returned when a user tries to follow themselves.
+var ErrSelfFollow = errors.New("users cannot follow themselves")
+
+// FollowService manages the social graph of users following other users.
+// Both directions of every edge are indexed so followers and followees can be listed
+// without scanning the whole graph. Edges are removed when either user is deleted.
+type FollowService struct {
+	mu sync.RWMutex
+	// following maps a follower to the users they follow and when they started
+	following map[int]map[int]time.Time
+	// followers maps a user to their followers and when they started
+	followers map[int]map[int]time.Time
+	users     *UserService
+	now       func() time.Time
+}
+
+// NewFollowService creates and returns a new instance of FollowService bound to the given user service.
+func NewFollowService(users *UserService) *FollowService {
+	s := &FollowService{
+		following: make(map[int]map[int]time.Time),
+		followers: make(map[int]map[int]time.Time),
+		users:     users,
+		now:       time.Now,
+	}
+	Subscribe(users.bus, s.userDeleted)
+	return s
+}
+
+// Follow makes followerID follow followeeID and publishes UserFollowed.
+// Following someone already followed is a no-op.
+// Returns ErrUserNotFound if either user doesn't exist, or ErrSelfFollow.
+func (s *FollowService) Follow(ctx context.Context, followerID int, followeeID int) error {
+	ctx, span := startSpan(ctx, "FollowService.Follow")
+	defer span.End()
+
+	if followerID == followeeID {
+		return fail(span, ErrSelfFollow)
+	}
+	for _, id := range []int{followerID, followeeID} {
+		if _, err := s.users.FindByID(ctx, id); err != nil {
+			return fail(span, err)
+		}
+	}
+
+	s.mu.Lock()
+	if _, ok := s.following[followerID][followeeID]; ok {
+		s.mu.Unlock()
+		return nil
+	}
+	insert := startStorageSpan(ctx, "follows", "insert")
+	now := s.now()
+	addEdge(s.following, followerID, followeeID, now)
+	addEdge(s.followers, followeeID, followerID, now)
+	insert.End()
+	s.mu.Unlock()
+
+	s.users.bus.Publish(ctx, UserFollowed{FollowerID: followerID, FolloweeID: followeeID})
+	return nil
+}
+
+// Unfollow makes followerID stop following followeeID.
+// Returns true if followerID was following followeeID, false otherwise.
+func (s *FollowService) Unfollow(ctx context.Context, followerID int, followeeID int) bool {
+	ctx, span := startSpan(ctx, "FollowService.Unfollow")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.following[followerID][followeeID]; !ok {
+		return false
+	}
+	del := startStorageSpan(ctx, "follows", "delete")
+	defer del.End()
+	removeEdge(s.following, followerID, followeeID)
+	removeEdge(s.followers, followeeID, followerID)
+	return true
+}
+
+// Followers returns the users following userID, most recent first.
+func (s *FollowService) Followers(ctx context.Context, userID int) ([]models.User, error) {
+	ctx, span := startSpan(ctx, "FollowService.Followers")
+	defer span.End()
+
+	users, err := s.list(ctx, s.followers, userID)
+	if err != nil {
+		return nil, fail(span, err)
+	}
+	return users, nil
+}
+
+// Following returns the users userID follows, most recent first.
+func (s *FollowService) Following(ctx context.Context, userID int) ([]models.User, error) {
+	ctx, span := startSpan(ctx, "FollowService.Following")
+	defer span.End()
+
+	users, err := s.list(ctx, s.following, userID)
+	if err != nil {
+		return nil, fail(span, err)
+	}
+	return users, nil
+}
+
+// FollowingIDs returns the IDs of the users userID follows, in no particular order.
+func (s *FollowService) FollowingIDs(ctx context.Context, userID int) []int {
+	ctx, span := startSpan(ctx, "FollowService.FollowingIDs")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "follows", "scan")
+	defer scan.End()
+	ids := make([]int, 0, len(s.following[userID]))
+	for id := range s.following[userID] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// list resolves the users on one side of userID's edges, most recent first.
+func (s *FollowService) list(ctx context.Context, edges map[int]map[int]time.Time, userID int) ([]models.User, error) {
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	type edge struct {
+		id    int
+		since time.Time
+	}
+	s.mu.RLock()
+	scan := startStorageSpan(ctx, "follows", "scan")
+	list := make([]edge, 0, len(edges[userID]))
+	for id, since := range edges[userID] {
+		list = append(list, edge{id, since})
+	}
+	scan.End()
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].since.Equal(list[j].since) {
+			return list[i].since.After(list[j].since)
+		}
+		return list[i].id > list[j].id
+	})
+	users := make([]models.User, 0, len(list))
+	for _, e := range list {
+		// Skip users deleted since the edges were read.
+		if u, err := s.users.FindByID(ctx, e.id); err == nil {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// userDeleted removes every edge touching a deleted user.
+func (s *FollowService) userDeleted(ctx context.Context, e UserDeleted) error {
+	userID := e.User.ID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	del := startStorageSpan(ctx, "follows", "delete")
+	defer del.End()
+	for followee := range s.following[userID] {
+		removeEdge(s.followers, followee, userID)
+	}
+	for follower := range s.followers[userID] {
+		removeEdge(s.following, follower, userID)
+	}
+	delete(s.following, userID)
+	delete(s.followers, userID)
+	return nil
+}
+
+func addEdge(edges map[int]map[int]time.Time, from int, to int, since time.Time) {
+	if edges[from] == nil {
+		edges[from] = make(map[int]time.Time)
+	}
+	edges[from][to] = since
+}
+
+func removeEdge(edges map[int]map[int]time.Time, from int, to int) {
+	delete(edges[from], to)
+	if len(edges[from]) == 0 {
+		delete(edges, from)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFollowService(t *testing.T) {
+	// Initialize services with a controllable clock
+	ctx := context.Background()
+	users := NewUserService(NewBus())
+	s := NewFollowService(users)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	alice, _ := users.Register(ctx, "Alice", "alice@example.com")
+	bob, _ := users.Register(ctx, "Bob", "bob@example.com")
+	carol, _ := users.Register(ctx, "Carol", "carol@example.com")
+
+	t.Run("Follow validates users", func(t *testing.T) {
+		if err := s.Follow(ctx, alice, alice); err != ErrSelfFollow {
+			t.Errorf("Expected ErrSelfFollow, got %v", err)
+		}
+		if err := s.Follow(ctx, alice, 99); err != ErrUserNotFound {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Followers are listed most recent first", func(t *testing.T) {
+		s.Follow(ctx, bob, alice)
+		now = now.Add(time.Minute)
+		s.Follow(ctx, carol, alice)
+		s.Follow(ctx, carol, alice)
+
+		followers, err := s.Followers(ctx, alice)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(followers) != 2 || followers[0].ID != carol || followers[1].ID != bob {
+			t.Errorf("Expected Carol then Bob, got %v", followers)
+		}
+		following, _ := s.Following(ctx, carol)
+		if len(following) != 1 || following[0].ID != alice {
+			t.Errorf("Expected Carol to follow Alice, got %v", following)
+		}
+	})
+
+	t.Run("Unfollow", func(t *testing.T) {
+		if !s.Unfollow(ctx, bob, alice) {
+			t.Error("Expected Bob to unfollow Alice")
+		}
+		if s.Unfollow(ctx, bob, alice) {
+			t.Error("Expected second unfollow to fail")
+		}
+		if ids := s.FollowingIDs(ctx, bob); len(ids) != 0 {
+			t.Errorf("Expected Bob to follow nobody, got %v", ids)
+		}
+	})
+
+	t.Run("Deleting a user removes their edges", func(t *testing.T) {
+		s.Follow(ctx, alice, carol)
+		users.Delete(ctx, carol)
+		if followers, _ := s.Followers(ctx, alice); len(followers) != 0 {
+			t.Errorf("Expected no followers, got %v", followers)
+		}
+		if ids := s.FollowingIDs(ctx, alice); len(ids) != 0 {
+			t.Errorf("Expected Alice to follow nobody, got %v", ids)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"example/api/internal/search"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Handle length limits, in characters.
+const (
+	MinHandleLength = 3
+	MaxHandleLength = 30
+)
+
+// ErrHandleTaken is returned when a handle already belongs to another user.
+var ErrHandleTaken = errors.New("handle already taken")
+
+// UserOption customizes a user at registration time.
+type UserOption func(*models.User)
+
+// WithHandle registers the user with the given handle instead of one derived from their name.
+func WithHandle(handle string) UserOption {
+	return func(u *models.User) {
+		u.Handle = handle
+	}
+}
+
+// NormalizeHandle lower-cases a handle and strips a leading '@'.
+// Handles must be MinHandleLength to MaxHandleLength characters long and may only contain
+// ASCII letters, digits and '_'.
+func NormalizeHandle(handle string) (string, error) {
+	handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+	if len(handle) < MinHandleLength || len(handle) > MaxHandleLength {
+		return "", fmt.Errorf("handle must be between %d and %d characters", MinHandleLength, MaxHandleLength)
+	}
+	for _, r := range handle {
+		if !isHandleChar(r) {
+			return "", fmt.Errorf("handle contains invalid character %q", r)
+		}
+	}
+	return handle, nil
+}
+
+// FindByHandle returns the user with the given handle, ignoring case and a leading '@'.
+func (s *UserService) FindByHandle(ctx context.Context, handle string) (models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.FindByHandle")
+	defer span.End()
+
+	handle = strings.ToLower(strings.TrimPrefix(handle, "@"))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "users", "scan")
+	defer scan.End()
+	for _, u := range s.users {
+		if u.Handle == handle {
+			return u, nil
+		}
+	}
+	return models.User{}, fail(span, ErrUserNotFound)
+}
+
+// ResolveHandles maps each of the given normalized handles that belongs to a user to
+// that user's ID. Unknown handles are left out.
+func (s *UserService) ResolveHandles(ctx context.Context, handles []string) map[string]int {
+	ctx, span := startSpan(ctx, "UserService.ResolveHandles")
+	defer span.End()
+
+	ids := make(map[string]int, len(handles))
+	if len(handles) == 0 {
+		return ids
+	}
+	wanted := make(map[string]bool, len(handles))
+	for _, h := range handles {
+		wanted[h] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "users", "scan")
+	defer scan.End()
+	for _, u := range s.users {
+		if wanted[u.Handle] {
+			ids[u.Handle] = u.ID
+		}
+	}
+	return ids
+}
+
+// handleTaken reports whether a user other than exceptID has the given handle.
+// Callers must hold s.mu.
+func (s *UserService) handleTaken(handle string, exceptID int) bool {
+	for _, u := range s.users {
+		if u.Handle == handle && u.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueHandle derives a free handle from a user's name, adding a numeric suffix
+// ("ana", "ana2", "ana3", ...) when needed. Callers must hold s.mu.
+func (s *UserService) uniqueHandle(name string) string {
+	base := handleFromName(name)
+	handle := base
+	for n := 2; s.handleTaken(handle, 0); n++ {
+		suffix := strconv.Itoa(n)
+		handle = base[:min(len(base), MaxHandleLength-len(suffix))] + suffix
+	}
+	return handle
+}
+
+// handleFromName turns a name into a handle candidate: lower-case ASCII letters and digits
+// with words joined by '_' ("José Núñez" becomes "jose_nunez"). Names too short to yield
+// a valid handle fall back to "user".
+func handleFromName(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range search.Fold(name) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			underscore = b.Len() > 0
+			continue
+		}
+		if underscore {
+			b.WriteByte('_')
+			underscore = false
+		}
+		b.WriteRune(r)
+	}
+	handle := strings.TrimSuffix(b.String()[:min(b.Len(), MaxHandleLength)], "_")
+	if len(handle) < MinHandleLength {
+		return "user"
+	}
+	return handle
+}
+
+func isHandleChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_'
+}
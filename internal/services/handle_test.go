@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHandles(t *testing.T) {
+	// Initialize service
+	ctx := context.Background()
+	s := NewUserService(NewBus())
+
+	t.Run("Handles are derived from names", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			expected string
+		}{
+			{"José Núñez", "jose_nunez"},
+			{"Jose Nunez", "jose_nunez2"},
+			{"Al", "user"},
+			{"日本語", "user2"},
+			{strings.Repeat("a", 40), strings.Repeat("a", MaxHandleLength)},
+			{strings.Repeat("a", 40) + "!", strings.Repeat("a", MaxHandleLength-1) + "2"},
+		}
+		for i, tt := range tests {
+			id, err := s.Register(ctx, tt.name, tt.name+"@example.com")
+			if err != nil {
+				t.Fatalf("Register(%q): expected no error, got %v", tt.name, err)
+			}
+			user, _ := s.FindByID(ctx, id)
+			if user.Handle != tt.expected {
+				t.Errorf("Case %d, %q: expected handle %q, got %q", i, tt.name, tt.expected, user.Handle)
+			}
+		}
+	})
+
+	t.Run("Chosen handles are validated", func(t *testing.T) {
+		id, err := s.Register(ctx, "Bob", "bob@example.com", WithHandle("@Bob_99"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if user, _ := s.FindByHandle(ctx, "@BOB_99"); user.ID != id || user.Handle != "bob_99" {
+			t.Errorf("Expected bob_99 to find user %d, got %v", id, user)
+		}
+		if _, err := s.Register(ctx, "Robert", "robert@example.com", WithHandle("BOB_99")); err != ErrHandleTaken {
+			t.Errorf("Expected ErrHandleTaken, got %v", err)
+		}
+		for _, handle := range []string{"ab", "bob-smith", "bób", strings.Repeat("b", MaxHandleLength+1)} {
+			if _, err := s.Register(ctx, "Bad", handle+"@example.com", WithHandle(handle)); err == nil {
+				t.Errorf("Expected handle %q to be rejected", handle)
+			}
+		}
+	})
+
+	t.Run("Handles can be changed", func(t *testing.T) {
+		id, _ := s.Register(ctx, "Carol", "carol@example.com")
+		taken := "bob_99"
+		if _, err := s.UpdateProfile(ctx, id, ProfileUpdate{Handle: &taken}); err != ErrHandleTaken {
+			t.Errorf("Expected ErrHandleTaken, got %v", err)
+		}
+		free := "Carol_Writes"
+		user, err := s.UpdateProfile(ctx, id, ProfileUpdate{Handle: &free})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if user.Handle != "carol_writes" {
+			t.Errorf("Expected carol_writes, got %q", user.Handle)
+		}
+		ids := s.ResolveHandles(ctx, []string{"carol", "carol_writes", "bob_99"})
+		if len(ids) != 2 || ids["carol_writes"] != id {
+			t.Errorf("Expected carol_writes and bob_99 to resolve, got %v", ids)
+		}
+	})
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Notification page sizes and retention.
+const (
+	DefaultNotificationLimit = 20
+	MaxNotificationLimit     = 100
+	// MaxNotificationsPerUser is the number of notifications kept per user; older ones are dropped.
+	MaxNotificationsPerUser = 500
+)
+
+// ErrInvalidNotificationType is returned for categories outside models.NotificationTypes.
+var ErrInvalidNotificationType = errors.New("invalid notification type")
+
+// NotificationService records notifications for users when others comment on their posts,
+// reply to their comments, mention them, follow them or react to their posts.
+//
+// Events arrive from the buses of the post and user services. An event of the same type
+// about the same subject as an unread notification is folded into it, so twenty reactions
+// to a post make one notification rather than twenty, and repeating an event (reacting
+// again with another type, following again after unfollowing) does not count twice.
+type NotificationService struct {
+	mu sync.RWMutex
+	// notifications maps a user to their notifications, oldest first
+	notifications map[int][]models.Notification
+	muted         map[int]map[models.NotificationType]bool
+	// mentioned records, per post, the users already notified of a mention in it
+	mentioned map[int]map[int]bool
+	nextID    int
+	posts     *PostService
+	users     *UserService
+	comments  *CommentService
+	now       func() time.Time
+}
+
+// NewNotificationService creates a NotificationService and subscribes to the events that feed it.
+func NewNotificationService(posts *PostService, users *UserService, comments *CommentService) *NotificationService {
+	s := &NotificationService{
+		notifications: make(map[int][]models.Notification),
+		muted:         make(map[int]map[models.NotificationType]bool),
+		mentioned:     make(map[int]map[int]bool),
+		nextID:        1,
+		posts:         posts,
+		users:         users,
+		comments:      comments,
+		now:           time.Now,
+	}
+	Subscribe(posts.bus, s.commented)
+	Subscribe(posts.bus, func(ctx context.Context, e PostCreated) error {
+		s.postSaved(ctx, e.Post)
+		return nil
+	})
+	Subscribe(posts.bus, func(ctx context.Context, e PostUpdated) error {
+		s.postSaved(ctx, e.Post)
+		return nil
+	})
+	Subscribe(posts.bus, s.reacted)
+	Subscribe(posts.bus, s.postDeleted)
+	Subscribe(users.bus, s.followed)
+	Subscribe(users.bus, s.userDeleted)
+	return s
+}
+
+// List returns a page of userID's notifications, most recently updated first.
+// With unreadOnly set, read notifications are left out. limit defaults to
+// DefaultNotificationLimit and is capped at MaxNotificationLimit.
+func (s *NotificationService) List(ctx context.Context, userID int, unreadOnly bool, limit int, offset int) models.NotificationPage {
+	ctx, span := startSpan(ctx, "NotificationService.List")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = DefaultNotificationLimit
+	}
+	limit = min(limit, MaxNotificationLimit)
+	offset = max(offset, 0)
+
+	s.mu.RLock()
+	scan := startStorageSpan(ctx, "notifications", "scan")
+	matched := make([]models.Notification, 0)
+	unread := 0
+	for _, n := range s.notifications[userID] {
+		if !n.Read {
+			unread++
+		}
+		if !unreadOnly || !n.Read {
+			matched = append(matched, n)
+		}
+	}
+	scan.End()
+	s.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].UpdatedAt.Equal(matched[j].UpdatedAt) {
+			return matched[i].UpdatedAt.After(matched[j].UpdatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	page := models.NotificationPage{Total: len(matched), Unread: unread}
+	matched = matched[min(offset, len(matched)):min(offset+limit, len(matched))]
+	for i := range matched {
+		matched[i].Message = s.message(ctx, matched[i])
+	}
+	page.Notifications = matched
+	return page
+}
+
+// MarkRead marks the given notifications of userID as read; IDs of other users'
+// notifications or unknown IDs are ignored. Returns the number of unread notifications left.
+func (s *NotificationService) MarkRead(ctx context.Context, userID int, ids []int) int {
+	ctx, span := startSpan(ctx, "NotificationService.MarkRead")
+	defer span.End()
+
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return s.markRead(ctx, userID, func(n models.Notification) bool { return set[n.ID] })
+}
+
+// MarkAllRead marks every notification of userID as read.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int) {
+	ctx, span := startSpan(ctx, "NotificationService.MarkAllRead")
+	defer span.End()
+
+	s.markRead(ctx, userID, func(models.Notification) bool { return true })
+}
+
+// Preferences returns userID's notification settings.
+func (s *NotificationService) Preferences(ctx context.Context, userID int) models.NotificationPreferences {
+	ctx, span := startSpan(ctx, "NotificationService.Preferences")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	get := startStorageSpan(ctx, "notification_preferences", "get")
+	defer get.End()
+	return s.preferences(userID)
+}
+
+// SetPreferences replaces userID's notification settings. Muting a category stops new
+// notifications of that type; existing ones are kept.
+// Returns the stored settings or ErrInvalidNotificationType.
+func (s *NotificationService) SetPreferences(ctx context.Context, userID int, prefs models.NotificationPreferences) (models.NotificationPreferences, error) {
+	ctx, span := startSpan(ctx, "NotificationService.SetPreferences")
+	defer span.End()
+
+	muted := make(map[models.NotificationType]bool, len(prefs.Muted))
+	for _, t := range prefs.Muted {
+		if !slices.Contains(models.NotificationTypes, t) {
+			return models.NotificationPreferences{}, fail(span, fmt.Errorf("%w: %q", ErrInvalidNotificationType, t))
+		}
+		muted[t] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	update := startStorageSpan(ctx, "notification_preferences", "update")
+	defer update.End()
+	if len(muted) == 0 {
+		delete(s.muted, userID)
+	} else {
+		s.muted[userID] = muted
+	}
+	return s.preferences(userID), nil
+}
+
+// notify records that actorID caused an event of type t for recipientID, folding it into
+// an unread notification about the same subject when there is one. Users are never
+// notified of their own actions or of muted categories.
+func (s *NotificationService) notify(ctx context.Context, recipientID int, actorID int, t models.NotificationType, postID int, commentID int) {
+	if recipientID == 0 || recipientID == actorID {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.muted[recipientID][t] {
+		return
+	}
+	now := s.now()
+	list := s.notifications[recipientID]
+	for i, n := range list {
+		if n.Read || n.Type != t || n.PostID != postID || n.CommentID != commentID {
+			continue
+		}
+		if slices.Contains(n.ActorIDs, actorID) {
+			return
+		}
+		update := startStorageSpan(ctx, "notifications", "update")
+		// The actor IDs are copied because notifications handed out earlier share the slice.
+		n.ActorIDs = append([]int{actorID}, n.ActorIDs...)
+		n.UpdatedAt = now
+		list[i] = n
+		update.End()
+		return
+	}
+
+	insert := startStorageSpan(ctx, "notifications", "insert")
+	defer insert.End()
+	list = append(list, models.Notification{
+		ID:        s.nextID,
+		UserID:    recipientID,
+		Type:      t,
+		PostID:    postID,
+		CommentID: commentID,
+		ActorIDs:  []int{actorID},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	s.nextID++
+	if len(list) > MaxNotificationsPerUser {
+		list = slices.Delete(list, 0, len(list)-MaxNotificationsPerUser)
+	}
+	s.notifications[recipientID] = list
+}
+
+// commented notifies the author of the post and, for replies, the author of the parent comment.
+func (s *NotificationService) commented(ctx context.Context, e CommentCreated) error {
+	comment := e.Comment
+	post, err := s.posts.FindByID(ctx, comment.PostID)
+	if err != nil {
+		return nil
+	}
+	s.notify(ctx, post.UserID, comment.UserID, models.NotificationComment, post.ID, 0)
+	if comment.ParentID == nil {
+		return nil
+	}
+	if parent, err := s.comments.FindByID(ctx, *comment.ParentID); err == nil && parent.UserID != post.UserID {
+		s.notify(ctx, parent.UserID, comment.UserID, models.NotificationComment, post.ID, parent.ID)
+	}
+	return nil
+}
+
+// postSaved notifies the users mentioned in a published post, once per post and user,
+// so edits and republishing do not repeat the notification.
+func (s *NotificationService) postSaved(ctx context.Context, post models.Post) {
+	if post.Status != models.PostPublished || len(post.Entities.Mentions) == 0 {
+		return
+	}
+	s.mu.Lock()
+	recipients := make([]int, 0, len(post.Entities.Mentions))
+	for _, m := range post.Entities.Mentions {
+		if s.mentioned[post.ID][m.UserID] {
+			continue
+		}
+		if s.mentioned[post.ID] == nil {
+			s.mentioned[post.ID] = make(map[int]bool)
+		}
+		s.mentioned[post.ID][m.UserID] = true
+		recipients = append(recipients, m.UserID)
+	}
+	s.mu.Unlock()
+
+	for _, id := range recipients {
+		s.notify(ctx, id, post.UserID, models.NotificationMention, post.ID, 0)
+	}
+}
+
+func (s *NotificationService) reacted(ctx context.Context, e PostReacted) error {
+	if post, err := s.posts.FindByID(ctx, e.Reaction.PostID); err == nil {
+		s.notify(ctx, post.UserID, e.Reaction.UserID, models.NotificationReaction, post.ID, 0)
+	}
+	return nil
+}
+
+func (s *NotificationService) followed(ctx context.Context, e UserFollowed) error {
+	s.notify(ctx, e.FolloweeID, e.FollowerID, models.NotificationFollow, 0, 0)
+	return nil
+}
+
+// postDeleted drops the notifications about a deleted post.
+func (s *NotificationService) postDeleted(ctx context.Context, e PostDeleted) error {
+	postID := e.Post.ID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	del := startStorageSpan(ctx, "notifications", "delete")
+	defer del.End()
+	for userID, list := range s.notifications {
+		s.notifications[userID] = slices.DeleteFunc(list, func(n models.Notification) bool { return n.PostID == postID })
+	}
+	delete(s.mentioned, postID)
+	return nil
+}
+
+// userDeleted drops the notifications and preferences of a deleted user.
+func (s *NotificationService) userDeleted(ctx context.Context, e UserDeleted) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	del := startStorageSpan(ctx, "notifications", "delete")
+	defer del.End()
+	delete(s.notifications, e.User.ID)
+	delete(s.muted, e.User.ID)
+	return nil
+}
+
+// markRead marks the notifications of userID matching fn as read and returns the number
+// of unread notifications left.
+func (s *NotificationService) markRead(ctx context.Context, userID int, fn func(models.Notification) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	update := startStorageSpan(ctx, "notifications", "update")
+	defer update.End()
+	unread := 0
+	list := s.notifications[userID]
+	for i := range list {
+		if !list[i].Read && fn(list[i]) {
+			list[i].Read = true
+		}
+		if !list[i].Read {
+			unread++
+		}
+	}
+	return unread
+}
+
+// preferences builds userID's settings from the muted set. Callers must hold s.mu.
+func (s *NotificationService) preferences(userID int) models.NotificationPreferences {
+	prefs := models.NotificationPreferences{Muted: []models.NotificationType{}}
+	for _, t := range models.NotificationTypes {
+		if s.muted[userID][t] {
+			prefs.Muted = append(prefs.Muted, t)
+		}
+	}
+	return prefs
+}
+
+// message summarizes a notification, naming the actor when there is only one.
+func (s *NotificationService) message(ctx context.Context, n models.Notification) string {
+	var action string
+	switch {
+	case n.Type == models.NotificationComment && n.CommentID != 0:
+		action = "replied to your comment"
+	case n.Type == models.NotificationComment:
+		action = "commented on your post"
+	case n.Type == models.NotificationMention:
+		action = "mentioned you in a post"
+	case n.Type == models.NotificationFollow:
+		action = "started following you"
+	case n.Type == models.NotificationReaction:
+		action = "reacted to your post"
+	}
+	if len(n.ActorIDs) > 1 {
+		return fmt.Sprintf("%d people %s", len(n.ActorIDs), action)
+	}
+	actor, err := s.users.FindByID(ctx, n.ActorIDs[0])
+	if err != nil {
+		return "Someone " + action
+	}
+	return "@" + actor.Handle + " " + action
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNotificationService(t *testing.T) {
+	// Initialize services with a controllable clock
+	ctx := context.Background()
+	users := NewUserService(NewBus())
+	posts := NewPostService(NewBus())
+	posts.ResolveMentionsWith(users.ResolveHandles)
+	comments := NewCommentService(posts)
+	follows := NewFollowService(users)
+	s := NewNotificationService(posts, users, comments)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	author, _ := users.Register(ctx, "Author", "author@example.com")
+	ana, _ := users.Register(ctx, "Ana", "ana@example.com")
+	bob, _ := users.Register(ctx, "Bob", "bob@example.com")
+	carol, _ := users.Register(ctx, "Carol", "carol@example.com")
+	post, _ := posts.Create(ctx, "Post", "Content", author)
+
+	latest := func(userID int) models.Notification {
+		page := s.List(ctx, userID, false, 1, 0)
+		if len(page.Notifications) == 0 {
+			t.Fatalf("Expected a notification for user %d", userID)
+		}
+		return page.Notifications[0]
+	}
+
+	t.Run("Reactions are aggregated and deduplicated", func(t *testing.T) {
+		posts.React(ctx, post, ana, models.ReactionLike)
+		if n := latest(author); n.Message != "@ana reacted to your post" {
+			t.Errorf("Expected single actor message, got %q", n.Message)
+		}
+		now = now.Add(time.Minute)
+		posts.React(ctx, post, bob, models.ReactionLike)
+		posts.React(ctx, post, ana, models.ReactionLove)
+		posts.React(ctx, post, carol, models.ReactionWow)
+		posts.React(ctx, post, author, models.ReactionLike)
+
+		page := s.List(ctx, author, false, 10, 0)
+		if page.Total != 1 || page.Unread != 1 {
+			t.Fatalf("Expected 1 unread notification, got total %d and unread %d", page.Total, page.Unread)
+		}
+		n := page.Notifications[0]
+		if n.Message != "3 people reacted to your post" {
+			t.Errorf("Expected aggregated message, got %q", n.Message)
+		}
+		if !reflect.DeepEqual(n.ActorIDs, []int{carol, bob, ana}) {
+			t.Errorf("Expected actors %v, got %v", []int{carol, bob, ana}, n.ActorIDs)
+		}
+	})
+
+	t.Run("Read notifications are not aggregated", func(t *testing.T) {
+		if unread := s.MarkRead(ctx, author, []int{latest(author).ID}); unread != 0 {
+			t.Errorf("Expected no unread notifications, got %d", unread)
+		}
+		now = now.Add(time.Minute)
+		posts.Unreact(ctx, post, ana, models.ReactionLike)
+		posts.React(ctx, post, ana, models.ReactionLike)
+		page := s.List(ctx, author, true, 10, 0)
+		if page.Total != 1 || page.Notifications[0].Message != "@ana reacted to your post" {
+			t.Errorf("Expected a new unread notification, got %v", page.Notifications)
+		}
+	})
+
+	t.Run("Comments and replies", func(t *testing.T) {
+		s.MarkAllRead(ctx, author)
+		parent, _ := comments.Create(ctx, post, ana, "First", nil)
+		comments.Create(ctx, post, bob, "Reply", &parent)
+
+		if n := latest(author); n.Type != models.NotificationComment || n.Message != "2 people commented on your post" {
+			t.Errorf("Expected aggregated comment notification, got %+v", n)
+		}
+		if n := latest(ana); n.CommentID != parent || n.Message != "@bob replied to your comment" {
+			t.Errorf("Expected reply notification, got %+v", n)
+		}
+	})
+
+	t.Run("Mentions notify once per post", func(t *testing.T) {
+		id, _ := posts.Create(ctx, "Hello", "Hi @carol", bob)
+		posts.Update(ctx, id, bob, "Hello", "Hi again @carol")
+		page := s.List(ctx, carol, false, 10, 0)
+		if page.Total != 1 || page.Notifications[0].Type != models.NotificationMention || page.Notifications[0].PostID != id {
+			t.Errorf("Expected one mention notification, got %v", page.Notifications)
+		}
+
+		draft, _ := posts.Create(ctx, "Draft", "Hi @ana", bob, WithStatus(models.PostDraft))
+		if n := latest(ana); n.PostID == draft {
+			t.Error("Expected no notification for a draft")
+		}
+		posts.Publish(ctx, draft, nil)
+		if n := latest(ana); n.PostID != draft || n.Type != models.NotificationMention {
+			t.Errorf("Expected mention notification once published, got %+v", n)
+		}
+	})
+
+	t.Run("Muted categories are not recorded", func(t *testing.T) {
+		prefs, err := s.SetPreferences(ctx, carol, models.NotificationPreferences{Muted: []models.NotificationType{models.NotificationFollow}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !reflect.DeepEqual(s.Preferences(ctx, carol), prefs) {
+			t.Errorf("Expected stored preferences %v, got %v", prefs, s.Preferences(ctx, carol))
+		}
+		follows.Follow(ctx, ana, carol)
+		if page := s.List(ctx, carol, false, 10, 0); page.Total != 1 {
+			t.Errorf("Expected follow to be muted, got %v", page.Notifications)
+		}
+		if _, err := s.SetPreferences(ctx, carol, models.NotificationPreferences{Muted: []models.NotificationType{"spam"}}); err == nil {
+			t.Error("Expected invalid category to be rejected")
+		}
+	})
+
+	t.Run("Deleting a post drops its notifications", func(t *testing.T) {
+		posts.Delete(ctx, post)
+		for _, n := range s.List(ctx, author, false, 100, 0).Notifications {
+			if n.PostID == post {
+				t.Errorf("Expected notifications about post %d to be removed, got %+v", post, n)
+			}
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Outbox holds the events of a service until they are published on its Bus.
+//
+// A service adds an event while it still holds the lock that guards the change the event
+// describes, so the event is stored if and only if the change is: a change can no longer be
+// saved without its event, or announced without being saved. Once the change is committed
+// the service calls Relay, which publishes the pending entries in the order they were added.
+//
+// Entries are removed only after Publish returns, when the synchronous subscribers have
+// handled the event and the asynchronous ones have it queued, so an event whose relay was
+// interrupted is published again by the next Relay, such as the one made by RunRelay.
+// Subscribers therefore see each event at least once; EventID identifies repeats. Those
+// whose work must be stored before the event is dropped, such as the WebhookService
+// recording deliveries, subscribe synchronously.
+//
+// Like the services' data, entries are kept in memory only: the outbox keeps changes and
+// events consistent while the process runs, but neither survives a crash or restart.
+type Outbox struct {
+	name string
+	bus  *Bus
+	mu   sync.Mutex
+	// entries are the events not yet published, oldest first
+	entries []OutboxEntry
+	nextSeq uint64
+	// relayed is the sequence number of the last published entry
+	relayed  uint64
+	relaying bool
+	// done is signalled whenever an entry has been published or a relay ends
+	done *sync.Cond
+	now  func() time.Time
+}
+
+// OutboxEntry is an event waiting in an Outbox.
+type OutboxEntry struct {
+	// ID identifies the event across every relay attempt, such as "posts:17"
+	ID string
+	// Event is the event to publish
+	Event DomainEvent
+	// CreatedAt is when the event was added
+	CreatedAt time.Time
+	// Attempts counts the relays that started publishing the entry
+	Attempts int
+
+	seq uint64
+	// ctx is the context of the change, so relays by other goroutines keep its trace
+	ctx context.Context
+}
+
+// NewOutbox creates an Outbox that relays to bus. name prefixes the IDs of its entries
+// and must be unique among the outboxes publishing on the same bus.
+func NewOutbox(name string, bus *Bus) *Outbox {
+	o := &Outbox{name: name, bus: bus, now: time.Now}
+	o.done = sync.NewCond(&o.mu)
+	return o
+}
+
+// Add stores e for publication. Callers add the event in the same critical section as the
+// change it describes and call Relay once the change is committed.
+func (o *Outbox) Add(ctx context.Context, e DomainEvent) {
+	insert := startStorageSpan(ctx, "outbox", "insert")
+	defer insert.End()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.nextSeq++
+	o.entries = append(o.entries, OutboxEntry{
+		ID:        o.name + ":" + strconv.FormatUint(o.nextSeq, 10),
+		Event:     e,
+		CreatedAt: o.now(),
+		seq:       o.nextSeq,
+		ctx:       context.WithoutCancel(ctx),
+	})
+}
+
+// Relay publishes the pending entries in order and returns how many it published.
+//
+// When another goroutine is already relaying, Relay waits until the entries pending at the
+// time of the call have been published, so a service method returns only after the
+// synchronous subscribers of its events have run. Inside a subscriber, Relay does not wait:
+// events of changes made by subscribers are published after the event being delivered.
+func (o *Outbox) Relay(ctx context.Context) int {
+	nested := ctx.Value(eventIDKey{}) != nil
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	target := o.nextSeq
+	for o.relaying && o.relayed < target {
+		if nested {
+			return 0
+		}
+		o.done.Wait()
+	}
+	if o.relayed >= target || len(o.entries) == 0 {
+		return 0
+	}
+
+	o.relaying = true
+	defer func() {
+		o.relaying = false
+		o.done.Broadcast()
+	}()
+	n := 0
+	for len(o.entries) > 0 {
+		o.entries[0].Attempts++
+		entry := o.entries[0]
+		o.mu.Unlock()
+		o.publish(entry)
+		o.mu.Lock()
+		o.entries = slices.Delete(o.entries, 0, 1)
+		o.relayed = entry.seq
+		n++
+		o.done.Broadcast()
+	}
+	return n
+}
+
+// publish delivers one entry on the bus with its ID in the context.
+func (o *Outbox) publish(entry OutboxEntry) {
+	ctx, span := startSpan(entry.ctx, "Outbox.relay")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("outbox.entry_id", entry.ID),
+		attribute.Int("outbox.attempts", entry.Attempts),
+	)
+	o.bus.Publish(context.WithValue(ctx, eventIDKey{}, entry.ID), entry.Event)
+}
+
+// RunRelay relays pending entries every interval until ctx is cancelled. Services relay
+// their own events as they commit them; this catches entries whose relay was interrupted.
+func (o *Outbox) RunRelay(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := o.Relay(ctx); n > 0 {
+				log.Printf("Relayed %d %s outbox event(s)", n, o.name)
+			}
+		}
+	}
+}
+
+// Pending returns the entries not yet published, oldest first.
+func (o *Outbox) Pending() []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return slices.Clone(o.entries)
+}
+
+type eventIDKey struct{}
+
+// EventID returns the outbox ID of the event being delivered to a subscriber, which is the same
+// each time the event is published. Subscribers with side effects that must not repeat, such
+// as sending webhooks, use it to ignore events they have already handled.
+// The second result is false for events that did not come from an Outbox.
+func EventID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(eventIDKey{}).(string)
+	return id, ok
+}
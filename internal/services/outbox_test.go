@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("entries are relayed in order with their IDs", func(t *testing.T) {
+		bus := NewBus()
+		o := NewOutbox("posts", bus)
+		var ids []string
+		Subscribe(bus, func(ctx context.Context, e DomainEvent) error {
+			id, _ := EventID(ctx)
+			ids = append(ids, id+" "+e.EventName())
+			// The entry stays in the outbox until every subscriber has run.
+			if pending := o.Pending(); len(pending) == 0 || pending[0].ID != id {
+				t.Errorf("Expected %s to be pending during delivery, got %+v", id, pending)
+			}
+			return nil
+		})
+
+		o.Add(ctx, PostCreated{})
+		o.Add(ctx, PostDeleted{})
+		if n := len(o.Pending()); n != 2 {
+			t.Fatalf("Expected 2 pending entries, got %d", n)
+		}
+		if n := o.Relay(ctx); n != 2 {
+			t.Errorf("Expected 2 entries relayed, got %d", n)
+		}
+		if n := o.Relay(ctx); n != 0 {
+			t.Errorf("Expected nothing left to relay, got %d", n)
+		}
+		expected := []string{"posts:1 post.created", "posts:2 post.deleted"}
+		if !reflect.DeepEqual(ids, expected) {
+			t.Errorf("Expected %v, got %v", expected, ids)
+		}
+		if _, ok := EventID(ctx); ok {
+			t.Error("Expected no event ID outside a delivery")
+		}
+	})
+
+	t.Run("events are recorded with the change", func(t *testing.T) {
+		bus := NewBus()
+		users := NewUserService(bus)
+		posts := NewPostService(bus)
+		var seen []string
+		Subscribe(bus, func(ctx context.Context, e DomainEvent) error {
+			id, _ := EventID(ctx)
+			seen = append(seen, id)
+			return nil
+		})
+
+		userID, _ := users.Register(ctx, "Ana", "ana@example.com")
+		postID, _ := posts.Create(ctx, "Title", "Content", userID)
+		posts.Update(ctx, postID, userID, "New title", "Content")
+		posts.Create(ctx, "", "", userID)
+		users.Delete(ctx, userID)
+
+		expected := []string{"users:1", "posts:1", "posts:2", "users:2"}
+		if !reflect.DeepEqual(seen, expected) {
+			t.Errorf("Expected events %v, got %v", expected, seen)
+		}
+		if n := len(posts.Outbox().Pending()) + len(users.Outbox().Pending()); n != 0 {
+			t.Errorf("Expected empty outboxes, got %d entries", n)
+		}
+	})
+
+	t.Run("changes made by subscribers are published afterwards", func(t *testing.T) {
+		bus := NewBus()
+		users := NewUserService(bus)
+		rec := Record(bus)
+		Subscribe(bus, func(ctx context.Context, e UserRegistered) error {
+			if e.User.Name == "Ana" {
+				_, err := users.Register(ctx, "Ana's assistant", "assistant@example.com")
+				return err
+			}
+			return nil
+		})
+		var names []string
+		Subscribe(bus, func(_ context.Context, e UserRegistered) error {
+			names = append(names, e.User.Name)
+			return nil
+		})
+
+		users.Register(ctx, "Ana", "ana@example.com")
+
+		rec.Expect(t, "user.registered", "user.registered")
+		if !reflect.DeepEqual(names, []string{"Ana", "Ana's assistant"}) {
+			t.Errorf("Expected the nested registration to be delivered second, got %v", names)
+		}
+	})
+
+	t.Run("methods return after their events are delivered", func(t *testing.T) {
+		bus := NewBus()
+		posts := NewPostService(bus)
+		var mu sync.Mutex
+		indexed := make(map[int]bool)
+		Subscribe(bus, func(_ context.Context, e PostCreated) error {
+			mu.Lock()
+			defer mu.Unlock()
+			indexed[e.Post.ID] = true
+			return nil
+		})
+
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				id, err := posts.Create(ctx, fmt.Sprintf("Post %d", i), "Content", 1)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if !indexed[id] {
+					t.Errorf("Expected post %d to be delivered before Create returned", id)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -1,81 +1,459 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"example/api/internal/models"
+	"example/api/internal/render"
+	"fmt"
+	"iter"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrPostNotFound is returned when no post exists with the requested ID.
+var ErrPostNotFound = errors.New("post not found")
+
+// ErrInvalidTransition is returned when a post cannot move from its current status to the requested one.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// postTransitions lists the statuses each status may move to.
+var postTransitions = map[models.PostStatus][]models.PostStatus{
+	models.PostDraft:     {models.PostScheduled, models.PostPublished, models.PostArchived},
+	models.PostScheduled: {models.PostDraft, models.PostPublished, models.PostArchived},
+	models.PostPublished: {models.PostArchived},
+	models.PostArchived:  {models.PostDraft, models.PostPublished},
+}
+
 // PostService manages post-related operations such as creation, listing, finding, and deleting posts.
 // It maintains an in-memory collection of posts and handles post ID generation.
 type PostService struct {
-	posts  []models.Post
-	nextId int
+	mu        sync.RWMutex
+	posts     []models.Post
+	revisions map[int][]models.PostRevision
+	reactions map[int][]models.Reaction
+	tagIndex  map[string]map[int]struct{}
+	// mentionIndex maps a user to the posts mentioning them
+	mentionIndex    map[int]map[int]struct{}
+	slugs           map[string]int
+	nextId          int
+	resolveMentions func(ctx context.Context, handles []string) map[string]int
+	bus             *Bus
+	outbox          *Outbox
+	now             func() time.Time
 }
 
 // NewPostService creates and returns a new instance of PostService with initialized fields.
-func NewPostService() *PostService {
+// Changes to posts and reactions are published on bus through the service's outbox.
+func NewPostService(bus *Bus) *PostService {
 	return &PostService{
-		posts:  make([]models.Post, 0),
-		nextId: 1,
+		posts:        make([]models.Post, 0),
+		revisions:    make(map[int][]models.PostRevision),
+		reactions:    make(map[int][]models.Reaction),
+		tagIndex:     make(map[string]map[int]struct{}),
+		mentionIndex: make(map[int]map[int]struct{}),
+		slugs:        make(map[string]int),
+		nextId:       1,
+		bus:          bus,
+		outbox:       NewOutbox("posts", bus),
+		now:          time.Now,
+	}
+}
+
+// PostOption customizes a post at creation time.
+type PostOption func(*models.Post)
+
+// WithStatus creates the post in the given status instead of publishing it immediately.
+func WithStatus(status models.PostStatus) PostOption {
+	return func(p *models.Post) {
+		p.Status = status
+	}
+}
+
+// WithFormat sets the markup language of the post's content. Posts default to plain text.
+func WithFormat(format models.ContentFormat) PostOption {
+	return func(p *models.Post) {
+		p.Format = format
+	}
+}
+
+// WithPublishAt schedules the post for publication at t.
+func WithPublishAt(t time.Time) PostOption {
+	return func(p *models.Post) {
+		p.PublishAt = &t
+		if p.Status == "" {
+			p.Status = models.PostScheduled
+		}
+	}
+}
+
+// WithCreatedAt sets the post's creation time instead of the current time, for posts
+// brought over from elsewhere.
+func WithCreatedAt(t time.Time) PostOption {
+	return func(p *models.Post) {
+		p.CreatedAt = t
+		p.UpdatedAt = t
+	}
+}
+
+// WithPublishedAt sets the time the post was last published instead of the current time,
+// for posts brought over from elsewhere.
+func WithPublishedAt(t time.Time) PostOption {
+	return func(p *models.Post) {
+		p.PublishedAt = &t
 	}
 }
 
 // Create creates a new post with the given title, content, and user ID.
+// Posts are published immediately unless options request a draft or a scheduled publication,
+// get a unique slug derived from the title, and have their content rendered to HTML
+// and scanned for mentions and hashtags.
 // Returns the new post's ID and an error if creation fails.
-// Creation fails if title or content is empty, or if the user ID doesn't exist.
-func (s *PostService) Create(title string, content string, userID int) (int, error) {
+// Creation fails if title or content is empty, a tag or the content format is invalid, or the requested status is not valid for a new post.
+func (s *PostService) Create(ctx context.Context, title string, content string, userID int, opts ...PostOption) (int, error) {
+	ctx, span := startSpan(ctx, "PostService.Create")
+	defer span.End()
+
 	if title == "" || content == "" {
-		return 0, errors.New("title and content are required")
+		return 0, fail(span, errors.New("title and content are required"))
 	}
 
+	now := s.now()
 	post := models.Post{
-		ID:      s.nextId,
-		Title:   title,
-		Content: content,
-		UserID:  userID,
+		Title:     title,
+		Content:   content,
+		UserID:    userID,
+		Reactions: make(map[models.ReactionType]int),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, opt := range opts {
+		opt(&post)
+	}
+	tags, err := NormalizeTags(post.Tags)
+	if err != nil {
+		return 0, fail(span, err)
+	}
+	post.Tags = tags
+	if post.Format == "" {
+		post.Format = models.FormatPlain
+	}
+	if post.ContentHTML, err = render.HTML(post.Format, post.Content); err != nil {
+		return 0, fail(span, err)
+	}
+	post.Entities = s.entities(ctx, post.Content)
+	if post.Status == "" {
+		post.Status = models.PostPublished
+	}
+	if err := validateSchedule(post.Status, post.PublishAt, now); err != nil {
+		return 0, fail(span, err)
+	}
+	switch post.Status {
+	case models.PostPublished:
+		if post.PublishedAt == nil {
+			post.PublishedAt = &now
+		}
+	case models.PostDraft, models.PostScheduled:
+	default:
+		return 0, fail(span, fmt.Errorf("posts cannot be created with status %q", post.Status))
 	}
 
+	s.mu.Lock()
+	insert := startStorageSpan(ctx, "posts", "insert")
+	post.ID = s.nextId
+	s.assignSlug(&post)
 	s.posts = append(s.posts, post)
 	s.nextId++
+	insert.End()
+	s.indexTags(post)
+	s.indexMentions(post)
+	s.recordRevision(ctx, post, userID, nil)
+	s.outbox.Add(ctx, PostCreated{Post: post})
+	s.mu.Unlock()
+
+	s.outbox.Relay(ctx)
 	return post.ID, nil
 }
 
-// List returns all posts.
-func (s *PostService) List() []models.Post {
-	return s.posts
+// List returns all posts, whatever their status.
+func (s *PostService) List(ctx context.Context) []models.Post {
+	ctx, span := startSpan(ctx, "PostService.List")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "posts", "scan")
+	defer scan.End()
+	return append([]models.Post(nil), s.posts...)
+}
+
+// scanBatchSize is the number of resources All reads from a service at a time.
+const scanBatchSize = 100
+
+// All returns every post in ID order, whatever its status. Posts are read from the
+// service in batches, so iterating does not hold the service's lock or copy every post
+// at once; posts created or deleted meanwhile may or may not be seen.
+func (s *PostService) All(ctx context.Context) iter.Seq[models.Post] {
+	return func(yield func(models.Post) bool) {
+		ctx, span := startSpan(ctx, "PostService.All")
+		defer span.End()
+
+		after := 0
+		for {
+			s.mu.RLock()
+			scan := startStorageSpan(ctx, "posts", "scan")
+			i := sort.Search(len(s.posts), func(i int) bool { return s.posts[i].ID > after })
+			batch := slices.Clone(s.posts[i:min(i+scanBatchSize, len(s.posts))])
+			scan.End()
+			s.mu.RUnlock()
+
+			for _, p := range batch {
+				if !yield(p) {
+					return
+				}
+				after = p.ID
+			}
+			if len(batch) < scanBatchSize {
+				return
+			}
+		}
+	}
+}
+
+// sandbox returns an empty service publishing on a bus of its own that numbers posts
+// like s, on which creations can be tried without making them.
+func (s *PostService) sandbox() *PostService {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := NewPostService(NewBus())
+	c.nextId = s.nextId
+	c.resolveMentions = s.resolveMentions
+	c.now = s.now
+	return c
 }
 
 // FindByID searches for a post by its ID.
 // Returns the post if found, or an error if no post exists with the given ID.
-func (s *PostService) FindByID(id int) (models.Post, error) {
-	for _, p := range s.posts {
-		if p.ID == id {
-			return p, nil
-		}
+func (s *PostService) FindByID(ctx context.Context, id int) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.FindByID")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "posts", "scan")
+	defer scan.End()
+	if i := s.index(id); i >= 0 {
+		return s.posts[i], nil
 	}
-	return models.Post{}, errors.New("post not found")
+	return models.Post{}, fail(span, ErrPostNotFound)
 }
 
 // FindByUserID returns all posts for a specific user.
 // Returns an empty slice if no posts are found.
-func (s *PostService) FindByUserID(userID int) []models.Post {
+func (s *PostService) FindByUserID(ctx context.Context, userID int) []models.Post {
+	ctx, span := startSpan(ctx, "PostService.FindByUserID")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "posts", "scan")
+	defer scan.End()
 	var userPosts []models.Post
 	for _, p := range s.posts {
 		if p.UserID == userID {
 			userPosts = append(userPosts, p)
 		}
 	}
+	span.SetAttributes(attribute.Int("posts.count", len(userPosts)))
 	return userPosts
 }
 
+// Publish publishes a post now, or schedules it when publishAt is in the future.
+// Returns the updated post, ErrPostNotFound, or ErrInvalidTransition if the post's
+// current status cannot be published.
+func (s *PostService) Publish(ctx context.Context, id int, publishAt *time.Time) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.Publish")
+	defer span.End()
+
+	to := models.PostPublished
+	if publishAt != nil && publishAt.After(s.now()) {
+		to = models.PostScheduled
+	}
+	s.mu.Lock()
+	post, err := s.transition(ctx, id, to, publishAt)
+	s.mu.Unlock()
+	if err != nil {
+		return models.Post{}, fail(span, err)
+	}
+	s.outbox.Relay(ctx)
+	return post, nil
+}
+
+// Archive hides a post from everyone but its author.
+// Returns the updated post, ErrPostNotFound, or ErrInvalidTransition.
+func (s *PostService) Archive(ctx context.Context, id int) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.Archive")
+	defer span.End()
+
+	s.mu.Lock()
+	post, err := s.transition(ctx, id, models.PostArchived, nil)
+	s.mu.Unlock()
+	if err != nil {
+		return models.Post{}, fail(span, err)
+	}
+	s.outbox.Relay(ctx)
+	return post, nil
+}
+
+// PublishDue publishes every scheduled post whose publication time has passed.
+// Returns the number of posts published.
+func (s *PostService) PublishDue(ctx context.Context) int {
+	ctx, span := startSpan(ctx, "PostService.PublishDue")
+	defer span.End()
+
+	s.mu.Lock()
+	now := s.now()
+	var published []models.Post
+	for _, p := range s.posts {
+		if p.Status == models.PostScheduled && p.PublishAt != nil && !p.PublishAt.After(now) {
+			if post, err := s.transition(ctx, p.ID, models.PostPublished, nil); err == nil {
+				published = append(published, post)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	s.outbox.Relay(ctx)
+	span.SetAttributes(attribute.Int("posts.published", len(published)))
+	return len(published)
+}
+
+// RunScheduler publishes due scheduled posts every interval until ctx is cancelled.
+func (s *PostService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.PublishDue(ctx); n > 0 {
+				log.Printf("Published %d scheduled post(s)", n)
+			}
+		}
+	}
+}
+
 // Delete removes a post with the specified ID from the service.
 // Returns true if the post was found and deleted, false otherwise.
-func (s *PostService) Delete(id int) bool {
+// PostDeleted is published after the post is removed.
+func (s *PostService) Delete(ctx context.Context, id int) bool {
+	ctx, span := startSpan(ctx, "PostService.Delete")
+	defer span.End()
+
+	s.mu.Lock()
+	del := startStorageSpan(ctx, "posts", "delete")
+	i := s.index(id)
+	var post models.Post
+	if i >= 0 {
+		post = s.posts[i]
+		s.unindexTags(s.posts[i])
+		s.unindexMentions(s.posts[i])
+		s.releaseSlugs(id)
+		s.posts = append(s.posts[:i], s.posts[i+1:]...)
+		delete(s.revisions, id)
+		delete(s.reactions, id)
+		s.outbox.Add(ctx, PostDeleted{Post: post})
+	}
+	del.End()
+	s.mu.Unlock()
+
+	if i < 0 {
+		return false
+	}
+	s.outbox.Relay(ctx)
+	return true
+}
+
+// Outbox returns the outbox holding the service's events until they are published.
+func (s *PostService) Outbox() *Outbox {
+	return s.outbox
+}
+
+// transition moves a post to a new status if allowed and adds PostUpdated to the outbox.
+// Callers must hold s.mu for writing and relay the outbox after unlocking.
+func (s *PostService) transition(ctx context.Context, id int, to models.PostStatus, publishAt *time.Time) (models.Post, error) {
+	i := s.index(id)
+	if i < 0 {
+		return models.Post{}, ErrPostNotFound
+	}
+	before := s.posts[i]
+	post := before
+	if !canTransition(post.Status, to) {
+		return models.Post{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, post.Status, to)
+	}
+
+	now := s.now()
+	if err := validateSchedule(to, publishAt, now); err != nil {
+		return models.Post{}, err
+	}
+	post.Status = to
+	post.PublishAt = nil
+	switch to {
+	case models.PostScheduled:
+		post.PublishAt = publishAt
+	case models.PostPublished:
+		post.PublishedAt = &now
+	}
+	post.UpdatedAt = now
+
+	update := startStorageSpan(ctx, "posts", "update")
+	s.posts[i] = post
+	update.End()
+	s.outbox.Add(ctx, PostUpdated{Post: post, Before: before})
+	return post, nil
+}
+
+// index returns the position of the post with the given ID, or -1. Callers must hold s.mu.
+func (s *PostService) index(id int) int {
 	for i, p := range s.posts {
 		if p.ID == id {
-			s.posts = append(s.posts[:i], s.posts[i+1:]...)
+			return i
+		}
+	}
+	return -1
+}
+
+func canTransition(from models.PostStatus, to models.PostStatus) bool {
+	for _, allowed := range postTransitions[from] {
+		if allowed == to {
 			return true
 		}
 	}
 	return false
 }
+
+// validateSchedule checks that scheduled posts, and only they, carry a future publication time.
+func validateSchedule(status models.PostStatus, publishAt *time.Time, now time.Time) error {
+	if status == models.PostScheduled {
+		if publishAt == nil {
+			return errors.New("publish_at is required for scheduled posts")
+		}
+		if !publishAt.After(now) {
+			return errors.New("publish_at must be in the future")
+		}
+		return nil
+	}
+	if publishAt != nil && status != models.PostPublished {
+		return fmt.Errorf("publish_at cannot be set on %s posts", status)
+	}
+	return nil
+}
@@ -1,17 +1,21 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"example/api/internal/models"
 	"testing"
+	"time"
 )
 
 func TestPostService(t *testing.T) {
 	// Initialize service
-	s := NewPostService()
+	ctx := context.Background()
+	s := NewPostService(NewBus())
 
 	// Test Create
 	t.Run("Create valid post", func(t *testing.T) {
-		id, err := s.Create("Test Post", "This is a test post", 1)
+		id, err := s.Create(ctx, "Test Post", "This is a test post", 1)
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -21,12 +25,12 @@ func TestPostService(t *testing.T) {
 	})
 
 	t.Run("Create post with empty fields", func(t *testing.T) {
-		_, err := s.Create("", "This is a test post", 1)
+		_, err := s.Create(ctx, "", "This is a test post", 1)
 		if err == nil || err.Error() != "title and content are required" {
 			t.Errorf("Expected required fields error, got %v", err)
 		}
 
-		_, err = s.Create("Test Post", "", 1)
+		_, err = s.Create(ctx, "Test Post", "", 1)
 		if err == nil || err.Error() != "title and content are required" {
 			t.Errorf("Expected required fields error, got %v", err)
 		}
@@ -34,30 +38,30 @@ func TestPostService(t *testing.T) {
 
 	// Test List
 	t.Run("List posts", func(t *testing.T) {
-		posts := s.List()
+		posts := s.List(ctx)
 		if len(posts) != 1 {
 			t.Errorf("Expected 1 post, got %d", len(posts))
 		}
-		expected := models.Post{ID: 1, Title: "Test Post", Content: "This is a test post", UserID: 1}
-		if posts[0] != expected {
+		expected := models.Post{ID: 1, Title: "Test Post", Content: "This is a test post", UserID: 1, Status: models.PostPublished}
+		if !samePost(posts[0], expected) {
 			t.Errorf("Expected post %v, got %v", expected, posts[0])
 		}
 	})
 
 	// Test FindByID
 	t.Run("Find existing post", func(t *testing.T) {
-		post, err := s.FindByID(1)
+		post, err := s.FindByID(ctx, 1)
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
-		expected := models.Post{ID: 1, Title: "Test Post", Content: "This is a test post", UserID: 1}
-		if post != expected {
+		expected := models.Post{ID: 1, Title: "Test Post", Content: "This is a test post", UserID: 1, Status: models.PostPublished}
+		if !samePost(post, expected) {
 			t.Errorf("Expected post %v, got %v", expected, post)
 		}
 	})
 
 	t.Run("Find non-existent post", func(t *testing.T) {
-		_, err := s.FindByID(999)
+		_, err := s.FindByID(ctx, 999)
 		if err == nil || err.Error() != "post not found" {
 			t.Errorf("Expected post not found error, got %v", err)
 		}
@@ -66,17 +70,17 @@ func TestPostService(t *testing.T) {
 	// Test FindByUserID
 	t.Run("Find posts by user ID", func(t *testing.T) {
 		// Create another post for the same user
-		s.Create("Another Post", "This is another test post", 1)
+		s.Create(ctx, "Another Post", "This is another test post", 1)
 
-		posts := s.FindByUserID(1)
+		posts := s.FindByUserID(ctx, 1)
 		if len(posts) != 2 {
 			t.Errorf("Expected 2 posts, got %d", len(posts))
 		}
 
 		// Create a post for a different user
-		s.Create("Different User Post", "This is a post from another user", 2)
+		s.Create(ctx, "Different User Post", "This is a post from another user", 2)
 
-		posts = s.FindByUserID(2)
+		posts = s.FindByUserID(ctx, 2)
 		if len(posts) != 1 {
 			t.Errorf("Expected 1 post, got %d", len(posts))
 		}
@@ -84,18 +88,152 @@ func TestPostService(t *testing.T) {
 
 	// Test Delete
 	t.Run("Delete existing post", func(t *testing.T) {
-		if !s.Delete(1) {
+		if !s.Delete(ctx, 1) {
 			t.Error("Expected true, got false")
 		}
-		posts := s.List()
+		posts := s.List(ctx)
 		if len(posts) != 2 {
 			t.Errorf("Expected 2 posts after deletion, got %d", len(posts))
 		}
 	})
 
 	t.Run("Delete non-existent post", func(t *testing.T) {
-		if s.Delete(999) {
+		if s.Delete(ctx, 999) {
 			t.Error("Expected false, got true")
 		}
 	})
 }
+
+// samePost compares the identifying and content fields of two posts, ignoring timestamps.
+func samePost(a models.Post, b models.Post) bool {
+	return a.ID == b.ID && a.Title == b.Title && a.Content == b.Content && a.UserID == b.UserID && a.Status == b.Status
+}
+
+func TestPostLifecycle(t *testing.T) {
+	// Initialize service with a controllable clock
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewPostService(NewBus())
+	s.now = func() time.Time { return now }
+
+	t.Run("Create draft", func(t *testing.T) {
+		id, err := s.Create(ctx, "Draft", "Work in progress", 1, WithStatus(models.PostDraft))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		post, _ := s.FindByID(ctx, id)
+		if post.Status != models.PostDraft || post.PublishedAt != nil {
+			t.Errorf("Expected unpublished draft, got %+v", post)
+		}
+		if post.VisibleTo(2) || !post.VisibleTo(1) {
+			t.Error("Expected draft to be visible only to its author")
+		}
+	})
+
+	t.Run("Create scheduled post", func(t *testing.T) {
+		_, err := s.Create(ctx, "Later", "Scheduled content", 1, WithPublishAt(now.Add(time.Hour)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		post, _ := s.FindByID(ctx, 2)
+		if post.Status != models.PostScheduled || post.PublishAt == nil {
+			t.Errorf("Expected scheduled post, got %+v", post)
+		}
+	})
+
+	t.Run("Create with invalid schedule", func(t *testing.T) {
+		_, err := s.Create(ctx, "Past", "Content", 1, WithPublishAt(now.Add(-time.Hour)))
+		if err == nil || err.Error() != "publish_at must be in the future" {
+			t.Errorf("Expected future publish_at error, got %v", err)
+		}
+		_, err = s.Create(ctx, "Archived", "Content", 1, WithStatus(models.PostArchived))
+		if err == nil {
+			t.Error("Expected error creating archived post")
+		}
+	})
+
+	t.Run("Publish draft", func(t *testing.T) {
+		post, err := s.Publish(ctx, 1, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if post.Status != models.PostPublished || post.PublishedAt == nil || !post.PublishedAt.Equal(now) {
+			t.Errorf("Expected published post, got %+v", post)
+		}
+	})
+
+	t.Run("Publishing a published post is rejected", func(t *testing.T) {
+		_, err := s.Publish(ctx, 1, nil)
+		if !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Expected invalid transition error, got %v", err)
+		}
+	})
+
+	t.Run("Archive and republish", func(t *testing.T) {
+		post, err := s.Archive(ctx, 1)
+		if err != nil || post.Status != models.PostArchived {
+			t.Fatalf("Expected archived post, got %+v, %v", post, err)
+		}
+		if _, err := s.Archive(ctx, 1); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Expected invalid transition error, got %v", err)
+		}
+		if post, _ := s.Publish(ctx, 1, nil); post.Status != models.PostPublished {
+			t.Errorf("Expected republished post, got %+v", post)
+		}
+	})
+
+	t.Run("Transition of missing post", func(t *testing.T) {
+		if _, err := s.Archive(ctx, 999); err != ErrPostNotFound {
+			t.Errorf("Expected post not found error, got %v", err)
+		}
+	})
+
+	t.Run("Scheduler publishes due posts", func(t *testing.T) {
+		if n := s.PublishDue(ctx); n != 0 {
+			t.Errorf("Expected nothing due yet, got %d", n)
+		}
+		now = now.Add(2 * time.Hour)
+		if n := s.PublishDue(ctx); n != 1 {
+			t.Errorf("Expected 1 post published, got %d", n)
+		}
+		post, _ := s.FindByID(ctx, 2)
+		if post.Status != models.PostPublished || post.PublishAt != nil {
+			t.Errorf("Expected scheduled post to be published, got %+v", post)
+		}
+	})
+}
+
+func TestPostFormat(t *testing.T) {
+	// Initialize service
+	ctx := context.Background()
+	s := NewPostService(NewBus())
+
+	t.Run("Posts default to plain text", func(t *testing.T) {
+		id, _ := s.Create(ctx, "Plain", "*not emphasis*", 1)
+		post, _ := s.FindByID(ctx, id)
+		if post.Format != models.FormatPlain || post.ContentHTML != "<p>*not emphasis*</p>\n" {
+			t.Errorf("Expected plain rendering, got %q as %q", post.ContentHTML, post.Format)
+		}
+	})
+
+	t.Run("Markdown is rendered and re-rendered on edit", func(t *testing.T) {
+		id, err := s.Create(ctx, "Markdown", "*emphasis*", 1, WithFormat(models.FormatMarkdown))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		post, _ := s.FindByID(ctx, id)
+		if post.ContentHTML != "<p><em>emphasis</em></p>\n" {
+			t.Errorf("Expected rendered markdown, got %q", post.ContentHTML)
+		}
+		post, _ = s.Update(ctx, id, 1, "Markdown", "**strong**")
+		if post.ContentHTML != "<p><strong>strong</strong></p>\n" {
+			t.Errorf("Expected re-rendered markdown, got %q", post.ContentHTML)
+		}
+	})
+
+	t.Run("Unknown formats are rejected", func(t *testing.T) {
+		if _, err := s.Create(ctx, "Bad", "Content", 1, WithFormat("html")); err == nil {
+			t.Error("Expected unsupported format error")
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Maximum profile field lengths, in characters.
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 280
+	MaxLocationLength    = 100
+	MaxWebsiteLength     = 200
+)
+
+// ProfileUpdate lists the profile fields to change. Nil fields are left as they are;
+// an empty string clears the field.
+type ProfileUpdate struct {
+	Handle      *string
+	DisplayName *string
+	Bio         *string
+	Location    *string
+	Website     *string
+}
+
+// UpdateProfile applies update to a user's profile.
+// Returns the updated user, ErrUserNotFound, ErrHandleTaken, or an error if the handle
+// is invalid, a field is too long or the website is not an http or https URL.
+func (s *UserService) UpdateProfile(ctx context.Context, id int, update ProfileUpdate) (models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.UpdateProfile")
+	defer span.End()
+
+	update, err := normalizeProfile(update)
+	if err != nil {
+		return models.User{}, fail(span, err)
+	}
+
+	user, err := s.update(ctx, id, func(u *models.User) error {
+		if update.Handle != nil {
+			if s.handleTaken(*update.Handle, u.ID) {
+				return ErrHandleTaken
+			}
+			u.Handle = *update.Handle
+		}
+		if update.DisplayName != nil {
+			u.DisplayName = *update.DisplayName
+		}
+		if update.Bio != nil {
+			u.Bio = *update.Bio
+		}
+		if update.Location != nil {
+			u.Location = *update.Location
+		}
+		if update.Website != nil {
+			u.Website = *update.Website
+		}
+		return nil
+	})
+	if err != nil {
+		return models.User{}, fail(span, err)
+	}
+	return user, nil
+}
+
+// normalizeProfile trims the fields of update and normalizes its handle.
+// Returns an error if the handle is invalid, a field is too long or the website is not an http or https URL.
+func normalizeProfile(update ProfileUpdate) (ProfileUpdate, error) {
+	update = ProfileUpdate{
+		Handle:      update.Handle,
+		DisplayName: trimmed(update.DisplayName),
+		Bio:         trimmed(update.Bio),
+		Location:    trimmed(update.Location),
+		Website:     trimmed(update.Website),
+	}
+	fields := []struct {
+		name  string
+		value *string
+		max   int
+	}{
+		{"display_name", update.DisplayName, MaxDisplayNameLength},
+		{"bio", update.Bio, MaxBioLength},
+		{"location", update.Location, MaxLocationLength},
+		{"website", update.Website, MaxWebsiteLength},
+	}
+	for _, f := range fields {
+		if f.value != nil && utf8.RuneCountInString(*f.value) > f.max {
+			return ProfileUpdate{}, fmt.Errorf("%s must be at most %d characters", f.name, f.max)
+		}
+	}
+	if update.Website != nil && *update.Website != "" {
+		if err := validateWebsite(*update.Website); err != nil {
+			return ProfileUpdate{}, err
+		}
+	}
+
+	if update.Handle != nil {
+		handle, err := NormalizeHandle(*update.Handle)
+		if err != nil {
+			return ProfileUpdate{}, err
+		}
+		update.Handle = &handle
+	}
+	return update, nil
+}
+
+// SetAvatar replaces a user's avatar; a nil avatar removes it.
+// Returns the updated user or ErrUserNotFound.
+func (s *UserService) SetAvatar(ctx context.Context, id int, avatar *models.Avatar) (models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.SetAvatar")
+	defer span.End()
+
+	user, err := s.update(ctx, id, func(u *models.User) error {
+		u.Avatar = avatar
+		return nil
+	})
+	if err != nil {
+		return models.User{}, fail(span, err)
+	}
+	return user, nil
+}
+
+// update applies fn to a copy of the user with the given ID, stores it unless fn fails,
+// and publishes UserUpdated through the outbox. fn runs with s.mu held for writing.
+func (s *UserService) update(ctx context.Context, id int, fn func(*models.User) error) (models.User, error) {
+	s.mu.Lock()
+	i := -1
+	for j, u := range s.users {
+		if u.ID == id {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		s.mu.Unlock()
+		return models.User{}, ErrUserNotFound
+	}
+	user := s.users[i]
+	if err := fn(&user); err != nil {
+		s.mu.Unlock()
+		return models.User{}, err
+	}
+	update := startStorageSpan(ctx, "users", "update")
+	before := s.users[i]
+	s.users[i] = user
+	update.End()
+	s.outbox.Add(ctx, UserUpdated{User: user, Before: before})
+	s.mu.Unlock()
+
+	s.outbox.Relay(ctx)
+	return user, nil
+}
+
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	return &t
+}
+
+func validateWebsite(website string) error {
+	u, err := url.Parse(website)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("website must be an http or https URL")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestUserProfile(t *testing.T) {
+	// Initialize service
+	ctx := context.Background()
+	s := NewUserService(NewBus())
+	id, _ := s.Register(ctx, "Alice", "alice@example.com")
+	ptr := func(v string) *string { return &v }
+
+	t.Run("Update sets only given fields", func(t *testing.T) {
+		user, err := s.UpdateProfile(ctx, id, ProfileUpdate{DisplayName: ptr(" Ali "), Website: ptr("https://alice.dev")})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if user.DisplayName != "Ali" || user.Website != "https://alice.dev" || user.Bio != "" {
+			t.Errorf("Unexpected profile %+v", user)
+		}
+		user, _ = s.UpdateProfile(ctx, id, ProfileUpdate{Bio: ptr("Gopher")})
+		if user.DisplayName != "Ali" || user.Bio != "Gopher" {
+			t.Errorf("Expected earlier fields to be kept, got %+v", user)
+		}
+	})
+
+	t.Run("Empty strings clear fields", func(t *testing.T) {
+		user, _ := s.UpdateProfile(ctx, id, ProfileUpdate{Website: ptr("")})
+		if user.Website != "" {
+			t.Errorf("Expected website to be cleared, got %q", user.Website)
+		}
+	})
+
+	t.Run("Invalid fields are rejected", func(t *testing.T) {
+		tests := []ProfileUpdate{
+			{DisplayName: ptr(strings.Repeat("a", MaxDisplayNameLength+1))},
+			{Bio: ptr(strings.Repeat("ñ", MaxBioLength+1))},
+			{Website: ptr("javascript:alert(1)")},
+			{Website: ptr("alice.dev")},
+		}
+		for _, update := range tests {
+			if _, err := s.UpdateProfile(ctx, id, update); err == nil {
+				t.Errorf("Expected error for %+v", update)
+			}
+		}
+		user, _ := s.FindByID(ctx, id)
+		if user.Bio != "Gopher" {
+			t.Errorf("Expected rejected updates to change nothing, got %+v", user)
+		}
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		if _, err := s.UpdateProfile(ctx, 99, ProfileUpdate{Bio: ptr("x")}); err != ErrUserNotFound {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ErrInvalidReaction is returned for reaction types outside models.ReactionTypes.
+var ErrInvalidReaction = errors.New("invalid reaction type")
+
+// DefaultPopularWindow is the period whose reactions rank posts when sorting by popularity.
+const DefaultPopularWindow = 7 * 24 * time.Hour
+
+// React records that userID reacted to a post with the given type.
+// Reacting twice with the same type is a no-op.
+// PostReacted is published after a new reaction is stored.
+// Returns the post with updated counts, ErrPostNotFound, or ErrInvalidReaction.
+func (s *PostService) React(ctx context.Context, postID int, userID int, reaction models.ReactionType) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.React")
+	defer span.End()
+
+	if !slices.Contains(models.ReactionTypes, reaction) {
+		return models.Post{}, fail(span, ErrInvalidReaction)
+	}
+
+	s.mu.Lock()
+	i := s.index(postID)
+	if i < 0 {
+		s.mu.Unlock()
+		return models.Post{}, fail(span, ErrPostNotFound)
+	}
+	if s.reactionIndex(postID, userID, reaction) >= 0 {
+		post := s.posts[i]
+		s.mu.Unlock()
+		return post, nil
+	}
+
+	insert := startStorageSpan(ctx, "post_reactions", "insert")
+	r := models.Reaction{
+		PostID:    postID,
+		UserID:    userID,
+		Type:      reaction,
+		CreatedAt: s.now(),
+	}
+	s.reactions[postID] = append(s.reactions[postID], r)
+	insert.End()
+	s.countReaction(i, reaction, 1)
+	post := s.posts[i]
+	s.outbox.Add(ctx, PostReacted{Reaction: r})
+	s.mu.Unlock()
+
+	s.outbox.Relay(ctx)
+	return post, nil
+}
+
+// Unreact removes userID's reaction of the given type from a post.
+// Removing a reaction that doesn't exist is a no-op.
+// Returns the post with updated counts, ErrPostNotFound, or ErrInvalidReaction.
+func (s *PostService) Unreact(ctx context.Context, postID int, userID int, reaction models.ReactionType) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.Unreact")
+	defer span.End()
+
+	if !slices.Contains(models.ReactionTypes, reaction) {
+		return models.Post{}, fail(span, ErrInvalidReaction)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(postID)
+	if i < 0 {
+		return models.Post{}, fail(span, ErrPostNotFound)
+	}
+	j := s.reactionIndex(postID, userID, reaction)
+	if j < 0 {
+		return s.posts[i], nil
+	}
+
+	del := startStorageSpan(ctx, "post_reactions", "delete")
+	s.reactions[postID] = slices.Delete(s.reactions[postID], j, j+1)
+	del.End()
+	s.countReaction(i, reaction, -1)
+	return s.posts[i], nil
+}
+
+// Reactions returns the reactions left on a post, most recent first.
+// A non-empty reaction type keeps only reactions of that type.
+func (s *PostService) Reactions(ctx context.Context, postID int, reaction models.ReactionType) ([]models.Reaction, error) {
+	ctx, span := startSpan(ctx, "PostService.Reactions")
+	defer span.End()
+
+	if reaction != "" && !slices.Contains(models.ReactionTypes, reaction) {
+		return nil, fail(span, ErrInvalidReaction)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.index(postID) < 0 {
+		return nil, fail(span, ErrPostNotFound)
+	}
+	scan := startStorageSpan(ctx, "post_reactions", "scan")
+	defer scan.End()
+	reactions := make([]models.Reaction, 0, len(s.reactions[postID]))
+	for _, r := range slices.Backward(s.reactions[postID]) {
+		if reaction == "" || r.Type == reaction {
+			reactions = append(reactions, r)
+		}
+	}
+	return reactions, nil
+}
+
+// SortByPopularity orders posts by the number of reactions they received during the
+// last window, most first. Ties keep the most recently created post first.
+func (s *PostService) SortByPopularity(ctx context.Context, posts []models.Post, window time.Duration) []models.Post {
+	ctx, span := startSpan(ctx, "PostService.SortByPopularity")
+	defer span.End()
+
+	s.mu.RLock()
+	scan := startStorageSpan(ctx, "post_reactions", "scan")
+	since := s.now().Add(-window)
+	scores := make(map[int]int, len(posts))
+	for _, p := range posts {
+		for _, r := range s.reactions[p.ID] {
+			if !r.CreatedAt.Before(since) {
+				scores[p.ID]++
+			}
+		}
+	}
+	scan.End()
+	s.mu.RUnlock()
+
+	sorted := slices.Clone(posts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if scores[a.ID] != scores[b.ID] {
+			return scores[a.ID] > scores[b.ID]
+		}
+		return a.ID > b.ID
+	})
+	return sorted
+}
+
+// reactionIndex returns the position of userID's reaction of the given type on a post, or -1.
+// Callers must hold s.mu.
+func (s *PostService) reactionIndex(postID int, userID int, reaction models.ReactionType) int {
+	return slices.IndexFunc(s.reactions[postID], func(r models.Reaction) bool {
+		return r.UserID == userID && r.Type == reaction
+	})
+}
+
+// countReaction adjusts the reaction counts of the post at position i.
+// The counts map is replaced rather than modified, because copies of the post
+// handed out earlier share it. Callers must hold s.mu for writing.
+func (s *PostService) countReaction(i int, reaction models.ReactionType, delta int) {
+	counts := maps.Clone(s.posts[i].Reactions)
+	if counts == nil {
+		counts = make(map[models.ReactionType]int)
+	}
+	counts[reaction] += delta
+	if counts[reaction] <= 0 {
+		delete(counts, reaction)
+	}
+	s.posts[i].Reactions = counts
+}
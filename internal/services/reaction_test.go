@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPostReactions(t *testing.T) {
+	// Initialize service with a controllable clock
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewPostService(NewBus())
+	s.now = func() time.Time { return now }
+
+	first, _ := s.Create(ctx, "First", "Content", 1)
+	second, _ := s.Create(ctx, "Second", "Content", 1)
+
+	t.Run("React is idempotent", func(t *testing.T) {
+		s.React(ctx, first, 2, models.ReactionLike)
+		post, err := s.React(ctx, first, 2, models.ReactionLike)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		s.React(ctx, first, 3, models.ReactionLike)
+		post, _ = s.React(ctx, first, 3, models.ReactionLove)
+		expected := map[models.ReactionType]int{models.ReactionLike: 2, models.ReactionLove: 1}
+		if !reflect.DeepEqual(post.Reactions, expected) {
+			t.Errorf("Expected %v, got %v", expected, post.Reactions)
+		}
+	})
+
+	t.Run("React validates input", func(t *testing.T) {
+		if _, err := s.React(ctx, first, 2, "meh"); err != ErrInvalidReaction {
+			t.Errorf("Expected ErrInvalidReaction, got %v", err)
+		}
+		if _, err := s.React(ctx, 99, 2, models.ReactionLike); err != ErrPostNotFound {
+			t.Errorf("Expected ErrPostNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Unreact is idempotent and keeps earlier copies intact", func(t *testing.T) {
+		before, _ := s.FindByID(ctx, first)
+		post, _ := s.Unreact(ctx, first, 3, models.ReactionLove)
+		post, _ = s.Unreact(ctx, first, 3, models.ReactionLove)
+		if _, ok := post.Reactions[models.ReactionLove]; ok {
+			t.Errorf("Expected love to be removed, got %v", post.Reactions)
+		}
+		if before.Reactions[models.ReactionLove] != 1 {
+			t.Errorf("Expected earlier copy to keep its counts, got %v", before.Reactions)
+		}
+	})
+
+	t.Run("Reactions lists most recent first", func(t *testing.T) {
+		reactions, err := s.Reactions(ctx, first, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(reactions) != 2 || reactions[0].UserID != 3 || reactions[1].UserID != 2 {
+			t.Errorf("Expected reactions by users 3 then 2, got %v", reactions)
+		}
+		loves, _ := s.Reactions(ctx, first, models.ReactionLove)
+		if len(loves) != 0 {
+			t.Errorf("Expected no love reactions, got %v", loves)
+		}
+	})
+
+	t.Run("Popularity counts reactions within the window", func(t *testing.T) {
+		now = now.Add(48 * time.Hour)
+		s.React(ctx, second, 2, models.ReactionLaugh)
+
+		sorted := s.SortByPopularity(ctx, s.List(ctx), DefaultPopularWindow)
+		if sorted[0].ID != first {
+			t.Errorf("Expected first post over a week, got %d", sorted[0].ID)
+		}
+		sorted = s.SortByPopularity(ctx, s.List(ctx), 24*time.Hour)
+		if sorted[0].ID != second {
+			t.Errorf("Expected second post over a day, got %d", sorted[0].ID)
+		}
+	})
+}
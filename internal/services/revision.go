@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"example/api/internal/render"
+	"fmt"
+)
+
+// Limits on the revisions that can be diffed, which bound the time a diff takes.
+const (
+	// MaxDiffLines is the number of lines a revision's title or content may have
+	MaxDiffLines = 10000
+	// MaxDiffChanges is the number of lines two revisions' titles or contents may differ in,
+	// unless they have no line in common
+	MaxDiffChanges = 1000
+)
+
+// ErrRevisionNotFound is returned when a post has no revision with the requested number.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// ErrDiffTooLarge is returned when revisions have too many lines, or too many differences, to be diffed.
+var ErrDiffTooLarge = fmt.Errorf("revisions of more than %d lines, or differing in more than %d lines, cannot be compared", MaxDiffLines, MaxDiffChanges)
+
+// Update changes a post's title and content on behalf of editorID and records a new revision.
+// Returns the updated post, ErrPostNotFound, or an error if title or content is empty.
+func (s *PostService) Update(ctx context.Context, id int, editorID int, title string, content string) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.Update")
+	defer span.End()
+
+	if title == "" || content == "" {
+		return models.Post{}, fail(span, errors.New("title and content are required"))
+	}
+
+	s.mu.Lock()
+	post, err := s.edit(ctx, id, editorID, title, content, nil, nil)
+	s.mu.Unlock()
+	if err != nil {
+		return models.Post{}, fail(span, err)
+	}
+	s.outbox.Relay(ctx)
+	return post, nil
+}
+
+// UpdateWithTags changes a post's title, content and tags on behalf of editorID as a single
+// edit: one revision and one event, so no one sees the new text with the old tags.
+// Returns the updated post, ErrPostNotFound, or an error if title or content is empty
+// or a tag is invalid, in which case nothing is changed.
+func (s *PostService) UpdateWithTags(ctx context.Context, id int, editorID int, title string, content string, tags []string) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.UpdateWithTags")
+	defer span.End()
+
+	if title == "" || content == "" {
+		return models.Post{}, fail(span, errors.New("title and content are required"))
+	}
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return models.Post{}, fail(span, err)
+	}
+
+	s.mu.Lock()
+	post, err := s.edit(ctx, id, editorID, title, content, normalized, nil)
+	s.mu.Unlock()
+	if err != nil {
+		return models.Post{}, fail(span, err)
+	}
+	s.outbox.Relay(ctx)
+	return post, nil
+}
+
+// Revisions returns the revision history of a post, oldest first.
+func (s *PostService) Revisions(ctx context.Context, id int) ([]models.PostRevision, error) {
+	ctx, span := startSpan(ctx, "PostService.Revisions")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.index(id) < 0 {
+		return nil, fail(span, ErrPostNotFound)
+	}
+	scan := startStorageSpan(ctx, "post_revisions", "scan")
+	defer scan.End()
+	return append([]models.PostRevision(nil), s.revisions[id]...), nil
+}
+
+// Revision returns revision number n of a post.
+func (s *PostService) Revision(ctx context.Context, id int, n int) (models.PostRevision, error) {
+	_, span := startSpan(ctx, "PostService.Revision")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rev, err := s.revision(id, n)
+	if err != nil {
+		return models.PostRevision{}, fail(span, err)
+	}
+	return rev, nil
+}
+
+// Diff returns the line-based differences between revisions from and to of a post.
+// Returns ErrPostNotFound, ErrRevisionNotFound, or ErrDiffTooLarge if either revision
+// has more than MaxDiffLines lines of title or content, or if they differ in more than
+// MaxDiffChanges lines of either.
+func (s *PostService) Diff(ctx context.Context, id int, from int, to int) (models.RevisionDiff, error) {
+	_, span := startSpan(ctx, "PostService.Diff")
+	defer span.End()
+
+	s.mu.RLock()
+	a, err := s.revision(id, from)
+	if err != nil {
+		s.mu.RUnlock()
+		return models.RevisionDiff{}, fail(span, err)
+	}
+	b, err := s.revision(id, to)
+	s.mu.RUnlock()
+	if err != nil {
+		return models.RevisionDiff{}, fail(span, err)
+	}
+
+	title, err := diffLines(a.Title, b.Title)
+	if err != nil {
+		return models.RevisionDiff{}, fail(span, err)
+	}
+	content, err := diffLines(a.Content, b.Content)
+	if err != nil {
+		return models.RevisionDiff{}, fail(span, err)
+	}
+	return models.RevisionDiff{From: from, To: to, Title: title, Content: content}, nil
+}
+
+// Restore brings back the title and content of revision n as a new revision made by editorID.
+// Older revisions are never modified.
+func (s *PostService) Restore(ctx context.Context, id int, n int, editorID int) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.Restore")
+	defer span.End()
+
+	s.mu.Lock()
+	rev, err := s.revision(id, n)
+	if err != nil {
+		s.mu.Unlock()
+		return models.Post{}, fail(span, err)
+	}
+	post, err := s.edit(ctx, id, editorID, rev.Title, rev.Content, nil, &rev.Number)
+	s.mu.Unlock()
+	if err != nil {
+		return models.Post{}, fail(span, err)
+	}
+	s.outbox.Relay(ctx)
+	return post, nil
+}
+
+// edit applies a new title and content, and tags unless nil, and appends the matching revision.
+// Callers must hold s.mu for writing.
+func (s *PostService) edit(ctx context.Context, id int, editorID int, title string, content string, tags []string, restoredFrom *int) (models.Post, error) {
+	i := s.index(id)
+	if i < 0 {
+		return models.Post{}, ErrPostNotFound
+	}
+	before := s.posts[i]
+	post := before
+	if post.Title != title {
+		post.Title = title
+		s.assignSlug(&post)
+	}
+	if post.Content != content {
+		html, err := render.HTML(post.Format, content)
+		if err != nil {
+			return models.Post{}, err
+		}
+		s.unindexMentions(post)
+		post.Content = content
+		post.ContentHTML = html
+		post.Entities = s.entities(ctx, content)
+		s.indexMentions(post)
+	}
+	if tags != nil {
+		s.unindexTags(post)
+		post.Tags = tags
+		s.indexTags(post)
+	}
+	post.UpdatedAt = s.now()
+
+	update := startStorageSpan(ctx, "posts", "update")
+	s.posts[i] = post
+	update.End()
+	s.recordRevision(ctx, post, editorID, restoredFrom)
+	s.outbox.Add(ctx, PostUpdated{Post: post, Before: before})
+	return post, nil
+}
+
+// recordRevision appends a snapshot of post to its history. Callers must hold s.mu for writing.
+func (s *PostService) recordRevision(ctx context.Context, post models.Post, editorID int, restoredFrom *int) {
+	insert := startStorageSpan(ctx, "post_revisions", "insert")
+	defer insert.End()
+	s.revisions[post.ID] = append(s.revisions[post.ID], models.PostRevision{
+		PostID:       post.ID,
+		Number:       len(s.revisions[post.ID]) + 1,
+		Title:        post.Title,
+		Content:      post.Content,
+		EditorID:     editorID,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    post.UpdatedAt,
+	})
+}
+
+// revision looks up revision n of a post. Callers must hold s.mu.
+func (s *PostService) revision(id int, n int) (models.PostRevision, error) {
+	if s.index(id) < 0 {
+		return models.PostRevision{}, ErrPostNotFound
+	}
+	revs := s.revisions[id]
+	if n < 1 || n > len(revs) {
+		return models.PostRevision{}, ErrRevisionNotFound
+	}
+	return revs[n-1], nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestPostRevisions(t *testing.T) {
+	// Initialize service
+	ctx := context.Background()
+	s := NewPostService(NewBus())
+	id, _ := s.Create(ctx, "First title", "line one\nline two\nline three", 1)
+
+	t.Run("Create records first revision", func(t *testing.T) {
+		revs, err := s.Revisions(ctx, id)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(revs) != 1 || revs[0].Number != 1 || revs[0].EditorID != 1 {
+			t.Errorf("Expected one revision by the author, got %+v", revs)
+		}
+	})
+
+	t.Run("Update records new revision", func(t *testing.T) {
+		post, err := s.Update(ctx, id, 2, "Second title", "line one\nline 2\nline three\nline four")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if post.Title != "Second title" {
+			t.Errorf("Expected updated title, got %s", post.Title)
+		}
+		rev, err := s.Revision(ctx, id, 2)
+		if err != nil || rev.EditorID != 2 || rev.Title != "Second title" {
+			t.Errorf("Expected revision 2 by editor 2, got %+v, %v", rev, err)
+		}
+	})
+
+	t.Run("Update with empty fields", func(t *testing.T) {
+		_, err := s.Update(ctx, id, 2, "", "content")
+		if err == nil || err.Error() != "title and content are required" {
+			t.Errorf("Expected required fields error, got %v", err)
+		}
+	})
+
+	t.Run("Diff between revisions", func(t *testing.T) {
+		diff, err := s.Diff(ctx, id, 1, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := []models.DiffLine{
+			{Op: "equal", Text: "line one"},
+			{Op: "delete", Text: "line two"},
+			{Op: "insert", Text: "line 2"},
+			{Op: "equal", Text: "line three"},
+			{Op: "insert", Text: "line four"},
+		}
+		if !reflect.DeepEqual(diff.Content, expected) {
+			t.Errorf("Expected diff %v, got %v", expected, diff.Content)
+		}
+		if len(diff.Title) != 2 {
+			t.Errorf("Expected title delete and insert, got %v", diff.Title)
+		}
+	})
+
+	t.Run("Restore creates new revision", func(t *testing.T) {
+		post, err := s.Restore(ctx, id, 1, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if post.Title != "First title" {
+			t.Errorf("Expected restored title, got %s", post.Title)
+		}
+		revs, _ := s.Revisions(ctx, id)
+		if len(revs) != 3 || revs[2].RestoredFrom == nil || *revs[2].RestoredFrom != 1 {
+			t.Errorf("Expected third revision restored from 1, got %+v", revs)
+		}
+		if revs[1].Title != "Second title" {
+			t.Error("Expected older revisions to be unchanged")
+		}
+	})
+
+	t.Run("Update with tags is one edit", func(t *testing.T) {
+		rec := Record(s.bus)
+		before, _ := s.Revisions(ctx, id)
+		post, err := s.UpdateWithTags(ctx, id, 1, "Tagged title", "tagged content", []string{"Go", "news"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if post.Title != "Tagged title" || !reflect.DeepEqual(post.Tags, []string{"go", "news"}) {
+			t.Errorf("Expected new title and tags, got %+v", post)
+		}
+		rec.Expect(t, "post.updated")
+		if found, _ := s.FindByTags(ctx, []string{"news"}, nil); len(found) != 1 || found[0].ID != id {
+			t.Errorf("Expected post to be indexed by its new tag, got %+v", found)
+		}
+		if after, _ := s.Revisions(ctx, id); len(after) != len(before)+1 {
+			t.Errorf("Expected one more revision, got %d after %d", len(after), len(before))
+		}
+	})
+
+	t.Run("Update with invalid tag changes nothing", func(t *testing.T) {
+		rec := Record(s.bus)
+		before, _ := s.FindByID(ctx, id)
+		if _, err := s.UpdateWithTags(ctx, id, 1, "Other title", "other content", []string{"not a tag!"}); err == nil {
+			t.Fatal("Expected invalid tag error, got nil")
+		}
+		if after, _ := s.FindByID(ctx, id); after.Title != before.Title || !reflect.DeepEqual(after.Tags, before.Tags) {
+			t.Errorf("Expected post unchanged, got %+v", after)
+		}
+		rec.Expect(t)
+	})
+
+	t.Run("Missing revision", func(t *testing.T) {
+		if _, err := s.Revision(ctx, id, 9); err != ErrRevisionNotFound {
+			t.Errorf("Expected revision not found error, got %v", err)
+		}
+		if _, err := s.Diff(ctx, 999, 1, 2); err != ErrPostNotFound {
+			t.Errorf("Expected post not found error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"example/api/internal/search"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Kinds of documents in the search index.
+const (
+	SearchPosts = "post"
+	SearchUsers = "user"
+)
+
+// SearchService keeps a full-text index of published posts and users up to date
+// by subscribing to PostService and UserService changes.
+type SearchService struct {
+	index *search.Index
+}
+
+// NewSearchService creates a SearchService, indexes the current posts and users,
+// and subscribes to post and user events so later changes are indexed as they happen.
+func NewSearchService(posts *PostService, users *UserService) *SearchService {
+	s := &SearchService{index: search.NewIndex()}
+
+	ctx := context.Background()
+	for _, p := range posts.List(ctx) {
+		s.indexPost(ctx, p)
+	}
+	for _, u := range users.List(ctx) {
+		s.indexUser(ctx, u)
+	}
+
+	Subscribe(posts.bus, func(ctx context.Context, e PostCreated) error {
+		s.indexPost(ctx, e.Post)
+		return nil
+	})
+	Subscribe(posts.bus, func(ctx context.Context, e PostUpdated) error {
+		s.indexPost(ctx, e.Post)
+		return nil
+	})
+	Subscribe(posts.bus, func(_ context.Context, e PostDeleted) error {
+		s.index.Remove(SearchPosts, e.Post.ID)
+		return nil
+	})
+	Subscribe(users.bus, func(ctx context.Context, e UserRegistered) error {
+		s.indexUser(ctx, e.User)
+		return nil
+	})
+	Subscribe(users.bus, func(ctx context.Context, e UserUpdated) error {
+		s.indexUser(ctx, e.User)
+		return nil
+	})
+	Subscribe(users.bus, func(_ context.Context, e UserDeleted) error {
+		s.index.Remove(SearchUsers, e.User.ID)
+		return nil
+	})
+	return s
+}
+
+// Search runs query against the index. Only published posts are ever indexed,
+// so results are safe to show to any viewer.
+func (s *SearchService) Search(ctx context.Context, query string, opts search.Options) search.Results {
+	_, span := startSpan(ctx, "SearchService.Search")
+	defer span.End()
+
+	results := s.index.Search(query, opts)
+	span.SetAttributes(
+		attribute.String("search.kind", opts.Kind),
+		attribute.Int("search.total", results.Total),
+	)
+	return results
+}
+
+// indexPost adds a published post to the index, or removes it when it is not published.
+func (s *SearchService) indexPost(_ context.Context, post models.Post) {
+	if post.Status != models.PostPublished {
+		s.index.Remove(SearchPosts, post.ID)
+		return
+	}
+	s.index.Upsert(search.Document{
+		Kind:  SearchPosts,
+		ID:    post.ID,
+		Title: post.Title,
+		Fields: []search.Field{
+			{Name: "title", Text: post.Title, Weight: 2},
+			{Name: "tags", Text: strings.Join(post.Tags, " "), Weight: 1.5},
+			{Name: "content", Text: post.Content, Weight: 1},
+		},
+	})
+}
+
+func (s *SearchService) indexUser(_ context.Context, user models.User) {
+	s.index.Upsert(search.Document{
+		Kind:  SearchUsers,
+		ID:    user.ID,
+		Title: user.Name,
+		Fields: []search.Field{
+			{Name: "name", Text: user.Name, Weight: 2},
+			{Name: "handle", Text: user.Handle, Weight: 2},
+			{Name: "display_name", Text: user.DisplayName, Weight: 2},
+			{Name: "bio", Text: user.Bio, Weight: 1},
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"example/api/internal/search"
+	"testing"
+)
+
+func TestSearchService(t *testing.T) {
+	// Initialize services
+	ctx := context.Background()
+	users := NewUserService(NewBus())
+	posts := NewPostService(NewBus())
+	users.Register(ctx, "Ana García", "ana@example.com")
+	posts.Create(ctx, "Existing post", "Created ahead of the index", 1)
+	s := NewSearchService(posts, users)
+
+	ids := func(r search.Results) []int {
+		var out []int
+		for _, h := range r.Hits {
+			out = append(out, h.ID)
+		}
+		return out
+	}
+
+	t.Run("Indexes existing data", func(t *testing.T) {
+		if got := s.Search(ctx, "existing", search.Options{Kind: SearchPosts}); got.Total != 1 {
+			t.Errorf("Expected 1 post, got %d", got.Total)
+		}
+		if got := s.Search(ctx, "garcia", search.Options{Kind: SearchUsers}); got.Total != 1 {
+			t.Errorf("Expected 1 user, got %d", got.Total)
+		}
+	})
+
+	draftID, _ := posts.Create(ctx, "Draft about searching", "Not yet visible", 1, WithStatus(models.PostDraft))
+
+	t.Run("Skips unpublished posts", func(t *testing.T) {
+		if got := s.Search(ctx, "searching", search.Options{}); got.Total != 0 {
+			t.Errorf("Expected no results, got %v", ids(got))
+		}
+	})
+
+	t.Run("Indexes posts when published", func(t *testing.T) {
+		posts.Publish(ctx, draftID, nil)
+		got := s.Search(ctx, "search", search.Options{})
+		if got.Total != 1 || got.Hits[0].ID != draftID {
+			t.Errorf("Expected post %d, got %v", draftID, ids(got))
+		}
+	})
+
+	t.Run("Reindexes posts when edited", func(t *testing.T) {
+		posts.Update(ctx, draftID, 1, "Notes on indexing", "Inverted indexes explained")
+		if got := s.Search(ctx, "searching", search.Options{}); got.Total != 0 {
+			t.Errorf("Expected old title to be gone, got %v", ids(got))
+		}
+		if got := s.Search(ctx, "inverted", search.Options{}); got.Total != 1 {
+			t.Errorf("Expected 1 result, got %d", got.Total)
+		}
+	})
+
+	t.Run("Removes archived and deleted posts", func(t *testing.T) {
+		posts.Archive(ctx, draftID)
+		if got := s.Search(ctx, "inverted", search.Options{}); got.Total != 0 {
+			t.Errorf("Expected archived post to be removed, got %v", ids(got))
+		}
+		posts.Delete(ctx, 1)
+		if got := s.Search(ctx, "existing", search.Options{}); got.Total != 0 {
+			t.Errorf("Expected deleted post to be removed, got %v", ids(got))
+		}
+	})
+
+	t.Run("Removes deleted users", func(t *testing.T) {
+		id, _ := users.Register(ctx, "Bob Builder", "bob@example.com")
+		if got := s.Search(ctx, "bob", search.Options{}); got.Total != 1 {
+			t.Fatalf("Expected 1 user, got %d", got.Total)
+		}
+		users.Delete(ctx, id)
+		if got := s.Search(ctx, "bob", search.Options{}); got.Total != 0 {
+			t.Errorf("Expected deleted user to be removed, got %v", ids(got))
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"example/api/internal/search"
+	"strconv"
+	"strings"
+)
+
+// MaxSlugLength is the maximum length of a generated slug, before any collision suffix.
+const MaxSlugLength = 80
+
+// transliterations spells out letters that do not decompose into a base letter and accents.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d",
+	'ł': "l", 'þ': "th", 'ı': "i", 'ħ': "h", 'ŧ': "t",
+}
+
+// reservedSlugs would be shadowed by the GET /posts/{id}/... routes, so they are never handed out.
+var reservedSlugs = map[string]bool{"comments": true, "reactions": true, "revisions": true}
+
+// Slugify turns a title into a URL-friendly slug: lower-case ASCII letters and digits
+// separated by single hyphens, with accents removed ("Canción Ñandú" becomes "cancion-nandu").
+// Returns "post" when the title has no usable characters.
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range search.Fold(title) {
+		var part string
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			part = string(r)
+		case transliterations[r] != "":
+			part = transliterations[r]
+		default:
+			hyphen = b.Len() > 0
+			continue
+		}
+		if hyphen {
+			b.WriteByte('-')
+			hyphen = false
+		}
+		b.WriteString(part)
+	}
+
+	slug := b.String()
+	if len(slug) > MaxSlugLength {
+		// Cut at a word boundary unless the first word alone is too long.
+		cut := slug[:MaxSlugLength]
+		if slug[MaxSlugLength] != '-' {
+			if i := strings.LastIndexByte(cut, '-'); i > 0 {
+				cut = cut[:i]
+			}
+		}
+		slug = strings.TrimSuffix(cut, "-")
+	}
+	if slug == "" {
+		return "post"
+	}
+	return slug
+}
+
+// FindBySlug returns the post addressed by slug, which may be its current slug
+// or one it had before its title changed. Callers can compare the result's Slug
+// with the requested one to detect a retired slug.
+func (s *PostService) FindBySlug(ctx context.Context, slug string) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.FindBySlug")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "post_slugs", "get")
+	defer scan.End()
+	id, ok := s.slugs[slug]
+	if !ok {
+		return models.Post{}, fail(span, ErrPostNotFound)
+	}
+	return s.posts[s.index(id)], nil
+}
+
+// assignSlug gives a post a unique slug derived from its title, keeping any previous
+// slug registered so it can redirect. Callers must hold s.mu for writing.
+func (s *PostService) assignSlug(post *models.Post) {
+	base := Slugify(post.Title)
+	slug := base
+	for n := 2; ; n++ {
+		if owner, taken := s.slugs[slug]; (!taken || owner == post.ID) && !reservedSlugs[slug] {
+			break
+		}
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	post.Slug = slug
+	s.slugs[slug] = post.ID
+}
+
+// releaseSlugs frees the current and retired slugs of a deleted post. Callers must hold s.mu for writing.
+func (s *PostService) releaseSlugs(id int) {
+	for slug, owner := range s.slugs {
+		if owner == id {
+			delete(s.slugs, slug)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  Canción del Ñandú  ", "cancion-del-nandu"},
+		{"Straße & Smørrebrød", "strasse-smorrebrod"},
+		{"Go 1.23 -- what's new?", "go-1-23-what-s-new"},
+		{"¿¡!?", "post"},
+		{"日本語", "post"},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.title); got != tt.expected {
+			t.Errorf("Slugify(%q): expected %q, got %q", tt.title, tt.expected, got)
+		}
+	}
+
+	t.Run("Long titles are cut at a word boundary", func(t *testing.T) {
+		got := Slugify(strings.Repeat("word ", 30))
+		if len(got) > MaxSlugLength || strings.HasSuffix(got, "-") || !strings.HasSuffix(got, "word") {
+			t.Errorf("Expected whole words within %d characters, got %q", MaxSlugLength, got)
+		}
+	})
+}
+
+func TestPostSlugs(t *testing.T) {
+	// Initialize service
+	ctx := context.Background()
+	s := NewPostService(NewBus())
+
+	first, _ := s.Create(ctx, "Mi publicación", "Content", 1)
+	second, _ := s.Create(ctx, "Mi Publicacion!", "Content", 1)
+
+	t.Run("Collisions get a numeric suffix", func(t *testing.T) {
+		a, _ := s.FindByID(ctx, first)
+		b, _ := s.FindByID(ctx, second)
+		if a.Slug != "mi-publicacion" || b.Slug != "mi-publicacion-2" {
+			t.Errorf("Expected mi-publicacion and mi-publicacion-2, got %q and %q", a.Slug, b.Slug)
+		}
+	})
+
+	t.Run("Reserved slugs are never assigned", func(t *testing.T) {
+		id, _ := s.Create(ctx, "Comments", "Content", 1)
+		post, _ := s.FindByID(ctx, id)
+		if post.Slug != "comments-2" {
+			t.Errorf("Expected comments-2, got %q", post.Slug)
+		}
+	})
+
+	t.Run("Title changes keep the old slug", func(t *testing.T) {
+		if _, err := s.Update(ctx, first, 1, "Otro título", "Content"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		post, err := s.FindBySlug(ctx, "mi-publicacion")
+		if err != nil {
+			t.Fatalf("Expected old slug to resolve, got %v", err)
+		}
+		if post.ID != first || post.Slug != "otro-titulo" {
+			t.Errorf("Expected post %d with slug otro-titulo, got %d with %q", first, post.ID, post.Slug)
+		}
+	})
+
+	t.Run("Retired slugs stay reserved", func(t *testing.T) {
+		id, _ := s.Create(ctx, "Mi publicación", "Content", 2)
+		post, _ := s.FindByID(ctx, id)
+		if post.Slug != "mi-publicacion-3" {
+			t.Errorf("Expected mi-publicacion-3, got %q", post.Slug)
+		}
+	})
+
+	t.Run("Restoring a title reuses its slug", func(t *testing.T) {
+		post, err := s.Restore(ctx, first, 1, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if post.Slug != "mi-publicacion" {
+			t.Errorf("Expected mi-publicacion, got %q", post.Slug)
+		}
+	})
+
+	t.Run("Delete frees slugs", func(t *testing.T) {
+		s.Delete(ctx, first)
+		if _, err := s.FindBySlug(ctx, "otro-titulo"); err != ErrPostNotFound {
+			t.Errorf("Expected ErrPostNotFound, got %v", err)
+		}
+		id, _ := s.Create(ctx, "Mi publicación", "Content", 1)
+		post, _ := s.FindByID(ctx, id)
+		if post.Slug != "mi-publicacion" {
+			t.Errorf("Expected mi-publicacion, got %q", post.Slug)
+		}
+	})
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/models"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Limits on tags attached to a post.
+const (
+	MaxTagsPerPost = 10
+	MaxTagLength   = 50
+)
+
+// WithTags attaches tags to a new post. Tags are normalized when the post is created.
+func WithTags(tags ...string) PostOption {
+	return func(p *models.Post) {
+		p.Tags = tags
+	}
+}
+
+// NormalizeTag lower-cases a tag, strips a leading '#' and turns inner whitespace into '-'.
+// Tags may only contain letters, digits, '-' and '_'.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.TrimPrefix(tag, "#")
+	tag = strings.Join(strings.Fields(tag), "-")
+	if tag == "" {
+		return "", errors.New("tags must not be empty")
+	}
+	if len([]rune(tag)) > MaxTagLength {
+		return "", fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", fmt.Errorf("tag %q contains invalid character %q", tag, r)
+		}
+	}
+	return tag, nil
+}
+
+// NormalizeTags normalizes, deduplicates and sorts a set of tags for a post.
+func NormalizeTags(tags []string) ([]string, error) {
+	set := make([]string, 0, len(tags))
+	for _, t := range tags {
+		n, err := NormalizeTag(t)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, n)
+	}
+	sort.Strings(set)
+	set = slices.Compact(set)
+	if len(set) > MaxTagsPerPost {
+		return nil, fmt.Errorf("posts can have at most %d tags", MaxTagsPerPost)
+	}
+	return set, nil
+}
+
+// SetTags replaces the tags of a post.
+// Returns the updated post, ErrPostNotFound, or an error if a tag is invalid.
+func (s *PostService) SetTags(ctx context.Context, id int, tags []string) (models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.SetTags")
+	defer span.End()
+
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return models.Post{}, fail(span, err)
+	}
+
+	s.mu.Lock()
+	i := s.index(id)
+	if i < 0 {
+		s.mu.Unlock()
+		return models.Post{}, fail(span, ErrPostNotFound)
+	}
+	before := s.posts[i]
+	post := before
+	s.unindexTags(post)
+	post.Tags = normalized
+	post.UpdatedAt = s.now()
+
+	update := startStorageSpan(ctx, "posts", "update")
+	s.posts[i] = post
+	update.End()
+	s.indexTags(post)
+	s.outbox.Add(ctx, PostUpdated{Post: post, Before: before})
+	s.mu.Unlock()
+
+	s.outbox.Relay(ctx)
+	return post, nil
+}
+
+// Tags returns every tag in use with the number of published posts carrying it,
+// most used first. Tags used only on unpublished posts are omitted.
+func (s *PostService) Tags(ctx context.Context) []models.TagCount {
+	ctx, span := startSpan(ctx, "PostService.Tags")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "post_tags", "scan")
+	defer scan.End()
+	counts := make([]models.TagCount, 0, len(s.tagIndex))
+	for tag, ids := range s.tagIndex {
+		n := 0
+		for id := range ids {
+			if s.posts[s.index(id)].Status == models.PostPublished {
+				n++
+			}
+		}
+		if n > 0 {
+			counts = append(counts, models.TagCount{Tag: tag, Count: n})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+	return counts
+}
+
+// FindByTags returns the posts carrying every tag in allOf and at least one tag in anyOf.
+// Either list may be empty; tags are normalized before matching. Posts are returned in creation order.
+func (s *PostService) FindByTags(ctx context.Context, allOf []string, anyOf []string) ([]models.Post, error) {
+	ctx, span := startSpan(ctx, "PostService.FindByTags")
+	defer span.End()
+
+	allOf, err := normalizeQueryTags(allOf)
+	if err != nil {
+		return nil, fail(span, err)
+	}
+	anyOf, err = normalizeQueryTags(anyOf)
+	if err != nil {
+		return nil, fail(span, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "post_tags", "scan")
+	defer scan.End()
+	matches := make([]models.Post, 0)
+	for _, p := range s.posts {
+		if s.hasAllTags(p.ID, allOf) && (len(anyOf) == 0 || s.hasAnyTag(p.ID, anyOf)) {
+			matches = append(matches, p)
+		}
+	}
+	return matches, nil
+}
+
+// MergeTags replaces every source tag with target on all posts carrying it, atomically.
+// Renaming a tag is a merge with a single source. Returns the number of posts changed.
+func (s *PostService) MergeTags(ctx context.Context, sources []string, target string) (int, error) {
+	ctx, span := startSpan(ctx, "PostService.MergeTags")
+	defer span.End()
+
+	target, err := NormalizeTag(target)
+	if err != nil {
+		return 0, fail(span, err)
+	}
+	if len(sources) == 0 {
+		return 0, fail(span, errors.New("at least one source tag is required"))
+	}
+	from := make(map[string]bool, len(sources))
+	for _, t := range sources {
+		n, err := NormalizeTag(t)
+		if err != nil {
+			return 0, fail(span, err)
+		}
+		from[n] = true
+	}
+	delete(from, target)
+
+	s.mu.Lock()
+	// Compute every rewrite before applying any, so a failure leaves all posts untouched.
+	rewritten := make(map[int][]string)
+	for tag := range from {
+		for id := range s.tagIndex[tag] {
+			if _, done := rewritten[id]; done {
+				continue
+			}
+			post := s.posts[s.index(id)]
+			tags := make([]string, 0, len(post.Tags))
+			for _, t := range post.Tags {
+				if !from[t] {
+					tags = append(tags, t)
+				}
+			}
+			tags, _ = NormalizeTags(append(tags, target))
+			rewritten[id] = tags
+		}
+	}
+
+	update := startStorageSpan(ctx, "posts", "update")
+	now := s.now()
+	merged := make([]models.Post, 0, len(rewritten))
+	for id, tags := range rewritten {
+		i := s.index(id)
+		before := s.posts[i]
+		post := before
+		s.unindexTags(post)
+		post.Tags = tags
+		post.UpdatedAt = now
+		s.posts[i] = post
+		s.indexTags(post)
+		s.outbox.Add(ctx, PostUpdated{Post: post, Before: before})
+		merged = append(merged, post)
+	}
+	update.End()
+	s.mu.Unlock()
+
+	s.outbox.Relay(ctx)
+	return len(merged), nil
+}
+
+// indexTags adds a post to the tag index. Callers must hold s.mu for writing.
+func (s *PostService) indexTags(post models.Post) {
+	for _, tag := range post.Tags {
+		if s.tagIndex[tag] == nil {
+			s.tagIndex[tag] = make(map[int]struct{})
+		}
+		s.tagIndex[tag][post.ID] = struct{}{}
+	}
+}
+
+// unindexTags removes a post from the tag index. Callers must hold s.mu for writing.
+func (s *PostService) unindexTags(post models.Post) {
+	for _, tag := range post.Tags {
+		delete(s.tagIndex[tag], post.ID)
+		if len(s.tagIndex[tag]) == 0 {
+			delete(s.tagIndex, tag)
+		}
+	}
+}
+
+func (s *PostService) hasAllTags(id int, tags []string) bool {
+	for _, tag := range tags {
+		if _, ok := s.tagIndex[tag][id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *PostService) hasAnyTag(id int, tags []string) bool {
+	for _, tag := range tags {
+		if _, ok := s.tagIndex[tag][id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeQueryTags normalizes tags used for filtering, without the per-post limit.
+func normalizeQueryTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, t := range tags {
+		n, err := NormalizeTag(t)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, n)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestPostTags(t *testing.T) {
+	// Initialize service
+	ctx := context.Background()
+	s := NewPostService(NewBus())
+
+	t.Run("Create normalizes and deduplicates tags", func(t *testing.T) {
+		id, err := s.Create(ctx, "Go post", "About Go", 1, WithTags("Go", " #go ", "Web Dev", "api"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		post, _ := s.FindByID(ctx, id)
+		expected := []string{"api", "go", "web-dev"}
+		if !reflect.DeepEqual(post.Tags, expected) {
+			t.Errorf("Expected tags %v, got %v", expected, post.Tags)
+		}
+	})
+
+	t.Run("Create rejects invalid tags", func(t *testing.T) {
+		if _, err := s.Create(ctx, "Bad", "Bad tags", 1, WithTags("c++")); err == nil {
+			t.Error("Expected invalid character error")
+		}
+		if _, err := s.Create(ctx, "Bad", "Bad tags", 1, WithTags("   ")); err == nil {
+			t.Error("Expected empty tag error")
+		}
+	})
+
+	s.Create(ctx, "Rust post", "About Rust", 2, WithTags("rust", "api"))
+	s.Create(ctx, "Golang draft", "Unpublished", 2, WithTags("golang"), WithStatus(models.PostDraft))
+
+	t.Run("Tags counts published posts", func(t *testing.T) {
+		expected := []models.TagCount{{Tag: "api", Count: 2}, {Tag: "go", Count: 1}, {Tag: "rust", Count: 1}, {Tag: "web-dev", Count: 1}}
+		if got := s.Tags(ctx); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Find by all and any tags", func(t *testing.T) {
+		posts, _ := s.FindByTags(ctx, []string{"api", "go"}, nil)
+		if len(posts) != 1 || posts[0].Title != "Go post" {
+			t.Errorf("Expected only the Go post, got %v", posts)
+		}
+		posts, _ = s.FindByTags(ctx, nil, []string{"RUST", "golang"})
+		if len(posts) != 2 {
+			t.Errorf("Expected 2 posts, got %d", len(posts))
+		}
+	})
+
+	t.Run("Set tags updates index", func(t *testing.T) {
+		if _, err := s.SetTags(ctx, 2, []string{"rust"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		posts, _ := s.FindByTags(ctx, []string{"api"}, nil)
+		if len(posts) != 1 {
+			t.Errorf("Expected 1 post tagged api, got %d", len(posts))
+		}
+	})
+
+	t.Run("Merge tags rewrites all posts", func(t *testing.T) {
+		n, err := s.MergeTags(ctx, []string{"go", "GoLang"}, "golang")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if n != 1 {
+			t.Errorf("Expected 1 post rewritten, got %d", n)
+		}
+		posts, _ := s.FindByTags(ctx, []string{"golang"}, nil)
+		if len(posts) != 2 {
+			t.Errorf("Expected 2 posts tagged golang, got %d", len(posts))
+		}
+		if posts, _ := s.FindByTags(ctx, []string{"go"}, nil); len(posts) != 0 {
+			t.Errorf("Expected no posts tagged go, got %d", len(posts))
+		}
+	})
+
+	t.Run("Delete removes post from index", func(t *testing.T) {
+		s.Delete(ctx, 1)
+		for _, tc := range s.Tags(ctx) {
+			if tc.Tag == "web-dev" {
+				t.Errorf("Expected web-dev to disappear, got %v", tc)
+			}
+		}
+	})
+}
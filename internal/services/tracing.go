@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("example/api/internal/services")
+
+// startSpan starts a child span for a service method such as "PostService.Create".
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+}
+
+// startStorageSpan starts a child span around an operation on the in-memory storage.
+func startStorageSpan(ctx context.Context, collection string, operation string) trace.Span {
+	_, span := tracer.Start(ctx, "memory "+collection+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "memory"),
+			attribute.String("db.collection.name", collection),
+			attribute.String("db.operation.name", operation),
+		),
+	)
+	return span
+}
+
+// fail records err on the span and returns it unchanged.
+func fail(span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
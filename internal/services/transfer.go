@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/api/internal/audit"
+	"example/api/internal/models"
+	"example/api/internal/transfer"
+	"fmt"
+	"iter"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TransferService imports and exports users and posts in bulk.
+type TransferService struct {
+	users *UserService
+	posts *PostService
+}
+
+// NewTransferService creates a TransferService moving data in and out of users and posts.
+func NewTransferService(users *UserService, posts *PostService) *TransferService {
+	return &TransferService{users: users, posts: posts}
+}
+
+// Export returns the records of every user, then of every post whatever its status.
+// Records are read as the sequence is iterated, so the dataset is never held in memory.
+func (s *TransferService) Export(ctx context.Context) iter.Seq[transfer.Record] {
+	return func(yield func(transfer.Record) bool) {
+		for u := range s.users.All(ctx) {
+			if !yield(transfer.UserRecord(u)) {
+				return
+			}
+		}
+		for p := range s.posts.All(ctx) {
+			if !yield(transfer.PostRecord(p)) {
+				return
+			}
+		}
+	}
+}
+
+// Import creates a user or post for each row, in order, and reports the rows that failed.
+// A failed row does not stop the import or undo the rows before it.
+//
+// Users are registered like through POST /users, with the handle and profile of the record.
+// A post's user_id is looked up among the IDs of the user records imported before it,
+// and otherwise taken as the ID of an existing user. The report maps the records' IDs
+// to the IDs of the users and posts created.
+//
+// With dryRun, the rows are imported into a copy of the services that is then discarded,
+// so the report says what the import would do without changing anything.
+//
+// The import is audited as one change to the resource "import" holding the report's summary,
+// rather than one per record.
+func (s *TransferService) Import(ctx context.Context, rows iter.Seq[transfer.Row], dryRun bool) transfer.Report {
+	ctx, span := startSpan(ctx, "TransferService.Import")
+	defer span.End()
+
+	users, posts := s.users, s.posts
+	if dryRun {
+		users, posts = s.users.sandbox(), s.posts.sandbox()
+	}
+	report := transfer.NewReport(dryRun)
+	recordCtx := audit.WithoutRecorder(ctx)
+	for row := range rows {
+		report.Rows++
+		err := row.Err
+		if err == nil {
+			err = importRecord(recordCtx, users, posts, row.Record, &report)
+		}
+		if err != nil {
+			report.Fail(row, err)
+		}
+	}
+	span.SetAttributes(
+		attribute.Bool("import.dry_run", dryRun),
+		attribute.Int("import.rows", report.Rows),
+		attribute.Int("import.failed", report.Failed),
+	)
+	audit.Record(ctx, audit.Change{Resource: "import", After: report.Summary})
+	return report
+}
+
+func importRecord(ctx context.Context, users *UserService, posts *PostService, rec transfer.Record, report *transfer.Report) error {
+	switch rec.Type {
+	case transfer.TypeUser:
+		if _, ok := report.UserIDs[rec.ID]; ok {
+			return fmt.Errorf("duplicate user id %d", rec.ID)
+		}
+		id, err := importUser(ctx, users, rec)
+		if err != nil {
+			return err
+		}
+		report.Users++
+		if rec.ID != 0 {
+			report.UserIDs[rec.ID] = id
+		}
+	case transfer.TypePost:
+		if _, ok := report.PostIDs[rec.ID]; ok {
+			return fmt.Errorf("duplicate post id %d", rec.ID)
+		}
+		author, ok := report.UserIDs[rec.UserID]
+		if !ok {
+			if _, err := users.FindByID(ctx, rec.UserID); err != nil {
+				return fmt.Errorf("unknown author %d", rec.UserID)
+			}
+			author = rec.UserID
+		}
+		id, err := importPost(ctx, posts, rec, author)
+		if err != nil {
+			return err
+		}
+		report.Posts++
+		if rec.ID != 0 {
+			report.PostIDs[rec.ID] = id
+		}
+	default:
+		return fmt.Errorf("unknown record type %q: use %q or %q", rec.Type, transfer.TypeUser, transfer.TypePost)
+	}
+	return nil
+}
+
+func importUser(ctx context.Context, users *UserService, rec transfer.Record) (int, error) {
+	profile, err := normalizeProfile(ProfileUpdate{
+		DisplayName: &rec.DisplayName,
+		Bio:         &rec.Bio,
+		Location:    &rec.Location,
+		Website:     &rec.Website,
+	})
+	if err != nil {
+		return 0, err
+	}
+	opts := []UserOption{func(u *models.User) {
+		u.DisplayName = *profile.DisplayName
+		u.Bio = *profile.Bio
+		u.Location = *profile.Location
+		u.Website = *profile.Website
+	}}
+	if rec.Handle != "" {
+		opts = append(opts, WithHandle(rec.Handle))
+	}
+	id, err := users.Register(ctx, rec.Name, rec.Email, opts...)
+	if errors.Is(err, ErrHandleTaken) {
+		return 0, fmt.Errorf("handle %q is taken", rec.Handle)
+	}
+	return id, err
+}
+
+// importPost creates the post of rec with its creation and publication times. Posts cannot
+// be created archived, so archived posts are created as drafts, without their publication
+// schedule, and then archived. A scheduled post whose time has passed since the export is
+// imported as published at that time, as the scheduler would have done.
+func importPost(ctx context.Context, posts *PostService, rec transfer.Record, author int) (int, error) {
+	status, publishAt, publishedAt := rec.Status, rec.PublishAt, rec.PublishedAt
+	if publishAt != nil && (status == "" || status == models.PostScheduled) && !publishAt.After(posts.now()) {
+		status, publishAt = models.PostPublished, nil
+		if publishedAt == nil {
+			publishedAt = rec.PublishAt
+		}
+	}
+
+	var opts []PostOption
+	switch status {
+	case "":
+	case models.PostArchived:
+		opts = append(opts, WithStatus(models.PostDraft))
+	default:
+		opts = append(opts, WithStatus(status))
+	}
+	if publishAt != nil && status != models.PostArchived {
+		opts = append(opts, WithPublishAt(*publishAt))
+	}
+	if publishedAt != nil {
+		opts = append(opts, WithPublishedAt(*publishedAt))
+	}
+	if rec.CreatedAt != nil {
+		opts = append(opts, WithCreatedAt(*rec.CreatedAt))
+	}
+	if rec.Format != "" {
+		opts = append(opts, WithFormat(rec.Format))
+	}
+	if len(rec.Tags) > 0 {
+		opts = append(opts, WithTags(rec.Tags...))
+	}
+	id, err := posts.Create(ctx, rec.Title, rec.Content, author, opts...)
+	if err != nil || status != models.PostArchived {
+		return id, err
+	}
+	_, err = posts.Archive(ctx, id)
+	return id, err
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"example/api/internal/audit"
+	"example/api/internal/models"
+	"example/api/internal/transfer"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransferService(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	users := NewUserService(bus)
+	posts := NewPostService(bus)
+	s := NewTransferService(users, posts)
+	existing, _ := users.Register(ctx, "Existing", "existing@example.com")
+
+	input := strings.Join([]string{
+		`{"type":"user","id":40,"name":"Ana","email":"ana@example.com","handle":"ana","bio":"  Gopher  "}`,
+		`{"type":"user","id":41,"name":"Bob","email":"existing@example.com"}`,
+		`{"type":"user","id":40,"name":"Clone","email":"clone@example.com"}`,
+		`{"type":"post","id":1,"title":"By Ana","content":"Hi","user_id":40,"tags":["Go"]}`,
+		`{"type":"post","id":2,"title":"By existing","content":"Hi","user_id":` + fmt.Sprint(existing) + `,"status":"draft"}`,
+		`{"type":"post","id":3,"title":"By Bob","content":"Hi","user_id":41}`,
+		`{"type":"post","id":4,"title":"Old","content":"Hi","user_id":40,"status":"archived"}`,
+		`{"type":"comment"}`,
+		`not json`,
+	}, "\n")
+	read := func() []transfer.Row {
+		return slices.Collect(transfer.Read(strings.NewReader(input), transfer.NDJSON))
+	}
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		report := s.Import(ctx, slices.Values(read()), true)
+		if !report.DryRun || report.Users != 1 || report.Posts != 3 || report.Failed != 5 {
+			t.Errorf("Expected 1 user and 3 posts with 5 failures, got %+v", report)
+		}
+		if n := len(users.List(ctx)); n != 1 {
+			t.Errorf("Expected 1 user after a dry run, got %d", n)
+		}
+		if n := len(posts.List(ctx)); n != 0 {
+			t.Errorf("Expected no posts after a dry run, got %d", n)
+		}
+	})
+
+	t.Run("import remaps author IDs and reports failed rows", func(t *testing.T) {
+		report := s.Import(ctx, slices.Values(read()), false)
+		if report.Users != 1 || report.Posts != 3 || report.Rows != 9 {
+			t.Fatalf("Expected 1 user and 3 posts out of 9 rows, got %+v", report)
+		}
+		expected := []transfer.RowError{
+			{Line: 2, Type: transfer.TypeUser, ID: 41, Error: "email already exists"},
+			{Line: 3, Type: transfer.TypeUser, ID: 40, Error: "duplicate user id 40"},
+			{Line: 6, Type: transfer.TypePost, ID: 3, Error: "unknown author 41"},
+			{Line: 8, Type: "comment", Error: `unknown record type "comment": use "user" or "post"`},
+		}
+		if len(report.Errors) != 5 || !slices.Equal(report.Errors[:4], expected) || report.Errors[4].Line != 9 {
+			t.Errorf("Expected errors %+v and one on line 9, got %+v", expected, report.Errors)
+		}
+
+		ana, err := users.FindByID(ctx, report.UserIDs[40])
+		if err != nil || ana.Name != "Ana" || ana.Handle != "ana" || ana.Bio != "Gopher" {
+			t.Fatalf("Expected Ana with her handle and trimmed bio, got %+v and %v", ana, err)
+		}
+		post, _ := posts.FindByID(ctx, report.PostIDs[1])
+		if post.UserID != ana.ID || !slices.Equal(post.Tags, []string{"go"}) {
+			t.Errorf("Expected post 1 by Ana tagged go, got %+v", post)
+		}
+		if post, _ := posts.FindByID(ctx, report.PostIDs[2]); post.UserID != existing || post.Status != models.PostDraft {
+			t.Errorf("Expected a draft by the existing user, got %+v", post)
+		}
+		if post, _ := posts.FindByID(ctx, report.PostIDs[4]); post.Status != models.PostArchived {
+			t.Errorf("Expected an archived post, got %s", post.Status)
+		}
+	})
+
+	t.Run("export lists users then posts", func(t *testing.T) {
+		for i := range 2 * scanBatchSize {
+			posts.Create(ctx, fmt.Sprintf("Post %d", i), "Content", existing)
+		}
+		var types []transfer.Type
+		var ids []int
+		for rec := range s.Export(ctx) {
+			types = append(types, rec.Type)
+			if rec.Type == transfer.TypePost {
+				ids = append(ids, rec.ID)
+			}
+		}
+		if len(types) != 2+3+2*scanBatchSize || types[1] != transfer.TypeUser || types[2] != transfer.TypePost {
+			t.Errorf("Expected 2 users then %d posts, got %d records", 3+2*scanBatchSize, len(types))
+		}
+		if !slices.IsSorted(ids) || len(slices.Compact(ids)) != len(ids) {
+			t.Error("Expected every post once, in ID order")
+		}
+	})
+	t.Run("import keeps timestamps and publishes overdue schedules", func(t *testing.T) {
+		created := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+		published := created.Add(time.Hour)
+		due := time.Now().Add(-time.Minute).Truncate(time.Second)
+		future := time.Now().Add(time.Hour).Truncate(time.Second)
+		post := func(id int, status models.PostStatus) transfer.Record {
+			return transfer.Record{Type: transfer.TypePost, ID: id, Title: "Moved", Content: "Hi", UserID: existing, Status: status, CreatedAt: &created}
+		}
+		kept := post(10, models.PostPublished)
+		kept.PublishedAt = &published
+		overdue := post(11, models.PostScheduled)
+		overdue.PublishAt = &due
+		scheduled := post(12, models.PostScheduled)
+		scheduled.PublishAt = &future
+
+		rows := []transfer.Row{{Line: 1, Record: kept}, {Line: 2, Record: overdue}, {Line: 3, Record: scheduled}}
+		report := s.Import(ctx, slices.Values(rows), false)
+		if report.Posts != 3 {
+			t.Fatalf("Expected 3 posts, got %+v", report)
+		}
+		p, _ := posts.FindByID(ctx, report.PostIDs[10])
+		if !p.CreatedAt.Equal(created) || p.PublishedAt == nil || !p.PublishedAt.Equal(published) {
+			t.Errorf("Expected the post created at %v and published at %v, got %v and %v", created, published, p.CreatedAt, p.PublishedAt)
+		}
+		p, _ = posts.FindByID(ctx, report.PostIDs[11])
+		if p.Status != models.PostPublished || p.PublishAt != nil || p.PublishedAt == nil || !p.PublishedAt.Equal(due) {
+			t.Errorf("Expected the overdue post published at %v, got %s at %v", due, p.Status, p.PublishedAt)
+		}
+		p, _ = posts.FindByID(ctx, report.PostIDs[12])
+		if p.Status != models.PostScheduled || p.PublishAt == nil || !p.PublishAt.Equal(future) {
+			t.Errorf("Expected the post scheduled for %v, got %s for %v", future, p.Status, p.PublishAt)
+		}
+	})
+	t.Run("import is audited as a summary", func(t *testing.T) {
+		RecordAuditChanges(bus)
+		auditCtx, rec := audit.WithRecorder(ctx)
+		rows := []transfer.Row{
+			{Line: 1, Record: transfer.Record{Type: transfer.TypeUser, ID: 1, Name: "Dee", Email: "dee@example.com"}},
+			{Line: 2, Record: transfer.Record{Type: transfer.TypePost, ID: 1, Title: "By Dee", Content: "Hi", UserID: 1}},
+		}
+		report := s.Import(auditCtx, slices.Values(rows), false)
+		changes := rec.Changes()
+		if len(changes) != 1 || changes[0].Resource != "import" || changes[0].After != report.Summary {
+			t.Errorf("Expected one change to import with the summary %+v, got %+v", report.Summary, changes)
+		}
+	})
+}
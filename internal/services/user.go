@@ -2,74 +2,201 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"example/api/internal/models"
+	"iter"
+	"slices"
+	"sort"
+	"sync"
 )
 
+// ErrUserNotFound is returned when no user exists with the requested ID.
+var ErrUserNotFound = errors.New("user not found")
+
 // UserService manages user-related operations such as registration, listing, finding, and deleting users.
 // It maintains an in-memory collection of users and handles user ID generation.
 type UserService struct {
+	mu     sync.RWMutex
 	users  []models.User
 	nextId int
+	bus    *Bus
+	outbox *Outbox
 }
 
 // NewUserService creates and returns a new instance of UserService with initialized fields.
-func NewUserService() *UserService {
+// Registrations, profile changes and deletions are published on bus through the service's outbox.
+func NewUserService(bus *Bus) *UserService {
 	return &UserService{
 		users:  make([]models.User, 0),
 		nextId: 1,
+		bus:    bus,
+		outbox: NewOutbox("users", bus),
 	}
 }
 
 // Register creates a new user with the given name and email.
+// Users get a unique handle derived from their name unless WithHandle picks one.
 // Returns the new user's ID and an error if registration fails.
-// Registration fails if name or email is empty, or if the email already exists.
-func (service *UserService) Register(name string, email string) (int, error) {
+// Registration fails if name or email is empty, if the email already exists,
+// or if the requested handle is invalid or taken (ErrHandleTaken).
+func (service *UserService) Register(ctx context.Context, name string, email string, opts ...UserOption) (int, error) {
+	ctx, span := startSpan(ctx, "UserService.Register")
+	defer span.End()
+
 	if name == "" || email == "" {
-		return 0, errors.New("name and email are required")
+		return 0, fail(span, errors.New("name and email are required"))
+	}
+
+	user := models.User{
+		Name:  name,
+		Email: email,
+	}
+	for _, opt := range opts {
+		opt(&user)
+	}
+	if user.Handle != "" {
+		handle, err := NormalizeHandle(user.Handle)
+		if err != nil {
+			return 0, fail(span, err)
+		}
+		user.Handle = handle
 	}
 
+	service.mu.Lock()
+	scan := startStorageSpan(ctx, "users", "scan")
 	for _, u := range service.users {
 		if email == u.Email {
-			return 0, errors.New("email already exists")
+			scan.End()
+			service.mu.Unlock()
+			return 0, fail(span, errors.New("email already exists"))
 		}
 	}
-
-	user := models.User{
-		ID:    service.nextId,
-		Name:  name,
-		Email: email,
+	if user.Handle == "" {
+		user.Handle = service.uniqueHandle(name)
+	} else if service.handleTaken(user.Handle, 0) {
+		scan.End()
+		service.mu.Unlock()
+		return 0, fail(span, ErrHandleTaken)
 	}
+	scan.End()
 
+	user.ID = service.nextId
+	insert := startStorageSpan(ctx, "users", "insert")
 	service.users = append(service.users, user)
 	service.nextId++
+	insert.End()
+	service.outbox.Add(ctx, UserRegistered{User: user})
+	service.mu.Unlock()
+
+	service.outbox.Relay(ctx)
 	return user.ID, nil
 }
 
 // List returns all registered users.
-func (s *UserService) List() []models.User {
-	return s.users
+func (s *UserService) List(ctx context.Context) []models.User {
+	ctx, span := startSpan(ctx, "UserService.List")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "users", "scan")
+	defer scan.End()
+	return append([]models.User(nil), s.users...)
+}
+
+// All returns every registered user in ID order. Users are read from the service in
+// batches, so iterating does not hold the service's lock or copy every user at once;
+// users registered or deleted meanwhile may or may not be seen.
+func (s *UserService) All(ctx context.Context) iter.Seq[models.User] {
+	return func(yield func(models.User) bool) {
+		ctx, span := startSpan(ctx, "UserService.All")
+		defer span.End()
+
+		after := 0
+		for {
+			s.mu.RLock()
+			scan := startStorageSpan(ctx, "users", "scan")
+			i := sort.Search(len(s.users), func(i int) bool { return s.users[i].ID > after })
+			batch := slices.Clone(s.users[i:min(i+scanBatchSize, len(s.users))])
+			scan.End()
+			s.mu.RUnlock()
+
+			for _, u := range batch {
+				if !yield(u) {
+					return
+				}
+				after = u.ID
+			}
+			if len(batch) < scanBatchSize {
+				return
+			}
+		}
+	}
+}
+
+// sandbox returns a copy of the service publishing on a bus of its own, on which
+// changes can be tried without making them.
+func (s *UserService) sandbox() *UserService {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := NewUserService(NewBus())
+	c.users = slices.Clone(s.users)
+	c.nextId = s.nextId
+	return c
 }
 
 // FindByID searches for a user by their ID.
 // Returns the user if found, or an error if no user exists with the given ID.
-func (s *UserService) FindByID(id int) (models.User, error) {
+func (s *UserService) FindByID(ctx context.Context, id int) (models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.FindByID")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scan := startStorageSpan(ctx, "users", "scan")
+	defer scan.End()
 	for _, u := range s.users {
 		if u.ID == id {
 			return u, nil
 		}
 	}
-	return models.User{}, errors.New("user not found")
+	return models.User{}, fail(span, ErrUserNotFound)
 }
 
 // Delete removes a user with the specified ID from the service.
 // Returns true if the user was found and deleted, false otherwise.
-func (s *UserService) Delete(id int) bool {
+// UserDeleted is published after the user is removed.
+func (s *UserService) Delete(ctx context.Context, id int) bool {
+	ctx, span := startSpan(ctx, "UserService.Delete")
+	defer span.End()
+
+	s.mu.Lock()
+	del := startStorageSpan(ctx, "users", "delete")
+	var user models.User
+	found := false
 	for i, u := range s.users {
 		if u.ID == id {
+			user = u
 			s.users = append(s.users[:i], s.users[i+1:]...)
-			return true
+			s.outbox.Add(ctx, UserDeleted{User: user})
+			found = true
+			break
 		}
 	}
-	return false
+	del.End()
+	s.mu.Unlock()
+
+	if !found {
+		return false
+	}
+	s.outbox.Relay(ctx)
+	return true
+}
+
+// Outbox returns the outbox holding the service's events until they are published.
+func (s *UserService) Outbox() *Outbox {
+	return s.outbox
 }
@@ -1,17 +1,19 @@
 package services
 
 import (
+	"context"
 	"example/api/internal/models"
 	"testing"
 )
 
 func TestUserService(t *testing.T) {
 	// Initialize service
-	s := NewUserService()
+	ctx := context.Background()
+	s := NewUserService(NewBus())
 
 	// Test Register
 	t.Run("Register valid user", func(t *testing.T) {
-		id, err := s.Register("Alice", "alice@example.com")
+		id, err := s.Register(ctx, "Alice", "alice@example.com")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -21,14 +23,14 @@ func TestUserService(t *testing.T) {
 	})
 
 	t.Run("Register duplicate email", func(t *testing.T) {
-		_, err := s.Register("Bob", "alice@example.com")
+		_, err := s.Register(ctx, "Bob", "alice@example.com")
 		if err == nil || err.Error() != "email already exists" {
 			t.Errorf("Expected email already exists error, got %v", err)
 		}
 	})
 
 	t.Run("Register empty fields", func(t *testing.T) {
-		_, err := s.Register("", "test@example.com")
+		_, err := s.Register(ctx, "", "test@example.com")
 		if err == nil || err.Error() != "name and email are required" {
 			t.Errorf("Expected required fields error, got %v", err)
 		}
@@ -36,11 +38,11 @@ func TestUserService(t *testing.T) {
 
 	// Test List
 	t.Run("List users", func(t *testing.T) {
-		users := s.List()
+		users := s.List(ctx)
 		if len(users) != 1 {
 			t.Errorf("Expected 1 user, got %d", len(users))
 		}
-		expected := models.User{ID: 1, Name: "Alice", Email: "alice@example.com"}
+		expected := models.User{ID: 1, Name: "Alice", Email: "alice@example.com", Handle: "alice"}
 		if users[0] != expected {
 			t.Errorf("Expected user %v, got %v", expected, users[0])
 		}
@@ -48,18 +50,18 @@ func TestUserService(t *testing.T) {
 
 	// Test FindByID
 	t.Run("Find existing user", func(t *testing.T) {
-		user, err := s.FindByID(1)
+		user, err := s.FindByID(ctx, 1)
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
-		expected := models.User{ID: 1, Name: "Alice", Email: "alice@example.com"}
+		expected := models.User{ID: 1, Name: "Alice", Email: "alice@example.com", Handle: "alice"}
 		if user != expected {
 			t.Errorf("Expected user %v, got %v", expected, user)
 		}
 	})
 
 	t.Run("Find non-existent user", func(t *testing.T) {
-		_, err := s.FindByID(999)
+		_, err := s.FindByID(ctx, 999)
 		if err == nil || err.Error() != "user not found" {
 			t.Errorf("Expected user not found error, got %v", err)
 		}
@@ -67,16 +69,16 @@ func TestUserService(t *testing.T) {
 
 	// Test Delete
 	t.Run("Delete existing user", func(t *testing.T) {
-		if !s.Delete(1) {
+		if !s.Delete(ctx, 1) {
 			t.Error("Expected true, got false")
 		}
-		if len(s.List()) != 0 {
-			t.Errorf("Expected 0 users, got %d", len(s.List()))
+		if len(s.List(ctx)) != 0 {
+			t.Errorf("Expected 0 users, got %d", len(s.List(ctx)))
 		}
 	})
 
 	t.Run("Delete non-existent user", func(t *testing.T) {
-		if s.Delete(999) {
+		if s.Delete(ctx, 999) {
 			t.Error("Expected false, got true")
 		}
 	})
@@ -0,0 +1,894 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"example/api/internal/events"
+	"example/api/internal/models"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Webhook limits and retry schedule.
+const (
+	MaxWebhooksPerUser   = 20
+	MaxWebhookURLLength  = 2000
+	MinWebhookSecretSize = 16
+	// MaxWebhookDeliveries is the number of deliveries kept per webhook; the oldest finished ones are dropped.
+	MaxWebhookDeliveries = 500
+	// DefaultWebhookMaxAttempts is the number of attempts made before a delivery is dead-lettered.
+	DefaultWebhookMaxAttempts = 8
+	// WebhookRetryDelay is the wait before the first retry; each retry after that waits twice
+	// as long as the previous one, up to MaxWebhookRetryDelay.
+	WebhookRetryDelay    = 30 * time.Second
+	MaxWebhookRetryDelay = 6 * time.Hour
+
+	DefaultWebhookDeliveryLimit = 20
+	MaxWebhookDeliveryLimit     = 100
+
+	// WebhookWorkers is the number of deliveries sent at the same time.
+	WebhookWorkers = 8
+	// MaxWebhookQueue is the number of deliveries waiting for a worker. Deliveries that do
+	// not fit are left pending, due right away, for RetryDue to queue later.
+	MaxWebhookQueue = 1000
+)
+
+// Headers sent with every webhook delivery.
+const (
+	// WebhookIDHeader carries the delivery ID, which is the same for every attempt
+	WebhookIDHeader = "X-Webhook-ID"
+	// WebhookEventHeader carries the event type
+	WebhookEventHeader = "X-Webhook-Event"
+	// WebhookEventIDHeader carries the outbox ID of the event (see EventID)
+	WebhookEventIDHeader = "X-Webhook-Event-ID"
+	// WebhookTimestampHeader carries the time of the attempt, in Unix seconds
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// WebhookSignatureHeader carries the signature computed by SignWebhook
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// Webhook errors.
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryInFlight = errors.New("webhook delivery is being sent")
+	ErrTooManyWebhooks         = fmt.Errorf("a user can have at most %d webhooks", MaxWebhooksPerUser)
+	ErrInvalidWebhookEvent     = errors.New("invalid webhook event type")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	// ErrWebhookAddressNotAllowed is returned by the client of NewWebhookClient for
+	// destinations that are not public internet addresses.
+	ErrWebhookAddressNotAllowed = errors.New("webhook destination address not allowed")
+)
+
+// WebhookInput describes a new webhook. An empty Secret is replaced by a random one.
+type WebhookInput struct {
+	URL    string
+	Events []string
+	Secret string
+}
+
+// WebhookUpdate lists the webhook settings to change. Nil fields are left as they are.
+type WebhookUpdate struct {
+	URL    *string
+	Events []string
+	Secret *string
+	Active *bool
+}
+
+// WebhookService sends the events of the post and user services to the URLs users subscribe.
+//
+// Deliveries are recorded by a synchronous subscriber of the services' bus, so an event
+// leaves the services' outboxes only once its deliveries are stored, and are sent by a
+// fixed pool of workers, so slow receivers never hold up a request or the bus. Each request is signed with the webhook's secret
+// (see SignWebhook). A delivery that fails is retried with exponential backoff by
+// RetryDue and dead-lettered after its last attempt; any delivery can be sent again
+// with Redeliver. Webhooks only receive events about posts their owner can see.
+type WebhookService struct {
+	mu       sync.Mutex
+	webhooks map[int]models.Webhook
+	// deliveries maps a webhook to its deliveries, oldest first
+	deliveries     map[int][]*webhookDelivery
+	nextID         int
+	nextDeliveryID int
+	nextEventID    uint64
+	posts          *PostService
+	client         *http.Client
+	maxAttempts    int
+	now            func() time.Time
+
+	// queue holds the deliveries waiting for a worker
+	queue chan webhookJob
+	// pending counts the queued and running jobs; idle is signalled when it reaches zero
+	pending int
+	idle    *sync.Cond
+}
+
+// webhookJob is a delivery waiting for a worker, with the context it was queued in.
+type webhookJob struct {
+	ctx      context.Context
+	delivery *webhookDelivery
+}
+
+// webhookDelivery is a stored delivery with its retry bookkeeping.
+type webhookDelivery struct {
+	models.WebhookDelivery
+	// tries counts the attempts since the delivery was created or last redelivered
+	tries int
+	// queued is set while the delivery waits for a worker
+	queued bool
+	// sending is set while an attempt is in flight
+	sending bool
+}
+
+// NewWebhookService creates a WebhookService that sends requests with client and gives up on a
+// delivery after maxAttempts attempts (DefaultWebhookMaxAttempts if zero or less).
+// It subscribes to post and user events, removes a user's webhooks when the user is deleted,
+// and starts WebhookWorkers workers.
+func NewWebhookService(posts *PostService, users *UserService, client *http.Client, maxAttempts int) *WebhookService {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultWebhookMaxAttempts
+	}
+	s := &WebhookService{
+		webhooks:       make(map[int]models.Webhook),
+		deliveries:     make(map[int][]*webhookDelivery),
+		nextID:         1,
+		nextDeliveryID: 1,
+		posts:          posts,
+		client:         client,
+		maxAttempts:    maxAttempts,
+		now:            time.Now,
+		queue:          make(chan webhookJob, MaxWebhookQueue),
+	}
+	s.idle = sync.NewCond(&s.mu)
+	for range WebhookWorkers {
+		go s.work()
+	}
+	subscribeWebhooks[PostCreated](posts.bus, s)
+	subscribeWebhooks[PostUpdated](posts.bus, s)
+	subscribeWebhooks[PostDeleted](posts.bus, s)
+	subscribeWebhooks[CommentCreated](posts.bus, s)
+	subscribeWebhooks[CommentDeleted](posts.bus, s)
+	subscribeWebhooks[UserRegistered](users.bus, s)
+	subscribeWebhooks[UserDeleted](users.bus, s)
+	Subscribe(users.bus, s.userDeleted)
+	return s
+}
+
+func subscribeWebhooks[E DomainEvent](b *Bus, s *WebhookService) {
+	Subscribe(b, func(ctx context.Context, e E) error { return s.dispatch(ctx, e) })
+}
+
+// Create adds a webhook for userID. The returned webhook includes its secret, which is not shown again.
+// Returns an error if the URL is not an http or https URL, an event type is unknown,
+// the secret is shorter than MinWebhookSecretSize, or the user has MaxWebhooksPerUser webhooks.
+func (s *WebhookService) Create(ctx context.Context, userID int, input WebhookInput) (models.Webhook, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Create")
+	defer span.End()
+
+	if err := validateWebhookURL(input.URL); err != nil {
+		return models.Webhook{}, fail(span, err)
+	}
+	eventTypes, err := webhookEvents(input.Events)
+	if err != nil {
+		return models.Webhook{}, fail(span, err)
+	}
+	secret := input.Secret
+	if secret == "" {
+		secret = newWebhookSecret()
+	} else if err := validateWebhookSecret(secret); err != nil {
+		return models.Webhook{}, fail(span, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := 0
+	for _, w := range s.webhooks {
+		if w.UserID == userID {
+			owned++
+		}
+	}
+	if owned >= MaxWebhooksPerUser {
+		return models.Webhook{}, fail(span, ErrTooManyWebhooks)
+	}
+	insert := startStorageSpan(ctx, "webhooks", "insert")
+	defer insert.End()
+	webhook := models.Webhook{
+		ID:        s.nextID,
+		UserID:    userID,
+		URL:       input.URL,
+		Events:    eventTypes,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: s.now(),
+	}
+	s.webhooks[webhook.ID] = webhook
+	s.nextID++
+	return webhook, nil
+}
+
+// List returns userID's webhooks, oldest first, without their secrets.
+func (s *WebhookService) List(ctx context.Context, userID int) []models.Webhook {
+	ctx, span := startSpan(ctx, "WebhookService.List")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scan := startStorageSpan(ctx, "webhooks", "scan")
+	defer scan.End()
+	webhooks := make([]models.Webhook, 0)
+	for _, w := range s.webhooks {
+		if w.UserID == userID {
+			webhooks = append(webhooks, withoutSecret(w))
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks
+}
+
+// Find returns userID's webhook with the given ID, without its secret.
+// Returns ErrWebhookNotFound if it doesn't exist or belongs to another user.
+func (s *WebhookService) Find(ctx context.Context, userID int, id int) (models.Webhook, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Find")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhook, ok := s.webhook(ctx, userID, id)
+	if !ok {
+		return models.Webhook{}, fail(span, ErrWebhookNotFound)
+	}
+	return withoutSecret(webhook), nil
+}
+
+// Update applies update to userID's webhook. Deactivating a webhook stops new events from
+// being sent to it; deliveries already pending are still retried.
+// Returns the updated webhook without its secret, ErrWebhookNotFound, or a validation error as in Create.
+func (s *WebhookService) Update(ctx context.Context, userID int, id int, update WebhookUpdate) (models.Webhook, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Update")
+	defer span.End()
+
+	if update.URL != nil {
+		if err := validateWebhookURL(*update.URL); err != nil {
+			return models.Webhook{}, fail(span, err)
+		}
+	}
+	var eventTypes []string
+	if update.Events != nil {
+		var err error
+		if eventTypes, err = webhookEvents(update.Events); err != nil {
+			return models.Webhook{}, fail(span, err)
+		}
+	}
+	if update.Secret != nil {
+		if err := validateWebhookSecret(*update.Secret); err != nil {
+			return models.Webhook{}, fail(span, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhook, ok := s.webhook(ctx, userID, id)
+	if !ok {
+		return models.Webhook{}, fail(span, ErrWebhookNotFound)
+	}
+	save := startStorageSpan(ctx, "webhooks", "update")
+	defer save.End()
+	if update.URL != nil {
+		webhook.URL = *update.URL
+	}
+	if eventTypes != nil {
+		webhook.Events = eventTypes
+	}
+	if update.Secret != nil {
+		webhook.Secret = *update.Secret
+	}
+	if update.Active != nil {
+		webhook.Active = *update.Active
+	}
+	s.webhooks[id] = webhook
+	return withoutSecret(webhook), nil
+}
+
+// Delete removes userID's webhook and its deliveries.
+// Returns true if the webhook was found and deleted, false otherwise.
+func (s *WebhookService) Delete(ctx context.Context, userID int, id int) bool {
+	ctx, span := startSpan(ctx, "WebhookService.Delete")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhook(ctx, userID, id); !ok {
+		return false
+	}
+	del := startStorageSpan(ctx, "webhooks", "delete")
+	defer del.End()
+	delete(s.webhooks, id)
+	delete(s.deliveries, id)
+	return true
+}
+
+// Deliveries returns a page of the delivery log of userID's webhook, most recent first.
+// A non-empty status keeps only deliveries in that state. limit defaults to
+// DefaultWebhookDeliveryLimit and is capped at MaxWebhookDeliveryLimit.
+// Returns ErrWebhookNotFound if the webhook doesn't exist or belongs to another user.
+func (s *WebhookService) Deliveries(ctx context.Context, userID int, id int, status models.WebhookDeliveryStatus, limit int, offset int) (models.WebhookDeliveryPage, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Deliveries")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhook(ctx, userID, id); !ok {
+		return models.WebhookDeliveryPage{}, fail(span, ErrWebhookNotFound)
+	}
+	scan := startStorageSpan(ctx, "webhook_deliveries", "scan")
+	defer scan.End()
+	return deliveryPage(s.deliveries[id], status, limit, offset), nil
+}
+
+// DeadLetters returns a page of the deliveries to userID's webhooks that failed every attempt,
+// most recent first. limit is handled as in Deliveries.
+func (s *WebhookService) DeadLetters(ctx context.Context, userID int, limit int, offset int) models.WebhookDeliveryPage {
+	ctx, span := startSpan(ctx, "WebhookService.DeadLetters")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scan := startStorageSpan(ctx, "webhook_deliveries", "scan")
+	defer scan.End()
+	var all []*webhookDelivery
+	for id, w := range s.webhooks {
+		if w.UserID == userID {
+			all = append(all, s.deliveries[id]...)
+		}
+	}
+	// Delivery IDs increase with creation time, so sorting by ID keeps the oldest first.
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return deliveryPage(all, models.WebhookDead, limit, offset)
+}
+
+// Redeliver sends a delivery of userID's webhook again, right away, and returns it with the
+// outcome. If the attempt fails, the delivery is retried on the usual schedule as if it were new.
+// Returns ErrWebhookNotFound, ErrWebhookDeliveryNotFound, or ErrWebhookDeliveryInFlight if an
+// attempt is already queued or under way.
+func (s *WebhookService) Redeliver(ctx context.Context, userID int, id int, deliveryID int) (models.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Redeliver")
+	defer span.End()
+
+	s.mu.Lock()
+	if _, ok := s.webhook(ctx, userID, id); !ok {
+		s.mu.Unlock()
+		return models.WebhookDelivery{}, fail(span, ErrWebhookNotFound)
+	}
+	i := slices.IndexFunc(s.deliveries[id], func(d *webhookDelivery) bool { return d.ID == deliveryID })
+	if i < 0 {
+		s.mu.Unlock()
+		return models.WebhookDelivery{}, fail(span, ErrWebhookDeliveryNotFound)
+	}
+	d := s.deliveries[id][i]
+	if d.sending || d.queued {
+		s.mu.Unlock()
+		return models.WebhookDelivery{}, fail(span, ErrWebhookDeliveryInFlight)
+	}
+	d.tries = 0
+	d.Status = models.WebhookPending
+	d.NextAttemptAt = nil
+	s.mu.Unlock()
+
+	// The attempt is recorded even if the client goes away.
+	return s.send(context.WithoutCancel(ctx), d), nil
+}
+
+// RetryDue queues every pending delivery whose next attempt is due, as far as the queue has room.
+// Returns the number of deliveries queued.
+func (s *WebhookService) RetryDue(ctx context.Context) int {
+	ctx, span := startSpan(ctx, "WebhookService.RetryDue")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var due []*webhookDelivery
+	for _, list := range s.deliveries {
+		for _, d := range list {
+			if d.Status == models.WebhookPending && !d.sending && !d.queued && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+				due = append(due, d)
+			}
+		}
+	}
+	// Oldest first, so that a full queue does not starve any delivery.
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	queued := 0
+	for _, d := range due {
+		if !s.enqueue(ctx, d) {
+			break
+		}
+		queued++
+	}
+	span.SetAttributes(attribute.Int("webhook.deliveries", queued))
+	return queued
+}
+
+// Wait blocks until every delivery queued so far has been attempted. Tests use it
+// before asserting on the outcome of deliveries.
+func (s *WebhookService) Wait() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.pending > 0 {
+		s.idle.Wait()
+	}
+}
+
+// enqueue hands d to the workers without blocking. If the queue is full, d is left pending
+// and due now, so that RetryDue queues it later, and false is returned. Callers must hold s.mu.
+func (s *WebhookService) enqueue(ctx context.Context, d *webhookDelivery) bool {
+	select {
+	case s.queue <- webhookJob{ctx: context.WithoutCancel(ctx), delivery: d}:
+		d.queued = true
+		s.pending++
+		return true
+	default:
+		if d.NextAttemptAt == nil {
+			now := s.now()
+			d.NextAttemptAt = &now
+		}
+		return false
+	}
+}
+
+// work sends the queued deliveries, one at a time.
+func (s *WebhookService) work() {
+	for job := range s.queue {
+		s.send(job.ctx, job.delivery)
+
+		s.mu.Lock()
+		s.pending--
+		if s.pending == 0 {
+			s.idle.Broadcast()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// RunRetries retries due deliveries every interval until ctx is cancelled.
+func (s *WebhookService) RunRetries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.RetryDue(ctx); n > 0 {
+				log.Printf("Retrying %d webhook delivery(s)", n)
+			}
+		}
+	}
+}
+
+// SignWebhook returns the WebhookSignatureHeader value for a request body sent at timestamp:
+// "sha256=" followed by the hex-encoded HMAC-SHA256, keyed with secret, of the timestamp in
+// Unix seconds, a period and the body.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature headers of a delivery received at now against secret.
+// Deliveries with a timestamp more than tolerance away from now are rejected, so a captured
+// request cannot be replayed later. Returns ErrInvalidWebhookSignature on failure.
+func VerifyWebhook(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or malformed timestamp", ErrInvalidWebhookSignature)
+	}
+	timestamp := time.Unix(unix, 0)
+	if d := now.Sub(timestamp); d > tolerance || d < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhookSignature)
+	}
+	if !hmac.Equal([]byte(header.Get(WebhookSignatureHeader)), []byte(SignWebhook(secret, timestamp, body))) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// dispatch records a delivery of e for every active webhook subscribed to it whose owner
+// may see it, and queues the first attempt of each. Webhooks that already have a delivery
+// of the event, identified by its EventID, are skipped.
+func (s *WebhookService) dispatch(ctx context.Context, e DomainEvent) error {
+	ev, ok := streamEvent(e)
+	if !ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	var targets []models.Webhook
+	for _, w := range s.webhooks {
+		if w.Active && slices.Contains(w.Events, string(ev.Type)) {
+			targets = append(targets, w)
+		}
+	}
+	s.mu.Unlock()
+	targets = slices.DeleteFunc(targets, func(w models.Webhook) bool { return !s.visible(ctx, w.UserID, e) })
+	if len(targets) == 0 {
+		return nil
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].ID < targets[j].ID })
+
+	eventID, _ := EventID(ctx)
+	s.mu.Lock()
+	s.nextEventID++
+	ev.ID = s.nextEventID
+	ev.Time = s.now()
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	insert := startStorageSpan(ctx, "webhook_deliveries", "insert")
+	for _, w := range targets {
+		if _, ok := s.webhooks[w.ID]; !ok || s.delivered(w.ID, eventID) {
+			continue
+		}
+		d := &webhookDelivery{WebhookDelivery: models.WebhookDelivery{
+			ID:        s.nextDeliveryID,
+			WebhookID: w.ID,
+			Event:     string(ev.Type),
+			EventID:   eventID,
+			Payload:   payload,
+			Status:    models.WebhookPending,
+			CreatedAt: ev.Time,
+		}}
+		s.nextDeliveryID++
+		s.deliveries[w.ID] = trimDeliveries(append(s.deliveries[w.ID], d))
+		s.enqueue(ctx, d)
+	}
+	insert.End()
+	s.mu.Unlock()
+	return nil
+}
+
+// delivered reports whether the webhook already has a delivery of the event with the given ID.
+// Callers must hold s.mu.
+func (s *WebhookService) delivered(webhookID int, eventID string) bool {
+	if eventID == "" {
+		return false
+	}
+	return slices.ContainsFunc(s.deliveries[webhookID], func(d *webhookDelivery) bool { return d.EventID == eventID })
+}
+
+// visible reports whether the owner of a webhook may see the post e concerns.
+// User events are public.
+func (s *WebhookService) visible(ctx context.Context, userID int, e DomainEvent) bool {
+	switch e := e.(type) {
+	case PostCreated:
+		return e.Post.VisibleTo(userID)
+	case PostUpdated:
+		return e.Post.VisibleTo(userID)
+	case PostDeleted:
+		return e.Post.VisibleTo(userID)
+	case CommentCreated, CommentDeleted:
+		post, err := commentPost(ctx, s.posts, e)
+		return err == nil && post.VisibleTo(userID)
+	}
+	return true
+}
+
+// send makes one attempt at d, records its outcome and returns the delivery as it is afterwards.
+// Nothing is sent if another attempt is already under way.
+func (s *WebhookService) send(ctx context.Context, d *webhookDelivery) models.WebhookDelivery {
+	ctx, span := startSpan(ctx, "WebhookService.send")
+	defer span.End()
+	span.SetAttributes(
+		attribute.Int("webhook.id", d.WebhookID),
+		attribute.Int("webhook.delivery_id", d.ID),
+		attribute.String("event.name", d.Event),
+	)
+
+	s.mu.Lock()
+	d.queued = false
+	webhook, ok := s.webhooks[d.WebhookID]
+	if !ok || d.sending {
+		defer s.mu.Unlock()
+		return d.snapshot()
+	}
+	d.sending = true
+	s.mu.Unlock()
+
+	at := s.now()
+	attempt := models.WebhookAttempt{At: at}
+	started := time.Now()
+	status, err := s.post(ctx, webhook, d, at)
+	attempt.DurationMS = time.Since(started).Milliseconds()
+	attempt.StatusCode = status
+	if err != nil {
+		attempt.Error = attemptError(err)
+		fail(span, err)
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.sending = false
+	d.tries++
+	d.Attempts = append(d.Attempts, attempt)
+	switch {
+	case err == nil:
+		d.Status = models.WebhookSucceeded
+		d.NextAttemptAt = nil
+	case d.tries >= s.maxAttempts:
+		d.Status = models.WebhookDead
+		d.NextAttemptAt = nil
+	default:
+		next := s.now().Add(webhookRetryDelay(d.tries))
+		d.Status = models.WebhookPending
+		d.NextAttemptAt = &next
+	}
+	return d.snapshot()
+}
+
+// post sends the payload of d to webhook, signed for time at, and returns the response status.
+// Responses other than 2xx are errors.
+func (s *WebhookService) post(ctx context.Context, webhook models.Webhook, d *webhookDelivery, at time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "example-api-webhooks")
+	req.Header.Set(WebhookIDHeader, strconv.Itoa(d.ID))
+	req.Header.Set(WebhookEventHeader, d.Event)
+	if d.EventID != "" {
+		req.Header.Set(WebhookEventIDHeader, d.EventID)
+	}
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(at.Unix(), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, at, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Reading a little of the body lets the connection be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// attemptError describes a failed attempt for its owner. Transport errors are reduced to
+// their kind so that the delivery log does not reveal how the server resolved and reached
+// the destination.
+func attemptError(err error) string {
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, ErrWebhookAddressNotAllowed):
+		return "destination address not allowed"
+	case !errors.As(err, &urlErr):
+		return err.Error()
+	case urlErr.Timeout():
+		return "request timed out"
+	default:
+		return "connection failed"
+	}
+}
+
+// NewWebhookClient returns a client for webhook deliveries that gives up after timeout
+// and does not follow redirects. It only connects to public internet addresses: the check
+// is made on the address being dialled, after the host name is resolved, so a name that
+// resolves (or is later re-pointed) to a loopback, private or link-local address cannot
+// be used to reach internal services.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   webhookDialControl,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// webhookDialControl rejects connections to addresses that are not public.
+func webhookDialControl(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(addrPort.Addr()) {
+		return ErrWebhookAddressNotAllowed
+	}
+	return nil
+}
+
+// nonPublicPrefixes are the special-purpose ranges that netip.Addr does not classify itself.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+}
+
+// publicAddr reports whether addr is a public unicast address.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// userDeleted removes the webhooks of a deleted user.
+func (s *WebhookService) userDeleted(ctx context.Context, e UserDeleted) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	del := startStorageSpan(ctx, "webhooks", "delete")
+	defer del.End()
+	for id, w := range s.webhooks {
+		if w.UserID == e.User.ID {
+			delete(s.webhooks, id)
+			delete(s.deliveries, id)
+		}
+	}
+	return nil
+}
+
+// webhook returns userID's webhook with the given ID. Callers must hold s.mu.
+func (s *WebhookService) webhook(ctx context.Context, userID int, id int) (models.Webhook, bool) {
+	get := startStorageSpan(ctx, "webhooks", "get")
+	defer get.End()
+	w, ok := s.webhooks[id]
+	if !ok || w.UserID != userID {
+		return models.Webhook{}, false
+	}
+	return w, true
+}
+
+// snapshot returns a copy of the delivery that is safe to hand out. Callers must hold s.mu.
+func (d *webhookDelivery) snapshot() models.WebhookDelivery {
+	out := d.WebhookDelivery
+	out.Attempts = slices.Clone(d.Attempts)
+	if out.Attempts == nil {
+		out.Attempts = []models.WebhookAttempt{}
+	}
+	return out
+}
+
+// deliveryPage returns a page of the deliveries in list with the given status (any if empty),
+// most recent first. list must be ordered oldest first.
+func deliveryPage(list []*webhookDelivery, status models.WebhookDeliveryStatus, limit int, offset int) models.WebhookDeliveryPage {
+	if limit <= 0 {
+		limit = DefaultWebhookDeliveryLimit
+	}
+	limit = min(limit, MaxWebhookDeliveryLimit)
+	offset = max(offset, 0)
+
+	matched := make([]models.WebhookDelivery, 0)
+	for i := len(list) - 1; i >= 0; i-- {
+		if status == "" || list[i].Status == status {
+			matched = append(matched, list[i].snapshot())
+		}
+	}
+	return models.WebhookDeliveryPage{
+		Deliveries: matched[min(offset, len(matched)):min(offset+limit, len(matched))],
+		Total:      len(matched),
+	}
+}
+
+// trimDeliveries drops the oldest finished deliveries beyond MaxWebhookDeliveries.
+// Pending deliveries are kept until they succeed or die.
+func trimDeliveries(list []*webhookDelivery) []*webhookDelivery {
+	excess := len(list) - MaxWebhookDeliveries
+	if excess <= 0 {
+		return list
+	}
+	return slices.DeleteFunc(list, func(d *webhookDelivery) bool {
+		if excess > 0 && d.Status != models.WebhookPending {
+			excess--
+			return true
+		}
+		return false
+	})
+}
+
+// webhookRetryDelay returns the wait after the given number of failed attempts.
+func webhookRetryDelay(tries int) time.Duration {
+	delay := WebhookRetryDelay
+	for i := 1; i < tries && delay < MaxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxWebhookRetryDelay)
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(raw) > MaxWebhookURLLength {
+		return fmt.Errorf("url must be an http or https URL of at most %d characters", MaxWebhookURLLength)
+	}
+	return nil
+}
+
+func validateWebhookSecret(secret string) error {
+	if len(secret) < MinWebhookSecretSize {
+		return fmt.Errorf("secret must be at least %d characters", MinWebhookSecretSize)
+	}
+	return nil
+}
+
+// webhookEvents validates a webhook's event types and returns them without duplicates.
+func webhookEvents(types []string) ([]string, error) {
+	if len(types) == 0 {
+		return nil, errors.New("at least one event type is required")
+	}
+	var out []string
+	for _, t := range types {
+		if !slices.Contains(events.Types, events.Type(t)) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidWebhookEvent, t)
+		}
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func withoutSecret(w models.Webhook) models.Webhook {
+	w.Secret = ""
+	return w
+}
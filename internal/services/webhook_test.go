@@ -0,0 +1,498 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"example/api/internal/events"
+	"example/api/internal/models"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver is a local endpoint that records the deliveries it receives and
+// answers with a configurable status.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T) (*webhookReceiver, *httptest.Server) {
+	rec := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, receivedWebhook{header: r.Header.Clone(), body: body})
+		status := rec.status
+		rec.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return rec, server
+}
+
+func (rec *webhookReceiver) respond(status int) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.status = status
+}
+
+func (rec *webhookReceiver) received() []receivedWebhook {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]receivedWebhook(nil), rec.requests...)
+}
+
+func TestWebhookService(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	users := NewUserService(bus)
+	posts := NewPostService(bus)
+	comments := NewCommentService(posts)
+	s := NewWebhookService(posts, users, http.DefaultClient, 3)
+	now := time.Now().Truncate(time.Second)
+	s.now = func() time.Time { return now }
+
+	partner, _ := users.Register(ctx, "Partner", "partner@example.com")
+	author, _ := users.Register(ctx, "Author", "author@example.com")
+	rec, server := newWebhookReceiver(t)
+
+	t.Run("Create validates and generates a secret", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input WebhookInput
+		}{
+			{"relative URL", WebhookInput{URL: "/hooks", Events: []string{"post.created"}}},
+			{"unsupported scheme", WebhookInput{URL: "ftp://example.com", Events: []string{"post.created"}}},
+			{"no events", WebhookInput{URL: server.URL}},
+			{"unknown event", WebhookInput{URL: server.URL, Events: []string{"post.liked"}}},
+			{"short secret", WebhookInput{URL: server.URL, Events: []string{"post.created"}, Secret: "short"}},
+		}
+		for _, tt := range tests {
+			if _, err := s.Create(ctx, partner, tt.input); err == nil {
+				t.Errorf("%s: expected an error, got nil", tt.name)
+			}
+		}
+
+		webhook, err := s.Create(ctx, partner, WebhookInput{URL: server.URL, Events: []string{"user.deleted", "user.deleted"}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(webhook.Secret) < MinWebhookSecretSize || !webhook.Active {
+			t.Errorf("Expected an active webhook with a generated secret, got %+v", webhook)
+		}
+		if len(webhook.Events) != 1 {
+			t.Errorf("Expected duplicate events to be removed, got %v", webhook.Events)
+		}
+		if list := s.List(ctx, partner); len(list) != 1 || list[0].Secret != "" {
+			t.Errorf("Expected one webhook without its secret, got %+v", list)
+		}
+		if _, err := s.Find(ctx, author, webhook.ID); !errors.Is(err, ErrWebhookNotFound) {
+			t.Errorf("Expected ErrWebhookNotFound for another user, got %v", err)
+		}
+		if !s.Delete(ctx, partner, webhook.ID) {
+			t.Error("Expected the webhook to be deleted")
+		}
+	})
+
+	const secret = "0123456789abcdef0123"
+	webhook, err := s.Create(ctx, partner, WebhookInput{
+		URL:    server.URL,
+		Events: []string{"post.created", "comment.created"},
+		Secret: secret,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Deliveries are signed", func(t *testing.T) {
+		postID, _ := posts.Create(ctx, "Hello", "Content", author)
+		bus.Wait()
+		s.Wait()
+
+		got := rec.received()
+		if len(got) != 1 {
+			t.Fatalf("Expected 1 request, got %d", len(got))
+		}
+		req := got[0]
+		if err := VerifyWebhook(secret, req.header, req.body, now, 5*time.Minute); err != nil {
+			t.Errorf("Expected a valid signature, got %v", err)
+		}
+		if err := VerifyWebhook("another-secret-value", req.header, req.body, now, 5*time.Minute); !errors.Is(err, ErrInvalidWebhookSignature) {
+			t.Errorf("Expected ErrInvalidWebhookSignature with another secret, got %v", err)
+		}
+		if err := VerifyWebhook(secret, req.header, req.body, now.Add(time.Hour), 5*time.Minute); !errors.Is(err, ErrInvalidWebhookSignature) {
+			t.Errorf("Expected ErrInvalidWebhookSignature for an old delivery, got %v", err)
+		}
+		if e := req.header.Get(WebhookEventHeader); e != "post.created" {
+			t.Errorf("Expected event header post.created, got %q", e)
+		}
+		var ev events.Event
+		if err := json.Unmarshal(req.body, &ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev.Type != events.PostCreated || ev.PostID != postID || ev.UserID != author {
+			t.Errorf("Expected post.created for post %d by %d, got %+v", postID, author, ev)
+		}
+
+		page, _ := s.Deliveries(ctx, partner, webhook.ID, "", 0, 0)
+		if page.Total != 1 || page.Deliveries[0].Status != models.WebhookSucceeded {
+			t.Fatalf("Expected 1 succeeded delivery, got %+v", page)
+		}
+		d := page.Deliveries[0]
+		if len(d.Attempts) != 1 || d.Attempts[0].StatusCode != http.StatusOK {
+			t.Errorf("Expected 1 attempt answered with 200, got %+v", d.Attempts)
+		}
+	})
+
+	t.Run("Deliveries are recorded before the event leaves the outbox", func(t *testing.T) {
+		posts.Create(ctx, "Recorded", "Content", author)
+		if pending := posts.Outbox().Pending(); len(pending) != 0 {
+			t.Fatalf("Expected the outbox to be relayed, got %d pending entries", len(pending))
+		}
+		page, _ := s.Deliveries(ctx, partner, webhook.ID, "", 0, 0)
+		if page.Total != 2 || page.Deliveries[0].EventID == "" {
+			t.Errorf("Expected the delivery to be recorded with its event ID when Create returns, got %+v", page)
+		}
+		s.Wait()
+	})
+
+	t.Run("Events relayed twice are delivered once", func(t *testing.T) {
+		before := len(rec.received())
+		post, _ := posts.FindByID(ctx, 1)
+		relayed := context.WithValue(ctx, eventIDKey{}, "posts:99")
+		s.dispatch(relayed, PostCreated{Post: post})
+		s.dispatch(relayed, PostCreated{Post: post})
+		s.Wait()
+
+		got := rec.received()[before:]
+		if len(got) != 1 {
+			t.Fatalf("Expected 1 request, got %d", len(got))
+		}
+		if id := got[0].header.Get(WebhookEventIDHeader); id != "posts:99" {
+			t.Errorf("Expected event ID header posts:99, got %q", id)
+		}
+	})
+
+	t.Run("Events about hidden posts are not sent", func(t *testing.T) {
+		before := len(rec.received())
+		draft, _ := posts.Create(ctx, "Draft", "Content", author, WithStatus(models.PostDraft))
+		comments.Create(ctx, draft, author, "Note to self", nil)
+		bus.Wait()
+		s.Wait()
+		if got := len(rec.received()); got != before {
+			t.Errorf("Expected no requests for a draft, got %d", got-before)
+		}
+	})
+
+	t.Run("Comments deleted along with their post are judged by that post", func(t *testing.T) {
+		public, _ := posts.Create(ctx, "Public", "Content", author)
+		draft, _ := posts.Create(ctx, "Draft", "Content", author, WithStatus(models.PostDraft))
+		for _, tt := range []struct {
+			postID   int
+			expected bool
+		}{{public, true}, {draft, false}} {
+			post, _ := posts.FindByID(ctx, tt.postID)
+			posts.Delete(ctx, tt.postID)
+			e := CommentDeleted{Comment: models.Comment{PostID: tt.postID}, Post: &post}
+			if got := s.visible(ctx, partner, e); got != tt.expected {
+				t.Errorf("Expected visible %v for a comment of %q, got %v", tt.expected, post.Title, got)
+			}
+		}
+		bus.Wait()
+		s.Wait()
+	})
+
+	t.Run("Failed deliveries are retried and dead-lettered", func(t *testing.T) {
+		rec.respond(http.StatusInternalServerError)
+		before := len(rec.received())
+		posts.Create(ctx, "Retry", "Content", author)
+		bus.Wait()
+		s.Wait()
+
+		page, _ := s.Deliveries(ctx, partner, webhook.ID, models.WebhookPending, 0, 0)
+		if page.Total != 1 {
+			t.Fatalf("Expected 1 pending delivery, got %d", page.Total)
+		}
+		d := page.Deliveries[0]
+		if d.NextAttemptAt == nil || !d.NextAttemptAt.Equal(now.Add(WebhookRetryDelay)) {
+			t.Errorf("Expected next attempt after %v, got %v", WebhookRetryDelay, d.NextAttemptAt)
+		}
+		if n := s.RetryDue(ctx); n != 0 {
+			t.Errorf("Expected no deliveries due yet, got %d", n)
+		}
+
+		now = now.Add(WebhookRetryDelay)
+		if n := s.RetryDue(ctx); n != 1 {
+			t.Errorf("Expected 1 retry, got %d", n)
+		}
+		s.Wait()
+		page, _ = s.Deliveries(ctx, partner, webhook.ID, models.WebhookPending, 0, 0)
+		if next := page.Deliveries[0].NextAttemptAt; next == nil || !next.Equal(now.Add(2*WebhookRetryDelay)) {
+			t.Errorf("Expected the delay to double, got next attempt at %v", next)
+		}
+
+		now = now.Add(2 * WebhookRetryDelay)
+		s.RetryDue(ctx)
+		s.Wait()
+		dead := s.DeadLetters(ctx, partner, 0, 0)
+		if dead.Total != 1 || dead.Deliveries[0].ID != d.ID || len(dead.Deliveries[0].Attempts) != 3 {
+			t.Fatalf("Expected the delivery dead-lettered after 3 attempts, got %+v", dead)
+		}
+		if got := dead.Deliveries[0].Attempts[2].StatusCode; got != http.StatusInternalServerError {
+			t.Errorf("Expected the attempt status to be logged, got %d", got)
+		}
+		if n := s.RetryDue(ctx); n != 0 {
+			t.Errorf("Expected dead deliveries not to be retried, got %d", n)
+		}
+
+		rec.respond(http.StatusNoContent)
+		redelivered, err := s.Redeliver(ctx, partner, webhook.ID, d.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if redelivered.Status != models.WebhookSucceeded || len(redelivered.Attempts) != 4 {
+			t.Errorf("Expected a fourth, successful attempt, got %+v", redelivered)
+		}
+		got := rec.received()[before:]
+		if len(got) != 4 {
+			t.Fatalf("Expected 4 requests, got %d", len(got))
+		}
+		for _, req := range got {
+			if id := req.header.Get(WebhookIDHeader); id != got[0].header.Get(WebhookIDHeader) {
+				t.Errorf("Expected the same delivery ID on every attempt, got %q and %q", got[0].header.Get(WebhookIDHeader), id)
+			}
+		}
+		if dead := s.DeadLetters(ctx, partner, 0, 0); dead.Total != 0 {
+			t.Errorf("Expected no dead letters after redelivery, got %d", dead.Total)
+		}
+		if _, err := s.Redeliver(ctx, author, webhook.ID, d.ID); !errors.Is(err, ErrWebhookNotFound) {
+			t.Errorf("Expected ErrWebhookNotFound for another user, got %v", err)
+		}
+		if _, err := s.Redeliver(ctx, partner, webhook.ID, 999); !errors.Is(err, ErrWebhookDeliveryNotFound) {
+			t.Errorf("Expected ErrWebhookDeliveryNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Inactive webhooks receive nothing", func(t *testing.T) {
+		inactive := false
+		if _, err := s.Update(ctx, partner, webhook.ID, WebhookUpdate{Active: &inactive}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		before := len(rec.received())
+		posts.Create(ctx, "Quiet", "Content", author)
+		bus.Wait()
+		s.Wait()
+		if got := len(rec.received()); got != before {
+			t.Errorf("Expected no requests, got %d", got-before)
+		}
+	})
+
+	t.Run("Deleting the owner removes their webhooks", func(t *testing.T) {
+		users.Delete(ctx, partner)
+		if _, err := s.Find(ctx, partner, webhook.ID); !errors.Is(err, ErrWebhookNotFound) {
+			t.Errorf("Expected ErrWebhookNotFound, got %v", err)
+		}
+	})
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		tries    int
+		expected time.Duration
+	}{
+		{1, WebhookRetryDelay},
+		{2, 2 * WebhookRetryDelay},
+		{4, 8 * WebhookRetryDelay},
+		{30, MaxWebhookRetryDelay},
+	}
+	for _, tt := range tests {
+		if got := webhookRetryDelay(tt.tries); got != tt.expected {
+			t.Errorf("Expected %v after %d tries, got %v", tt.expected, tt.tries, got)
+		}
+	}
+}
+
+func TestWebhookClient(t *testing.T) {
+	t.Run("Non-public destinations are refused", func(t *testing.T) {
+		ctx := context.Background()
+		bus := NewBus()
+		users := NewUserService(bus)
+		posts := NewPostService(bus)
+		s := NewWebhookService(posts, users, NewWebhookClient(time.Second), 3)
+		owner, _ := users.Register(ctx, "Owner", "owner@example.com")
+		rec, server := newWebhookReceiver(t)
+		webhook, err := s.Create(ctx, owner, WebhookInput{URL: server.URL, Events: []string{"post.created"}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		posts.Create(ctx, "Hello", "Content", owner)
+		bus.Wait()
+		s.Wait()
+		if got := len(rec.received()); got != 0 {
+			t.Errorf("Expected no requests to a loopback address, got %d", got)
+		}
+		page, _ := s.Deliveries(ctx, owner, webhook.ID, "", 0, 0)
+		if page.Total != 1 || len(page.Deliveries[0].Attempts) != 1 {
+			t.Fatalf("Expected 1 delivery with 1 attempt, got %+v", page)
+		}
+		if got := page.Deliveries[0].Attempts[0].Error; got != "destination address not allowed" {
+			t.Errorf("Expected the attempt error not to reveal the transport error, got %q", got)
+		}
+	})
+
+	t.Run("Redirects are not followed", func(t *testing.T) {
+		err := NewWebhookClient(time.Second).CheckRedirect(nil, nil)
+		if !errors.Is(err, http.ErrUseLastResponse) {
+			t.Errorf("Expected http.ErrUseLastResponse, got %v", err)
+		}
+	})
+
+	t.Run("Only public addresses are allowed", func(t *testing.T) {
+		tests := []struct {
+			addr     string
+			expected bool
+		}{
+			{"93.184.216.34", true},
+			{"2606:2800:220:1:248:1893:25c8:1946", true},
+			{"127.0.0.1", false},
+			{"10.1.2.3", false},
+			{"172.16.0.1", false},
+			{"192.168.1.1", false},
+			{"169.254.169.254", false},
+			{"100.64.0.1", false},
+			{"0.0.0.0", false},
+			{"224.0.0.1", false},
+			{"::1", false},
+			{"fd00::1", false},
+			{"fe80::1", false},
+			{"::ffff:127.0.0.1", false},
+			{"64:ff9b::a00:1", false},
+		}
+		for _, tt := range tests {
+			if got := publicAddr(netip.MustParseAddr(tt.addr)); got != tt.expected {
+				t.Errorf("Expected publicAddr(%s) to be %v, got %v", tt.addr, tt.expected, got)
+			}
+		}
+	})
+}
+
+func TestAttemptError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{&url.Error{Op: "Post", URL: "http://hooks.example.com", Err: &net.OpError{Op: "dial", Err: ErrWebhookAddressNotAllowed}}, "destination address not allowed"},
+		{&url.Error{Op: "Post", URL: "http://hooks.example.com", Err: context.DeadlineExceeded}, "request timed out"},
+		{&url.Error{Op: "Post", URL: "http://hooks.example.com", Err: errors.New("dial tcp 10.0.0.7:80: connect: connection refused")}, "connection failed"},
+		{errors.New("unexpected response status 500 Internal Server Error"), "unexpected response status 500 Internal Server Error"},
+	}
+	for _, tt := range tests {
+		if got := attemptError(tt.err); got != tt.expected {
+			t.Errorf("Expected %q for %v, got %q", tt.expected, tt.err, got)
+		}
+	}
+}
+
+func TestWebhookQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Slow receivers do not hold up the bus", func(t *testing.T) {
+		bus := NewBus()
+		users := NewUserService(bus)
+		posts := NewPostService(bus)
+		s := NewWebhookService(posts, users, http.DefaultClient, 3)
+		owner, _ := users.Register(ctx, "Owner", "owner@example.com")
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		t.Cleanup(server.Close)
+		if _, err := s.Create(ctx, owner, WebhookInput{URL: server.URL, Events: []string{"post.created"}}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for range WebhookWorkers + 1 {
+			posts.Create(ctx, "Hello", "Content", owner)
+		}
+		done := make(chan struct{})
+		go func() {
+			bus.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the bus not to wait for webhook requests")
+		}
+		close(release)
+		s.Wait()
+	})
+
+	t.Run("Deliveries that do not fit are left for RetryDue", func(t *testing.T) {
+		bus := NewBus()
+		users := NewUserService(bus)
+		posts := NewPostService(bus)
+		s := NewWebhookService(posts, users, http.DefaultClient, 3)
+		now := time.Now()
+		s.now = func() time.Time { return now }
+		owner, _ := users.Register(ctx, "Owner", "owner@example.com")
+		release := make(chan struct{})
+		var received sync.WaitGroup
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			received.Done()
+		}))
+		t.Cleanup(server.Close)
+		if _, err := s.Create(ctx, owner, WebhookInput{URL: server.URL, Events: []string{"post.created"}}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		// The workers hang on the first deliveries and the queue fills up behind them.
+		n := WebhookWorkers + MaxWebhookQueue + 5
+		received.Add(n)
+		for range n {
+			posts.Create(ctx, "Hello", "Content", owner)
+		}
+		bus.Wait()
+		s.mu.Lock()
+		left := 0
+		for _, list := range s.deliveries {
+			for _, d := range list {
+				if !d.queued && !d.sending && d.NextAttemptAt != nil && d.NextAttemptAt.Equal(now) {
+					left++
+				}
+			}
+		}
+		s.mu.Unlock()
+		if left == 0 {
+			t.Fatal("Expected deliveries left due for RetryDue")
+		}
+		if got := s.RetryDue(ctx); got != 0 {
+			t.Errorf("Expected nothing queued while the queue is full, got %d", got)
+		}
+
+		close(release)
+		s.Wait()
+		if got := s.RetryDue(ctx); got != left {
+			t.Errorf("Expected %d deliveries queued, got %d", left, got)
+		}
+		s.Wait()
+		// Every post reached the receiver.
+		received.Wait()
+	})
+}
@@ -0,0 +1,447 @@
+// Package transfer reads and writes the records of bulk imports and exports of users
+// and posts, as NDJSON (one JSON record per line), CSV or a JSON array.
+//
+// Every format carries the same flat Record, whose Type says whether it is a user or
+// a post, so an export in any format can be imported again.
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"example/api/internal/models"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxLineBytes is the size of the longest NDJSON line Read accepts.
+const MaxLineBytes = 1 << 20
+
+// Format is the encoding of a stream of records.
+type Format string
+
+// Supported formats.
+const (
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+	JSON   Format = "json"
+)
+
+// ErrUnsupportedFormat is returned for unknown formats.
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ParseFormat returns the format named s, which may also be the format's media type,
+// such as "text/csv".
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, f := range []Format{NDJSON, CSV, JSON} {
+		if s == string(f) || s == f.ContentType() {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", ErrUnsupportedFormat, s)
+}
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case NDJSON:
+		return "application/x-ndjson"
+	case CSV:
+		return "text/csv"
+	default:
+		return "application/json"
+	}
+}
+
+// Type is the kind of resource a record holds.
+type Type string
+
+// Record types.
+const (
+	TypeUser Type = "user"
+	TypePost Type = "post"
+)
+
+// Record is one user or post. Users use the fields from Name to Website, posts the
+// fields from Title to CreatedAt.
+type Record struct {
+	// Type is "user" or "post"
+	Type Type `json:"type"`
+	// ID is the resource's ID in the system it comes from
+	ID int `json:"id,omitempty"`
+
+	Name        string `json:"name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Handle      string `json:"handle,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Bio         string `json:"bio,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Website     string `json:"website,omitempty"`
+
+	Title   string               `json:"title,omitempty"`
+	Content string               `json:"content,omitempty"`
+	Format  models.ContentFormat `json:"format,omitempty"`
+	// UserID is the ID of the post's author in the system the post comes from
+	UserID    int               `json:"user_id,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Status    models.PostStatus `json:"status,omitempty"`
+	PublishAt *time.Time        `json:"publish_at,omitempty"`
+	// PublishedAt is when the post was last published
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// CreatedAt is when the post was created; posts without it are created at the time of the import
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// UserRecord returns the record of u.
+func UserRecord(u models.User) Record {
+	return Record{
+		Type:        TypeUser,
+		ID:          u.ID,
+		Name:        u.Name,
+		Email:       u.Email,
+		Handle:      u.Handle,
+		DisplayName: u.DisplayName,
+		Bio:         u.Bio,
+		Location:    u.Location,
+		Website:     u.Website,
+	}
+}
+
+// PostRecord returns the record of p.
+func PostRecord(p models.Post) Record {
+	return Record{
+		Type:        TypePost,
+		ID:          p.ID,
+		Title:       p.Title,
+		Content:     p.Content,
+		Format:      p.Format,
+		UserID:      p.UserID,
+		Tags:        p.Tags,
+		Status:      p.Status,
+		PublishAt:   p.PublishAt,
+		PublishedAt: p.PublishedAt,
+		CreatedAt:   &p.CreatedAt,
+	}
+}
+
+// Columns are the CSV columns, in the order Writer writes them. Tags are joined with commas.
+var Columns = []string{
+	"type", "id",
+	"name", "email", "handle", "display_name", "bio", "location", "website",
+	"title", "content", "format", "user_id", "tags", "status", "publish_at", "published_at", "created_at",
+}
+
+// Row is a record read from an import, or the error that prevented reading it.
+type Row struct {
+	// Line is the line the record starts on, counting a CSV header
+	Line   int
+	Record Record
+	Err    error
+}
+
+// Read returns the records of r, which is in NDJSON or CSV format. Malformed records
+// are yielded with an error and reading goes on; errors that make the rest of the input
+// unreadable, such as a missing CSV header, end the sequence.
+func Read(r io.Reader, f Format) iter.Seq[Row] {
+	switch f {
+	case NDJSON:
+		return readNDJSON(r)
+	case CSV:
+		return readCSV(r)
+	default:
+		return func(yield func(Row) bool) {
+			yield(Row{Line: 1, Err: fmt.Errorf("%w for import %q: use ndjson or csv", ErrUnsupportedFormat, f)})
+		}
+	}
+}
+
+func readNDJSON(r io.Reader) iter.Seq[Row] {
+	return func(yield func(Row) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, MaxLineBytes)
+		line := 0
+		for scanner.Scan() {
+			line++
+			b := scanner.Bytes()
+			if len(bytes.TrimSpace(b)) == 0 {
+				continue
+			}
+			row := Row{Line: line}
+			if err := json.Unmarshal(b, &row.Record); err != nil {
+				row.Err = fmt.Errorf("invalid JSON: %w", err)
+			}
+			if !yield(row) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			if errors.Is(err, bufio.ErrTooLong) {
+				err = fmt.Errorf("line is longer than %d bytes", MaxLineBytes)
+			}
+			yield(Row{Line: line + 1, Err: err})
+		}
+	}
+}
+
+func readCSV(r io.Reader) iter.Seq[Row] {
+	return func(yield func(Row) bool) {
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		header, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			yield(Row{Line: 1, Err: fmt.Errorf("invalid CSV header: %w", err)})
+			return
+		}
+		columns := make(map[string]int, len(header))
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := columns["type"]; !ok {
+			yield(Row{Line: 1, Err: errors.New(`the CSV header has no "type" column`)})
+			return
+		}
+
+		for {
+			fields, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			var row Row
+			if err != nil {
+				var parseErr *csv.ParseError
+				if !errors.As(err, &parseErr) {
+					yield(Row{Err: err})
+					return
+				}
+				row.Line, row.Err = parseErr.StartLine, err
+			} else {
+				row.Line, _ = cr.FieldPos(0)
+				row.Record, row.Err = parseCSVRecord(columns, fields)
+			}
+			if !yield(row) {
+				return
+			}
+		}
+	}
+}
+
+func parseCSVRecord(columns map[string]int, fields []string) (Record, error) {
+	get := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+	var err error
+	integer := func(name string) int {
+		v := strings.TrimSpace(get(name))
+		if v == "" || err != nil {
+			return 0
+		}
+		n, convErr := strconv.Atoi(v)
+		if convErr != nil {
+			err = fmt.Errorf("%s must be an integer, got %q", name, v)
+		}
+		return n
+	}
+	timestamp := func(name string) *time.Time {
+		v := strings.TrimSpace(get(name))
+		if v == "" || err != nil {
+			return nil
+		}
+		t, parseErr := time.Parse(time.RFC3339, v)
+		if parseErr != nil {
+			err = fmt.Errorf("%s must be an RFC 3339 time, got %q", name, v)
+			return nil
+		}
+		return &t
+	}
+
+	rec := Record{
+		Type:        Type(strings.TrimSpace(get("type"))),
+		ID:          integer("id"),
+		Name:        get("name"),
+		Email:       get("email"),
+		Handle:      get("handle"),
+		DisplayName: get("display_name"),
+		Bio:         get("bio"),
+		Location:    get("location"),
+		Website:     get("website"),
+		Title:       get("title"),
+		Content:     get("content"),
+		Format:      models.ContentFormat(get("format")),
+		UserID:      integer("user_id"),
+		Status:      models.PostStatus(get("status")),
+		PublishAt:   timestamp("publish_at"),
+		PublishedAt: timestamp("published_at"),
+		CreatedAt:   timestamp("created_at"),
+	}
+	if tags := get("tags"); strings.TrimSpace(tags) != "" {
+		rec.Tags = strings.Split(tags, ",")
+	}
+	return rec, err
+}
+
+// Writer encodes records to an io.Writer, one at a time.
+type Writer struct {
+	w      io.Writer
+	format Format
+	csv    *csv.Writer
+	n      int
+}
+
+// NewWriter returns a Writer encoding records to w in format f.
+func NewWriter(w io.Writer, f Format) *Writer {
+	tw := &Writer{w: w, format: f}
+	if f == CSV {
+		tw.csv = csv.NewWriter(w)
+	}
+	return tw
+}
+
+// Write encodes rec.
+func (w *Writer) Write(rec Record) error {
+	w.n++
+	switch w.format {
+	case CSV:
+		if w.n == 1 {
+			if err := w.csv.Write(Columns); err != nil {
+				return err
+			}
+		}
+		return w.csv.Write(csvFields(rec))
+	case JSON:
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		sep := ",\n"
+		if w.n == 1 {
+			sep = "[\n"
+		}
+		_, err = io.WriteString(w.w, sep+string(b))
+		return err
+	default:
+		return json.NewEncoder(w.w).Encode(rec)
+	}
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+// Close ends the encoding, completing the JSON array or CSV header of an empty export, and flushes.
+// It does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	switch w.format {
+	case CSV:
+		if w.n == 0 {
+			if err := w.csv.Write(Columns); err != nil {
+				return err
+			}
+		}
+	case JSON:
+		end := "\n]\n"
+		if w.n == 0 {
+			end = "[]\n"
+		}
+		if _, err := io.WriteString(w.w, end); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func csvFields(rec Record) []string {
+	integer := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	timestamp := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339Nano)
+	}
+	return []string{
+		string(rec.Type), integer(rec.ID),
+		rec.Name, rec.Email, rec.Handle, rec.DisplayName, rec.Bio, rec.Location, rec.Website,
+		rec.Title, rec.Content, string(rec.Format), integer(rec.UserID), strings.Join(rec.Tags, ","),
+		string(rec.Status), timestamp(rec.PublishAt), timestamp(rec.PublishedAt), timestamp(rec.CreatedAt),
+	}
+}
+
+// Summary counts the outcome of an import.
+type Summary struct {
+	// DryRun is true if the records were only validated; the counts and IDs are those the import would have produced
+	DryRun bool `json:"dry_run"`
+	// Rows is the number of records read
+	Rows int `json:"rows"`
+	// Users is the number of users created
+	Users int `json:"users"`
+	// Posts is the number of posts created
+	Posts int `json:"posts"`
+	// Failed is the number of records that could not be imported
+	Failed int `json:"failed"`
+}
+
+// Report describes the outcome of an import.
+type Report struct {
+	Summary
+	// Errors describes the failed records, up to MaxReportedErrors
+	Errors []RowError `json:"errors"`
+	// UserIDs maps the IDs of the imported user records to the IDs of the users created
+	UserIDs map[int]int `json:"user_ids"`
+	// PostIDs maps the IDs of the imported post records to the IDs of the posts created
+	PostIDs map[int]int `json:"post_ids"`
+}
+
+// MaxReportedErrors is the number of failed records a Report describes.
+const MaxReportedErrors = 1000
+
+// RowError describes a record that could not be imported.
+type RowError struct {
+	// Line is the line the record starts on
+	Line int `json:"line"`
+	// Type is the record's type, if it could be read
+	Type Type `json:"type,omitempty"`
+	// ID is the record's ID, if it could be read
+	ID int `json:"id,omitempty"`
+	// Error says why the record was not imported
+	Error string `json:"error"`
+}
+
+// NewReport returns an empty Report.
+func NewReport(dryRun bool) Report {
+	return Report{
+		Summary: Summary{DryRun: dryRun},
+		Errors:  make([]RowError, 0),
+		UserIDs: make(map[int]int),
+		PostIDs: make(map[int]int),
+	}
+}
+
+// Fail records that row could not be imported because of err.
+func (r *Report) Fail(row Row, err error) {
+	r.Failed++
+	if len(r.Errors) < MaxReportedErrors {
+		r.Errors = append(r.Errors, RowError{Line: row.Line, Type: row.Record.Type, ID: row.Record.ID, Error: err.Error()})
+	}
+}
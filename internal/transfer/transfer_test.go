@@ -0,0 +1,163 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"example/api/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	published := created.Add(time.Hour)
+	records := []Record{
+		{Type: TypeUser, ID: 7, Name: "Ana", Email: "ana@example.com", Handle: "ana", Bio: "Hola, \"mundo\"\nsegunda línea"},
+		{Type: TypePost, ID: 3, Title: "Title", Content: "Line 1\nLine 2", Format: models.FormatMarkdown, UserID: 7,
+			Tags: []string{"go", "web-dev"}, Status: models.PostPublished, PublishedAt: &published, CreatedAt: &created},
+	}
+
+	for _, format := range []Format{NDJSON, CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(&buf, format)
+			for _, rec := range records {
+				if err := w.Write(rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []Record
+			for row := range Read(&buf, format) {
+				if row.Err != nil {
+					t.Fatalf("Expected no error, got %v on line %d", row.Err, row.Line)
+				}
+				got = append(got, row.Record)
+			}
+			if !reflect.DeepEqual(got, records) {
+				t.Errorf("Expected %+v, got %+v", records, got)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, JSON)
+		for _, rec := range records {
+			w.Write(rec)
+		}
+		w.Close()
+		var got []Record
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("Expected a JSON array, got %v: %s", err, buf.String())
+		}
+		if !reflect.DeepEqual(got, records) {
+			t.Errorf("Expected %+v, got %+v", records, got)
+		}
+
+		buf.Reset()
+		NewWriter(&buf, JSON).Close()
+		if buf.String() != "[]\n" {
+			t.Errorf("Expected an empty array, got %q", buf.String())
+		}
+	})
+}
+
+func TestRead(t *testing.T) {
+	t.Run("malformed NDJSON lines are reported and skipped", func(t *testing.T) {
+		input := `{"type":"user","name":"Ana","email":"ana@example.com"}
+
+{"type":"user",
+{"type":"post","id":"one"}
+{"type":"post","title":"T","content":"C","user_id":1}
+`
+		var lines []int
+		var failed []int
+		for row := range Read(strings.NewReader(input), NDJSON) {
+			lines = append(lines, row.Line)
+			if row.Err != nil {
+				failed = append(failed, row.Line)
+			}
+		}
+		if !reflect.DeepEqual(lines, []int{1, 3, 4, 5}) || !reflect.DeepEqual(failed, []int{3, 4}) {
+			t.Errorf("Expected rows on lines 1, 3, 4, 5 with errors on 3 and 4, got %v and %v", lines, failed)
+		}
+	})
+
+	t.Run("CSV columns are matched by name", func(t *testing.T) {
+		input := "Email,name,type,tags\nana@example.com,Ana,user,\n,,post,\"go,web\"\nx,y,user\n"
+		var rows []Row
+		for row := range Read(strings.NewReader(input), CSV) {
+			rows = append(rows, row)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("Expected 3 rows, got %d", len(rows))
+		}
+		if r := rows[0]; r.Line != 2 || r.Record.Name != "Ana" || r.Record.Email != "ana@example.com" || r.Record.Type != TypeUser {
+			t.Errorf("Expected Ana on line 2, got %+v", r)
+		}
+		if tags := rows[1].Record.Tags; !reflect.DeepEqual(tags, []string{"go", "web"}) {
+			t.Errorf("Expected tags [go web], got %v", tags)
+		}
+		if r := rows[2]; r.Err != nil || r.Record.Name != "y" {
+			t.Errorf("Expected a short row to leave the missing columns empty, got %+v", r)
+		}
+	})
+
+	t.Run("CSV errors", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input string
+			lines []int
+		}{
+			{"no type column", "name,email\nAna,ana@example.com\n", []int{1}},
+			{"invalid integer", "type,id\nuser,seven\nuser,8\n", []int{2}},
+			{"invalid time", "type,publish_at\npost,tomorrow\n", []int{2}},
+		}
+		for _, tt := range tests {
+			var failed []int
+			for row := range Read(strings.NewReader(tt.input), CSV) {
+				if row.Err != nil {
+					failed = append(failed, row.Line)
+				}
+			}
+			if !reflect.DeepEqual(failed, tt.lines) {
+				t.Errorf("%s: expected errors on lines %v, got %v", tt.name, tt.lines, failed)
+			}
+		}
+	})
+
+	t.Run("JSON cannot be imported", func(t *testing.T) {
+		for row := range Read(strings.NewReader("[]"), JSON) {
+			if row.Err == nil {
+				t.Error("Expected an error")
+			}
+		}
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+	}{
+		{"ndjson", NDJSON},
+		{"CSV", CSV},
+		{"application/x-ndjson", NDJSON},
+		{"text/csv", CSV},
+		{"json", JSON},
+	}
+	for _, tt := range tests {
+		if got, err := ParseFormat(tt.input); err != nil || got != tt.expected {
+			t.Errorf("ParseFormat(%q): expected %s, got %s and %v", tt.input, tt.expected, got, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected an error for xml")
+	}
+}
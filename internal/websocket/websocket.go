@@ -0,0 +1,386 @@
+// Package websocket implements the server side of the WebSocket protocol (RFC 6455):
+// the opening handshake, message framing and the ping, pong and close control frames.
+//
+// It covers what the API needs and nothing more: there is no client, and extensions
+// such as compression and subprotocols are not negotiated.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message types. They double as the frame opcodes.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// continuationFrame is the opcode of the frames following the first one of a fragmented message.
+const continuationFrame = 0
+
+// Close status codes.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+	CloseTryAgainLater   = 1013
+)
+
+// DefaultReadLimit is the largest message in bytes a Conn accepts, unless changed with SetReadLimit.
+const DefaultReadLimit = 64 << 10
+
+// maxControlPayload is the largest payload of a ping, pong or close frame.
+const maxControlPayload = 125
+
+// acceptGUID is the key suffix defined by RFC 6455 for computing Sec-WebSocket-Accept.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrClosed is returned when writing to a connection that has already sent a close frame.
+var ErrClosed = errors.New("websocket: close frame already sent")
+
+// CloseError reports the end of a connection by a close frame, either received from
+// the peer or sent because the peer broke the protocol.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: closed with code %d: %s", e.Code, e.Reason)
+}
+
+// AcceptKey computes the Sec-WebSocket-Accept value answering a client's Sec-WebSocket-Key.
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Upgrade completes the opening handshake for r and takes over its connection.
+// If r is not a valid handshake, Upgrade writes an error response and returns an error.
+// The HTTP server no longer tracks the connection: the caller must close it.
+//
+// Browsers let any page open WebSocket connections, so handshakes with an Origin header are
+// refused with 403 unless it is the origin of r's host or one of allowedOrigins, such as
+// "https://example.com". Clients other than browsers send no Origin and are not checked.
+func Upgrade(w http.ResponseWriter, r *http.Request, allowedOrigins []string) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "WebSocket handshakes must use GET", http.StatusMethodNotAllowed)
+		return nil, errors.New("websocket: handshake method is not GET")
+	}
+	if !hasToken(r.Header, "Connection", "upgrade") || !hasToken(r.Header, "Upgrade", "websocket") {
+		w.Header().Set("Upgrade", "websocket")
+		http.Error(w, "Expected a WebSocket handshake", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: missing upgrade headers")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	if !originAllowed(r, allowedOrigins) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return nil, errors.New("websocket: origin not allowed")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if nonce, err := base64.StdEncoding.DecodeString(key); err != nil || len(nonce) != 16 {
+		http.Error(w, "Invalid Sec-WebSocket-Key header", http.StatusBadRequest)
+		return nil, errors.New("websocket: invalid key")
+	}
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket connections are not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+	// The server's deadlines for the HTTP request no longer apply.
+	netConn.SetDeadline(time.Time{})
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	if _, err := io.WriteString(netConn, response); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	// Frames the client sent right after the handshake may already be buffered.
+	return &Conn{conn: netConn, br: brw.Reader, readLimit: DefaultReadLimit}, nil
+}
+
+// originAllowed reports whether r has no Origin header, or one whose host is r's or that
+// is in allowed, ignoring case.
+func originAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, o := range allowed {
+		if strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasToken reports whether the comma-separated header name contains token, ignoring case.
+func hasToken(h http.Header, name string, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Conn is a server-side WebSocket connection.
+//
+// ReadMessage must be called from one goroutine at a time. The write methods may be
+// called concurrently with each other and with ReadMessage.
+type Conn struct {
+	conn      net.Conn
+	br        *bufio.Reader
+	readLimit int64
+	onPong    func(data []byte)
+
+	wmu          sync.Mutex
+	writeTimeout time.Duration
+	closeSent    bool
+}
+
+// SetReadLimit sets the largest message in bytes ReadMessage accepts. Larger messages
+// close the connection with CloseMessageTooBig.
+func (c *Conn) SetReadLimit(n int64) {
+	c.readLimit = n
+}
+
+// SetReadDeadline sets when a pending or future ReadMessage fails. Use it with
+// SetPongHandler to detect peers that stopped answering pings.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteTimeout bounds how long writing a frame may take; zero means no limit.
+// A peer that does not read its messages makes writes fail once the timeout passes.
+func (c *Conn) SetWriteTimeout(d time.Duration) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.writeTimeout = d
+}
+
+// SetPongHandler sets fn to be called by ReadMessage with the payload of each pong received.
+func (c *Conn) SetPongHandler(fn func(data []byte)) {
+	c.onPong = fn
+}
+
+// ReadMessage returns the next text or binary message, reassembling fragmented messages.
+// Pings are answered and pongs handed to the pong handler as they arrive.
+// When the peer sends a close frame, or breaks the protocol, ReadMessage replies with
+// a close frame and returns a *CloseError; the caller should then close the connection.
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	var message []byte
+	for {
+		f, err := c.readFrame(c.readLimit - int64(len(message)))
+		if err != nil {
+			return 0, nil, err
+		}
+		switch f.op {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, f.payload); err != nil && !errors.Is(err, ErrClosed) {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if c.onPong != nil {
+				c.onPong(f.payload)
+			}
+			continue
+		case CloseMessage:
+			return 0, nil, c.closed(f.payload)
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected a continuation frame")
+			}
+			messageType = f.op
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+		message = append(message, f.payload...)
+		if !f.fin {
+			continue
+		}
+		if messageType == TextMessage && !utf8.Valid(message) {
+			return 0, nil, c.fail(CloseInvalidPayload, "text message is not valid UTF-8")
+		}
+		return messageType, message, nil
+	}
+}
+
+// WriteMessage sends data as a single frame of the given message type.
+// Control frames (ping and pong) carry at most 125 bytes.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	switch messageType {
+	case TextMessage, BinaryMessage:
+	case PingMessage, PongMessage:
+		if len(data) > maxControlPayload {
+			return errors.New("websocket: control frame payload too long")
+		}
+	default:
+		return fmt.Errorf("websocket: cannot write message type %d", messageType)
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// WriteClose starts the closing handshake with a status code and reason. Nothing can be
+// written afterwards; the peer's close frame is returned by ReadMessage as a *CloseError.
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+	return c.writeFrame(CloseMessage, append(payload, reason...))
+}
+
+// Close closes the underlying connection without a closing handshake.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// frame is a single frame read from the peer.
+type frame struct {
+	fin     bool
+	op      int
+	payload []byte
+}
+
+// readFrame reads the next frame, whose payload may be at most limit bytes for data frames.
+func (c *Conn) readFrame(limit int64) (frame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{fin: head[0]&0x80 != 0, op: int(head[0] & 0x0F)}
+	if head[0]&0x70 != 0 {
+		return frame{}, c.fail(CloseProtocolError, "reserved bits are set")
+	}
+	if head[1]&0x80 == 0 {
+		return frame{}, c.fail(CloseProtocolError, "client frames must be masked")
+	}
+
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return frame{}, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return frame{}, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if f.op >= CloseMessage {
+		if !f.fin || n > maxControlPayload {
+			return frame{}, c.fail(CloseProtocolError, "invalid control frame")
+		}
+	} else if n > uint64(max(limit, 0)) {
+		return frame{}, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return frame{}, err
+	}
+	f.payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, f.payload); err != nil {
+		return frame{}, err
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+	return f, nil
+}
+
+// closed answers the peer's close frame by echoing its status code.
+func (c *Conn) closed(payload []byte) error {
+	switch {
+	case len(payload) == 0:
+		c.writeFrame(CloseMessage, nil)
+		return &CloseError{Code: CloseNoStatus}
+	case len(payload) == 1:
+		return c.fail(CloseProtocolError, "invalid close frame")
+	case !utf8.Valid(payload[2:]):
+		return c.fail(CloseInvalidPayload, "close reason is not valid UTF-8")
+	}
+	c.writeFrame(CloseMessage, payload[:2])
+	return &CloseError{Code: int(binary.BigEndian.Uint16(payload)), Reason: string(payload[2:])}
+}
+
+// fail closes the connection with code after the peer broke the protocol.
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteClose(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// writeFrame sends payload in a single, final frame.
+func (c *Conn) writeFrame(op int, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closeSent {
+		return ErrClosed
+	}
+	if op == CloseMessage {
+		c.closeSent = true
+	}
+	header := []byte{0x80 | byte(op)}
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = binary.BigEndian.AppendUint16(append(header, 126), uint16(n))
+	default:
+		header = binary.BigEndian.AppendUint64(append(header, 127), uint64(n))
+	}
+	if c.writeTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+	buffers := net.Buffers{header, payload}
+	_, err := buffers.WriteTo(c.conn)
+	return err
+}
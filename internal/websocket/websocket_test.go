@@ -0,0 +1,225 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+// echoServer upgrades every request and echoes its messages until the connection ends.
+// The error that ended each connection is sent on the returned channel.
+func echoServer(t *testing.T, readLimit int64) (*httptest.Server, <-chan error) {
+	errs := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, []string{"https://app.example.com"})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadLimit(readLimit)
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			conn.WriteMessage(typ, data)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, errs
+}
+
+// testClient is the client side of a connection, writing masked frames.
+type testClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dial(t *testing.T, server *httptest.Server) *testClient {
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: "+testKey+"\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected status 101, got %d", resp.StatusCode)
+	}
+	return &testClient{conn: conn, br: br}
+}
+
+func (c *testClient) writeFrame(fin bool, op int, payload []byte) {
+	b := byte(op)
+	if fin {
+		b |= 0x80
+	}
+	header := []byte{b}
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, 0x80|byte(n))
+	default:
+		header = binary.BigEndian.AppendUint16(append(header, 0x80|126), uint16(n))
+	}
+	mask := []byte{1, 2, 3, 4}
+	masked := make([]byte, len(payload))
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+	c.conn.Write(append(append(header, mask...), masked...))
+}
+
+func (c *testClient) readFrame(t *testing.T) (int, []byte) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	n := int(head[1] & 0x7F)
+	if n == 126 {
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return int(head[0] & 0x0F), payload
+}
+
+func (c *testClient) expectClose(t *testing.T, code int) {
+	op, payload := c.readFrame(t)
+	if op != CloseMessage || len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != code {
+		t.Errorf("Expected close frame with code %d, got opcode %d with %q", code, op, payload)
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	t.Run("accept key", func(t *testing.T) {
+		// Example from RFC 6455, section 1.3
+		if got := AcceptKey(testKey); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+			t.Errorf("Expected s3pPLMBiTxaQ9kYGzzhZRbK+xOo=, got %s", got)
+		}
+	})
+
+	t.Run("rejects plain requests", func(t *testing.T) {
+		server, _ := echoServer(t, DefaultReadLimit)
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUpgradeRequired {
+			t.Errorf("Expected status 426, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("checks the origin", func(t *testing.T) {
+		server, _ := echoServer(t, DefaultReadLimit)
+		handshake := func(origin string) int {
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			req.Header.Set("Sec-WebSocket-Version", "13")
+			req.Header.Set("Sec-WebSocket-Key", testKey)
+			if origin != "" {
+				req.Header.Set("Origin", origin)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		for _, origin := range []string{"", "http://" + server.Listener.Addr().String(), "https://app.example.com"} {
+			if code := handshake(origin); code != http.StatusSwitchingProtocols {
+				t.Errorf("Expected status 101 for origin %q, got %d", origin, code)
+			}
+		}
+		if code := handshake("https://evil.example"); code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for another origin, got %d", code)
+		}
+	})
+
+	t.Run("echoes messages", func(t *testing.T) {
+		server, _ := echoServer(t, DefaultReadLimit)
+		client := dial(t, server)
+		long := strings.Repeat("x", 300)
+		client.writeFrame(true, TextMessage, []byte(long))
+		if op, payload := client.readFrame(t); op != TextMessage || string(payload) != long {
+			t.Errorf("Expected text echo of %d bytes, got opcode %d with %d bytes", len(long), op, len(payload))
+		}
+	})
+
+	t.Run("reassembles fragments around pings", func(t *testing.T) {
+		server, _ := echoServer(t, DefaultReadLimit)
+		client := dial(t, server)
+		client.writeFrame(false, TextMessage, []byte("Hello, "))
+		client.writeFrame(true, PingMessage, []byte("p"))
+		client.writeFrame(true, continuationFrame, []byte("world"))
+		if op, payload := client.readFrame(t); op != PongMessage || string(payload) != "p" {
+			t.Errorf("Expected pong with p, got opcode %d with %q", op, payload)
+		}
+		if op, payload := client.readFrame(t); op != TextMessage || string(payload) != "Hello, world" {
+			t.Errorf("Expected Hello, world, got opcode %d with %q", op, payload)
+		}
+	})
+
+	t.Run("close handshake", func(t *testing.T) {
+		server, errs := echoServer(t, DefaultReadLimit)
+		client := dial(t, server)
+		client.writeFrame(true, CloseMessage, append(binary.BigEndian.AppendUint16(nil, CloseNormal), "bye"...))
+		client.expectClose(t, CloseNormal)
+		var ce *CloseError
+		if err := <-errs; !errors.As(err, &ce) || ce.Code != CloseNormal || ce.Reason != "bye" {
+			t.Errorf("Expected close error 1000 bye, got %v", err)
+		}
+	})
+
+	t.Run("protocol errors", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			limit int64
+			send  func(c *testClient)
+			code  int
+		}{
+			{"unmasked frame", DefaultReadLimit, func(c *testClient) { c.conn.Write([]byte{0x81, 0x01, 'x'}) }, CloseProtocolError},
+			{"message too big", 8, func(c *testClient) { c.writeFrame(true, TextMessage, []byte("too long message")) }, CloseMessageTooBig},
+			{"fragments too big", 8, func(c *testClient) {
+				c.writeFrame(false, TextMessage, []byte("12345"))
+				c.writeFrame(true, continuationFrame, []byte("6789"))
+			}, CloseMessageTooBig},
+			{"invalid UTF-8", DefaultReadLimit, func(c *testClient) { c.writeFrame(true, TextMessage, []byte{0xff}) }, CloseInvalidPayload},
+			{"orphan continuation", DefaultReadLimit, func(c *testClient) { c.writeFrame(true, continuationFrame, []byte("x")) }, CloseProtocolError},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				server, errs := echoServer(t, tt.limit)
+				client := dial(t, server)
+				tt.send(client)
+				client.expectClose(t, tt.code)
+				var ce *CloseError
+				if err := <-errs; !errors.As(err, &ce) || ce.Code != tt.code {
+					t.Errorf("Expected close error %d, got %v", tt.code, err)
+				}
+			})
+		}
+	})
+}